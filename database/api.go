@@ -2,142 +2,411 @@
 package database
 
 import (
+	"context"
+	"fmt"
+	"iter"
+	"math/rand"
 	"time"
 
+	"github.com/beaconsoftwarellc/gadget/v2/database/audit"
+	dberrors "github.com/beaconsoftwarellc/gadget/v2/database/errors"
 	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
 	"github.com/beaconsoftwarellc/gadget/v2/database/record"
 	"github.com/beaconsoftwarellc/gadget/v2/database/transaction"
 	"github.com/beaconsoftwarellc/gadget/v2/database/utility"
 	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/net"
+	"github.com/jmoiron/sqlx"
 )
 
 const defaultSlowQueryThreshold = 100 * time.Millisecond
 
 // API is a database interface
+//
+// When the Connection has replicas, Read, ReadOneWhere, Select, ListWhere,
+// NextPage, Count and Sum made outside of a transaction are routed to a healthy
+// replica and everything else is made on the primary. Reads on an API return
+// to the primary for the ReadYourWritesWindow after any API of the same
+// Connection commits a transaction.
+//
+// Operations on records whose meta implements record.SoftDeletable set the
+// deleted column instead of deleting and exclude deleted rows from reads, lists
+// and counts unless the context was created with record.WithDeleted or
+// record.WithOnlyDeleted.
 type API interface {
-	// Begin starts a transaction
+	// Begin starts a transaction, if a transaction is already in progress a
+	// SAVEPOINT is created that the matching Commit or Rollback will release or
+	// roll back to
 	Begin() errors.TracerError
+	// BeginContext starts a transaction that is rolled back by the driver if the
+	// context is done before it is committed. See Begin for nesting.
+	BeginContext(ctx context.Context) errors.TracerError
 	// GetTransaction that is currently on this instance, Begin must be called first.
 	GetTransaction() transaction.Transaction
-	// Commit commits the transaction
+	// Depth of the transaction on this instance, 0 when there is no transaction
+	// and 1 for a transaction with no savepoints
+	Depth() int
+	// Commit commits the transaction or releases the innermost savepoint
 	Commit() errors.TracerError
-	// Rollback aborts the transaction
+	// Rollback aborts the transaction or rolls back to the innermost savepoint
 	Rollback() errors.TracerError
 	// CommitOrRollback will rollback on an errors.TracerError otherwise commit
 	CommitOrRollback(err error) errors.TracerError
-
-	// Count the number of rows in the passed query
+	// WithTransaction calls fn inside of a transaction, committing if fn returns
+	// nil and rolling back otherwise. Calls may be nested, inner calls roll back
+	// to a savepoint without affecting the enclosing transaction.
+	WithTransaction(fn func(API) error) errors.TracerError
+	// WithTransactionContext is WithTransaction with a context that is passed to
+	// the driver.
+	WithTransactionContext(ctx context.Context, fn func(API) error) errors.TracerError
+	// WithRetryingTransaction calls fn inside of a transaction like WithTransaction,
+	// re-executing fn in a new transaction with backoff when it fails with a
+	// deadlock or lock wait timeout. Retries only occur when this is the
+	// outermost transaction, nested calls behave like WithTransaction.
+	WithRetryingTransaction(fn func(API) error) errors.TracerError
+	// WithRetryingTransactionContext is WithRetryingTransaction with a context that
+	// is passed to the driver and interrupts the backoff when done.
+	WithRetryingTransactionContext(ctx context.Context, fn func(API) error) errors.TracerError
+
+	// Count the number of rows in the passed query, soft deleted rows of the
+	// table are excluded, see record.ScopeDeleted
 	Count(qb.Table, *qb.SelectQuery) (int32, error)
+	// CountContext the number of rows in the passed query
+	CountContext(context.Context, qb.Table, *qb.SelectQuery) (int32, error)
 	// CountWhere rows match the passed condition in the specified table. Condition
 	// may be nil in order to just count the table rows.
 	CountWhere(qb.Table, *qb.ConditionExpression) (int32, error)
+	// CountWhereContext rows match the passed condition in the specified table.
+	// Condition may be nil in order to just count the table rows.
+	CountWhereContext(context.Context, qb.Table, *qb.ConditionExpression) (int32, error)
 	// Sum calculates the total of the specified numeric field over all rows
-	// matching the given select query.
+	// matching the given select query, soft deleted rows of the table the query
+	// selects from are excluded, see record.ScopeDeleted
 	Sum(qb.TableField, *qb.SelectQuery) (int32, error)
+	// SumContext calculates the total of the specified numeric field over all
+	// rows matching the given select query.
+	SumContext(context.Context, qb.TableField, *qb.SelectQuery) (int32, error)
 	// Create initializes a Record and inserts it into the Database
 	Create(obj record.Record) errors.TracerError
+	// CreateContext initializes a Record and inserts it into the Database
+	CreateContext(ctx context.Context, obj record.Record) errors.TracerError
 	// Read populates a Record from the database
 	Read(obj record.Record, pk record.PrimaryKeyValue) errors.TracerError
+	// ReadContext populates a Record from the database
+	ReadContext(ctx context.Context, obj record.Record, pk record.PrimaryKeyValue) errors.TracerError
 	// ReadOneWhere populates a Record from a custom where clause
 	ReadOneWhere(obj record.Record, condition *qb.ConditionExpression) errors.TracerError
+	// ReadOneWhereContext populates a Record from a custom where clause
+	ReadOneWhereContext(ctx context.Context, obj record.Record,
+		condition *qb.ConditionExpression) errors.TracerError
 	// Select executes a given select query and populates the target
 	Select(target interface{}, query *qb.SelectQuery, options qb.LimitOffset) errors.TracerError
+	// SelectContext executes a given select query and populates the target
+	SelectContext(ctx context.Context, target interface{}, query *qb.SelectQuery,
+		options qb.LimitOffset) errors.TracerError
+	// SelectRows executes a given select query and yields the result set
+	// positioned at each row in turn. A transaction is started if one is not in
+	// progress and is ended once iteration completes. The maximum query limit is
+	// not enforced as rows are not loaded into memory. See Stream.
+	SelectRows(query *qb.SelectQuery, options qb.LimitOffset) iter.Seq2[*sqlx.Rows, error]
+	// SelectRowsContext is SelectRows with a context that is passed to the driver
+	SelectRowsContext(ctx context.Context, query *qb.SelectQuery,
+		options qb.LimitOffset) iter.Seq2[*sqlx.Rows, error]
+	// NextPage populates target, which must be a pointer to a slice, with up to
+	// limit rows from the query that sort after the passed cursor using keyset
+	// pagination on the OrderBy fields of the query. An empty cursor selects the
+	// first page. The cursor for the following page is returned, it is empty
+	// when there are no more rows or there is no limit.
+	NextPage(target interface{}, query *qb.SelectQuery, cursor string,
+		limit uint) (string, errors.TracerError)
+	// NextPageContext is NextPage with a context that is passed to the driver
+	NextPageContext(ctx context.Context, target interface{}, query *qb.SelectQuery,
+		cursor string, limit uint) (string, errors.TracerError)
 	// ListWhere populates target with a list of records from the database
 	ListWhere(meta record.Record, target interface{},
 		condition *qb.ConditionExpression, options qb.LimitOffset) errors.TracerError
-	// Update replaces an entry in the database for the Record using a transaction
+	// ListWhereContext populates target with a list of records from the database
+	ListWhereContext(ctx context.Context, meta record.Record, target interface{},
+		condition *qb.ConditionExpression, options qb.LimitOffset) errors.TracerError
+	// Update replaces an entry in the database for the Record using a transaction,
+	// see transaction.Transaction for versioned records
 	Update(obj record.Record) errors.TracerError
+	// UpdateContext replaces an entry in the database for the Record using a transaction
+	UpdateContext(ctx context.Context, obj record.Record) errors.TracerError
 	// UpdateWhere updates fields for the Record based on a supplied where clause
 	UpdateWhere(obj record.Record, where *qb.ConditionExpression,
 		fields ...qb.FieldValue) (int64, errors.TracerError)
+	// UpdateWhereContext updates fields for the Record based on a supplied where clause
+	UpdateWhereContext(ctx context.Context, obj record.Record, where *qb.ConditionExpression,
+		fields ...qb.FieldValue) (int64, errors.TracerError)
 	// UpdateIgnoreWhere updates fields for the Record based on a supplied where clause in a transaction,
 	// continuing on any ignorable errors.
 	UpdateIgnoreWhere(record.Record, *qb.ConditionExpression, ...qb.FieldValue) (int64, errors.TracerError)
+	// UpdateIgnoreWhereContext updates fields for the Record based on a supplied where clause in a
+	// transaction, continuing on any ignorable errors.
+	UpdateIgnoreWhereContext(context.Context, record.Record, *qb.ConditionExpression,
+		...qb.FieldValue) (int64, errors.TracerError)
 	// Delete removes a row from the database
 	Delete(obj record.Record) errors.TracerError
+	// DeleteContext removes a row from the database
+	DeleteContext(ctx context.Context, obj record.Record) errors.TracerError
 	// DeleteWhere removes row(s) from the database based on a supplied where
 	// clause in a transaction
 	DeleteWhere(obj record.Record, condition *qb.ConditionExpression) errors.TracerError
+	// DeleteWhereContext removes row(s) from the database based on a supplied
+	// where clause in a transaction
+	DeleteWhereContext(ctx context.Context, obj record.Record, condition *qb.ConditionExpression) errors.TracerError
+	// Restore clears the deleted column of a soft deleted record and reads it
+	Restore(obj record.Record) errors.TracerError
+	// RestoreContext clears the deleted column of a soft deleted record and reads it
+	RestoreContext(ctx context.Context, obj record.Record) errors.TracerError
+	// History of the changes made to a record, oldest first. Changes are only
+	// recorded when the Configuration enables AuditChanges.
+	History(obj record.Record) ([]*audit.Entry, errors.TracerError)
+	// HistoryContext of the changes made to a record, oldest first
+	HistoryContext(ctx context.Context, obj record.Record) ([]*audit.Entry, errors.TracerError)
 }
 
 // SelectWithTotal executes the select query populating target and returning the
 // total records possible
 func SelectWithTotal[T any](db API, table qb.Table, target T,
+	query *qb.SelectQuery, options qb.LimitOffset) (T, int, error) {
+	return SelectWithTotalContext(context.Background(), db, table, target, query, options)
+}
+
+// SelectWithTotalContext executes the select query populating target and
+// returning the total records possible. Soft deleted rows of table are excluded
+// from both the rows and the total, see record.ScopeDeleted.
+func SelectWithTotalContext[T any](ctx context.Context, db API, table qb.Table, target T,
 	query *qb.SelectQuery, options qb.LimitOffset) (T, int, error) {
 	var (
 		total int32
 		err   error
 	)
-	if total, err = db.Count(table, query); err != nil || options.Limit() == 0 || total == 0 {
+	if total, err = db.CountContext(ctx, table, query); err != nil || options.Limit() == 0 || total == 0 {
 		return target, int(total), err
 	}
 
-	err = db.Select(&target, query, options)
+	// scope the rows the same way CountContext scopes the total
+	scoped := query.SelectFrom(query.GetSelectExpressions()...)
+	scoped.Where(record.ScopeDeleted(ctx, table, query.GetWhere()))
+	err = db.SelectContext(ctx, &target, scoped, options)
 	if nil != err {
 		return target, 0, err
 	}
 	return target, int(total), err
 }
 
+// Stream executes the select query and yields each row scanned into a T, which
+// must be a struct with db tags matching the selected columns. Rows are read
+// from the driver as the sequence is consumed, breaking out of the loop closes
+// them and ends the transaction started for the query.
+func Stream[T any](db API, query *qb.SelectQuery, options qb.LimitOffset) iter.Seq2[T, error] {
+	return StreamContext[T](context.Background(), db, query, options)
+}
+
+// StreamContext is Stream with a context that is passed to the driver
+func StreamContext[T any](ctx context.Context, db API, query *qb.SelectQuery,
+	options qb.LimitOffset) iter.Seq2[T, error] {
+	return transaction.Scan[T](db.SelectRowsContext(ctx, query, options))
+}
+
 // ErrMissingTransaction is returned when a call requiring a transaction is made
 // prior to Begin being called.
 var ErrMissingTransaction = errors.New("missing transaction")
 
+const (
+	savepointNameFormat  = "sp_%d"
+	savepointFormat      = "SAVEPOINT %s"
+	releaseFormat        = "RELEASE SAVEPOINT %s"
+	rollbackToFormat     = "ROLLBACK TO SAVEPOINT %s"
+	transactionPanicking = "rolling back transaction due to panic: %v"
+	transactionRetrying  = "retrying transaction in %s (attempt %d of %d): %s"
+)
+
 type api struct {
 	tx            transaction.Transaction
+	savepoints    []string
 	db            *transactable
+	replicas      *replicaSet
 	configuration Configuration
 }
 
 func (d *api) Begin() errors.TracerError {
+	return d.BeginContext(context.Background())
+}
+
+func (d *api) BeginContext(ctx context.Context) errors.TracerError {
 	if d.tx != nil {
-		return nil
+		return d.savepoint(ctx)
 	}
-	var err error
-	d.tx, err = transaction.New(
-		d.db,
+	var err errors.TracerError
+	d.tx, err = d.begin(ctx, d.db)
+	return err
+}
+
+// dialect of the database this API is connected to
+func (d *api) dialect() qb.Dialect {
+	return qb.DialectFor(d.configuration.DatabaseDialect())
+}
+
+// begin a transaction on the passed database without making it the current
+// transaction
+func (d *api) begin(ctx context.Context, db *transactable) (transaction.Transaction, errors.TracerError) {
+	tx, err := transaction.NewContext(
+		ctx,
+		db,
+		d.dialect(),
 		d.configuration.Logger(),
 		d.configuration.SlowQueryThreshold(),
 		d.configuration.LoggedSlowQueries(),
+		queryInterceptors(d.configuration, db)...,
 	)
-	return errors.Wrap(err)
+	if nil != err {
+		return nil, errors.Wrap(err)
+	}
+	tx.SetAudit(d.configuration.AuditChanges())
+	return tx, nil
 }
 
 func (d *api) GetTransaction() transaction.Transaction {
 	return d.tx
 }
 
-func (d *api) Rollback() errors.TracerError {
-	if d.tx != nil {
-		err := d.tx.Rollback()
-		d.tx = nil
-		return err
+func (d *api) Depth() int {
+	if d.tx == nil {
+		return 0
 	}
+	return len(d.savepoints) + 1
+}
 
-	return ErrMissingTransaction
+func (d *api) Rollback() errors.TracerError {
+	if d.tx == nil {
+		return ErrMissingTransaction
+	}
+	if len(d.savepoints) > 0 {
+		return d.endSavepoint(rollbackToFormat)
+	}
+	err := d.tx.Rollback()
+	d.tx = nil
+	return err
 }
 
 func (d *api) Commit() errors.TracerError {
-	if d.tx != nil {
-		err := d.tx.Commit()
-		d.tx = nil
+	if d.tx == nil {
+		return ErrMissingTransaction
+	}
+	if len(d.savepoints) > 0 {
+		return d.endSavepoint(releaseFormat)
+	}
+	err := d.tx.Commit()
+	d.tx = nil
+	if nil == err && nil != d.replicas {
+		d.replicas.commit()
+	}
+	return err
+}
+
+func (d *api) CommitOrRollback(err error) errors.TracerError {
+	if d.tx == nil {
+		return ErrMissingTransaction
+	}
+	if len(d.savepoints) > 0 {
+		if nil != err {
+			_ = d.configuration.Logger().Error(d.endSavepoint(rollbackToFormat))
+			return errors.Wrap(err)
+		}
+		return d.endSavepoint(releaseFormat)
+	}
+	err = utility.CommitOrRollback(d.tx, err, d.configuration.Logger())
+	d.tx = nil
+	if nil == err && nil != d.replicas {
+		d.replicas.commit()
+	}
+	return errors.Wrap(err)
+}
+
+func (d *api) WithTransaction(fn func(API) error) errors.TracerError {
+	return d.WithTransactionContext(context.Background(), fn)
+}
+
+func (d *api) WithTransactionContext(ctx context.Context, fn func(API) error) errors.TracerError {
+	if err := d.BeginContext(ctx); nil != err {
 		return err
 	}
+	defer func() {
+		if r := recover(); r != nil {
+			d.configuration.Logger().Errorf(transactionPanicking, r)
+			_ = d.configuration.Logger().Error(d.Rollback())
+			panic(r)
+		}
+	}()
+	return d.CommitOrRollback(fn(d))
+}
 
-	return ErrMissingTransaction
+func (d *api) WithRetryingTransaction(fn func(API) error) errors.TracerError {
+	return d.WithRetryingTransactionContext(context.Background(), fn)
 }
 
-func (d *api) CommitOrRollback(err error) errors.TracerError {
+func (d *api) WithRetryingTransactionContext(ctx context.Context, fn func(API) error) errors.TracerError {
 	if d.tx != nil {
-		err = utility.CommitOrRollback(d.tx, err, d.configuration.Logger())
-		d.tx = nil
-		return errors.Wrap(err)
+		// the database rolls back the entire transaction on a deadlock so only
+		// the outermost transaction can be retried
+		return d.WithTransactionContext(ctx, fn)
 	}
+	var (
+		err         errors.TracerError
+		maxAttempts = d.configuration.MaxTransactionAttempts()
+		r           = rand.New(rand.NewSource(time.Now().UnixNano()))
+	)
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = d.WithTransactionContext(ctx, fn)
+		if !dberrors.IsRetryableError(err) || attempt == maxAttempts {
+			break
+		}
+		wait := net.CalculateBackoff(r, attempt,
+			d.configuration.MinimumWaitBetweenTransactionRetries(),
+			d.configuration.MaxWaitBetweenTransactionRetries(),
+			time.Millisecond)
+		d.configuration.Logger().Warnf(transactionRetrying, wait, attempt+1, maxAttempts, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+	return err
+}
+
+// savepoint creates a new savepoint on the current transaction and pushes it
+// on to the stack
+func (d *api) savepoint(ctx context.Context) errors.TracerError {
+	name := fmt.Sprintf(savepointNameFormat, len(d.savepoints)+1)
+	stmt := fmt.Sprintf(savepointFormat, name)
+	if _, err := d.tx.Implementation().ExecContext(ctx, stmt); nil != err {
+		return dberrors.TranslateErrorFor(d.dialect(), err, dberrors.Savepoint, stmt)
+	}
+	d.savepoints = append(d.savepoints, name)
+	return nil
+}
+
+// endSavepoint pops the innermost savepoint from the stack and either releases
+// or rolls back to it depending on the passed format
+func (d *api) endSavepoint(format string) errors.TracerError {
+	name := d.savepoints[len(d.savepoints)-1]
+	d.savepoints = d.savepoints[:len(d.savepoints)-1]
+	stmt := fmt.Sprintf(format, name)
+	if _, err := d.tx.Implementation().ExecContext(context.Background(), stmt); nil != err {
+		return dberrors.TranslateErrorFor(d.dialect(), err, dberrors.Savepoint, stmt)
+	}
+	return nil
+}
 
-	return ErrMissingTransaction
+func (d *api) Count(table qb.Table, query *qb.SelectQuery) (int32, error) {
+	return d.CountContext(context.Background(), table, query)
 }
 
-func (db *api) Count(table qb.Table, query *qb.SelectQuery) (int32, error) {
+func (db *api) CountContext(ctx context.Context, table qb.Table, query *qb.SelectQuery) (int32, error) {
 	var (
 		target           []*qb.RowCount
 		err              error
@@ -146,8 +415,9 @@ func (db *api) Count(table qb.Table, query *qb.SelectQuery) (int32, error) {
 	if query.GetDistinct() {
 		selectExpression = qb.NewCountDistinct(query.GetSelectExpressions())
 	}
-	err = db.Select(&target,
-		query.SelectFrom(selectExpression),
+	count := query.SelectFrom(selectExpression)
+	count.Where(record.ScopeDeleted(ctx, table, query.GetWhere()))
+	err = db.SelectContext(ctx, &target, count,
 		qb.NewLimitOffset[int]().SetLimit(1).SetOffset(0),
 	)
 	if err != nil {
@@ -161,19 +431,31 @@ func (db *api) Count(table qb.Table, query *qb.SelectQuery) (int32, error) {
 }
 
 func (d *api) CountWhere(table qb.Table, where *qb.ConditionExpression) (int32, error) {
-	return d.Count(table,
+	return d.CountWhereContext(context.Background(), table, where)
+}
+
+func (d *api) CountWhereContext(ctx context.Context, table qb.Table,
+	where *qb.ConditionExpression) (int32, error) {
+	return d.CountContext(ctx, table,
 		qb.Select(qb.NewCountExpression(table.GetName())).
 			From(table).
 			Where(where))
 }
 
-func (db *api) Sum(field qb.TableField, query *qb.SelectQuery) (int32, error) {
+func (d *api) Sum(field qb.TableField, query *qb.SelectQuery) (int32, error) {
+	return d.SumContext(context.Background(), field, query)
+}
+
+func (db *api) SumContext(ctx context.Context, field qb.TableField, query *qb.SelectQuery) (int32, error) {
 	var (
 		target []*qb.SumResult
 		err    error
 	)
-	err = db.Select(&target,
-		query.SelectFrom(qb.NewSumExpression(field.Table, field)),
+	sum := query.SelectFrom(qb.NewSumExpression(field.Table, field))
+	if from := query.GetFrom(); nil != from {
+		sum.Where(record.ScopeDeleted(ctx, from, query.GetWhere()))
+	}
+	err = db.SelectContext(ctx, &target, sum,
 		qb.NewLimitOffset[int]().SetLimit(1).SetOffset(0),
 	)
 	if err != nil {
@@ -186,53 +468,139 @@ func (db *api) Sum(field qb.TableField, query *qb.SelectQuery) (int32, error) {
 }
 
 func (d *api) Create(obj record.Record) errors.TracerError {
-	return d.runInTransaction(func(tx transaction.Transaction) errors.TracerError {
-		return tx.Create(obj)
+	return d.CreateContext(context.Background(), obj)
+}
+
+func (d *api) CreateContext(ctx context.Context, obj record.Record) errors.TracerError {
+	return d.runInTransaction(ctx, func(tx transaction.Transaction) errors.TracerError {
+		return tx.CreateContext(ctx, obj)
 	})
 }
 
 func (d *api) Read(obj record.Record, pk record.PrimaryKeyValue) errors.TracerError {
-	return d.runInTransaction(func(tx transaction.Transaction) errors.TracerError {
-		return tx.Read(obj, pk)
+	return d.ReadContext(context.Background(), obj, pk)
+}
+
+func (d *api) ReadContext(ctx context.Context, obj record.Record, pk record.PrimaryKeyValue) errors.TracerError {
+	return d.runRead(ctx, func(tx transaction.Transaction) errors.TracerError {
+		return tx.ReadContext(ctx, obj, pk)
 	})
 }
 
 func (d *api) ReadOneWhere(obj record.Record, condition *qb.ConditionExpression) errors.TracerError {
-	return d.runInTransaction(func(tx transaction.Transaction) errors.TracerError {
-		return tx.ReadOneWhere(obj, condition)
+	return d.ReadOneWhereContext(context.Background(), obj, condition)
+}
+
+func (d *api) ReadOneWhereContext(ctx context.Context, obj record.Record,
+	condition *qb.ConditionExpression) errors.TracerError {
+	return d.runRead(ctx, func(tx transaction.Transaction) errors.TracerError {
+		return tx.ReadOneWhereContext(ctx, obj, condition)
 	})
 }
 
 func (d *api) Select(target any, query *qb.SelectQuery,
+	options qb.LimitOffset) errors.TracerError {
+	return d.SelectContext(context.Background(), target, query, options)
+}
+
+func (d *api) SelectContext(ctx context.Context, target any, query *qb.SelectQuery,
 	options qb.LimitOffset) errors.TracerError {
 	options = d.enforceLimits(options)
-	return d.runInTransaction(func(tx transaction.Transaction) errors.TracerError {
-		return tx.Select(target, query, options)
+	return d.runRead(ctx, func(tx transaction.Transaction) errors.TracerError {
+		return tx.SelectContext(ctx, target, query, options)
 	})
 }
 
+func (d *api) SelectRows(query *qb.SelectQuery,
+	options qb.LimitOffset) iter.Seq2[*sqlx.Rows, error] {
+	return d.SelectRowsContext(context.Background(), query, options)
+}
+
+func (d *api) SelectRowsContext(ctx context.Context, query *qb.SelectQuery,
+	options qb.LimitOffset) iter.Seq2[*sqlx.Rows, error] {
+	return func(yield func(*sqlx.Rows, error) bool) {
+		// done is set once the caller has seen an error or stopped iterating
+		var done bool
+		err := d.runInTransaction(ctx, func(tx transaction.Transaction) errors.TracerError {
+			for rows, err := range tx.SelectRowsContext(ctx, query, options) {
+				if nil != err {
+					done = true
+					yield(nil, err)
+					return errors.Wrap(err)
+				}
+				if !yield(rows, nil) {
+					done = true
+					return nil
+				}
+			}
+			return nil
+		})
+		if nil != err && !done {
+			yield(nil, err)
+		}
+	}
+}
+
+func (d *api) NextPage(target interface{}, query *qb.SelectQuery, cursor string,
+	limit uint) (string, errors.TracerError) {
+	return d.NextPageContext(context.Background(), target, query, cursor, limit)
+}
+
+func (d *api) NextPageContext(ctx context.Context, target interface{}, query *qb.SelectQuery,
+	cursor string, limit uint) (string, errors.TracerError) {
+	encoder := qb.NewCursorEncoder(d.configuration.CursorKey())
+	page, err := query.NextPage(encoder, cursor)
+	if nil != err {
+		return "", errors.Wrap(err)
+	}
+	options := d.enforceLimits(qb.NewLimitOffset[uint]().SetLimit(limit))
+	if tracerErr := d.SelectContext(ctx, target, page, options); nil != tracerErr {
+		return "", tracerErr
+	}
+	values, ok, err := lastRowSortValues(target, query.GetOrderBy(), options.Limit())
+	if nil != err || !ok {
+		return "", errors.Wrap(err)
+	}
+	next, err := encoder.Encode(query, values...)
+	return next, errors.Wrap(err)
+}
+
 func (d *api) ListWhere(meta record.Record, target interface{},
+	condition *qb.ConditionExpression, options qb.LimitOffset) errors.TracerError {
+	return d.ListWhereContext(context.Background(), meta, target, condition, options)
+}
+
+func (d *api) ListWhereContext(ctx context.Context, meta record.Record, target interface{},
 	condition *qb.ConditionExpression, options qb.LimitOffset) errors.TracerError {
 	options = d.enforceLimits(options)
-	return d.runInTransaction(func(tx transaction.Transaction) errors.TracerError {
-		return tx.ListWhere(meta, target, condition, options)
+	return d.runRead(ctx, func(tx transaction.Transaction) errors.TracerError {
+		return tx.ListWhereContext(ctx, meta, target, condition, options)
 	})
 }
 
 func (d *api) Update(obj record.Record) errors.TracerError {
-	return d.runInTransaction(func(tx transaction.Transaction) errors.TracerError {
-		return tx.Update(obj)
+	return d.UpdateContext(context.Background(), obj)
+}
+
+func (d *api) UpdateContext(ctx context.Context, obj record.Record) errors.TracerError {
+	return d.runInTransaction(ctx, func(tx transaction.Transaction) errors.TracerError {
+		return tx.UpdateContext(ctx, obj)
 	})
 }
 
 func (d *api) UpdateWhere(obj record.Record, where *qb.ConditionExpression,
+	fields ...qb.FieldValue) (int64, errors.TracerError) {
+	return d.UpdateWhereContext(context.Background(), obj, where, fields...)
+}
+
+func (d *api) UpdateWhereContext(ctx context.Context, obj record.Record, where *qb.ConditionExpression,
 	fields ...qb.FieldValue) (int64, errors.TracerError) {
 	var (
 		total int64
 		err   errors.TracerError
 	)
-	err = d.runInTransaction(func(tx transaction.Transaction) errors.TracerError {
-		total, err = tx.UpdateWhere(obj, where, fields...)
+	err = d.runInTransaction(ctx, func(tx transaction.Transaction) errors.TracerError {
+		total, err = tx.UpdateWhereContext(ctx, obj, where, fields...)
 		return err
 	})
 
@@ -240,13 +608,18 @@ func (d *api) UpdateWhere(obj record.Record, where *qb.ConditionExpression,
 }
 
 func (d *api) UpdateIgnoreWhere(obj record.Record, where *qb.ConditionExpression,
+	fields ...qb.FieldValue) (int64, errors.TracerError) {
+	return d.UpdateIgnoreWhereContext(context.Background(), obj, where, fields...)
+}
+
+func (d *api) UpdateIgnoreWhereContext(ctx context.Context, obj record.Record, where *qb.ConditionExpression,
 	fields ...qb.FieldValue) (int64, errors.TracerError) {
 	var (
 		total int64
 		err   errors.TracerError
 	)
-	err = d.runInTransaction(func(tx transaction.Transaction) errors.TracerError {
-		total, err = tx.UpdateIgnoreWhere(obj, where, fields...)
+	err = d.runInTransaction(ctx, func(tx transaction.Transaction) errors.TracerError {
+		total, err = tx.UpdateIgnoreWhereContext(ctx, obj, where, fields...)
 		return err
 	})
 
@@ -254,17 +627,52 @@ func (d *api) UpdateIgnoreWhere(obj record.Record, where *qb.ConditionExpression
 }
 
 func (d *api) Delete(obj record.Record) errors.TracerError {
-	return d.runInTransaction(func(tx transaction.Transaction) errors.TracerError {
-		return tx.Delete(obj)
+	return d.DeleteContext(context.Background(), obj)
+}
+
+func (d *api) DeleteContext(ctx context.Context, obj record.Record) errors.TracerError {
+	return d.runInTransaction(ctx, func(tx transaction.Transaction) errors.TracerError {
+		return tx.DeleteContext(ctx, obj)
 	})
 }
 
 func (d *api) DeleteWhere(obj record.Record, condition *qb.ConditionExpression) errors.TracerError {
-	return d.runInTransaction(func(tx transaction.Transaction) errors.TracerError {
-		return tx.DeleteWhere(obj, condition)
+	return d.DeleteWhereContext(context.Background(), obj, condition)
+}
+
+func (d *api) DeleteWhereContext(ctx context.Context, obj record.Record,
+	condition *qb.ConditionExpression) errors.TracerError {
+	return d.runInTransaction(ctx, func(tx transaction.Transaction) errors.TracerError {
+		return tx.DeleteWhereContext(ctx, obj, condition)
+	})
+}
+
+func (d *api) Restore(obj record.Record) errors.TracerError {
+	return d.RestoreContext(context.Background(), obj)
+}
+
+func (d *api) RestoreContext(ctx context.Context, obj record.Record) errors.TracerError {
+	return d.runInTransaction(ctx, func(tx transaction.Transaction) errors.TracerError {
+		return tx.RestoreContext(ctx, obj)
 	})
 }
 
+func (d *api) History(obj record.Record) ([]*audit.Entry, errors.TracerError) {
+	return d.HistoryContext(context.Background(), obj)
+}
+
+func (d *api) HistoryContext(ctx context.Context, obj record.Record) ([]*audit.Entry, errors.TracerError) {
+	var (
+		entries []*audit.Entry
+		err     errors.TracerError
+	)
+	err = d.runInTransaction(ctx, func(tx transaction.Transaction) errors.TracerError {
+		entries, err = tx.HistoryContext(ctx, obj)
+		return err
+	})
+	return entries, err
+}
+
 func (d *api) enforceLimits(options qb.LimitOffset) qb.LimitOffset {
 	if options == nil {
 		options = qb.NewLimitOffset[uint]()
@@ -279,14 +687,46 @@ func (d *api) enforceLimits(options qb.LimitOffset) qb.LimitOffset {
 	return options
 }
 
-func (d *api) runInTransaction(fn func(transaction.Transaction) errors.TracerError) errors.TracerError {
+// runRead calls fn in the current transaction if there is one. Otherwise fn is
+// called in a read only transaction on a healthy replica, or on the primary
+// when there are none or a transaction committed within the read your writes
+// window.
+func (d *api) runRead(ctx context.Context,
+	fn func(transaction.Transaction) errors.TracerError) errors.TracerError {
+	if d.tx != nil || nil == d.replicas {
+		return d.runInTransaction(ctx, fn)
+	}
+	if !d.replicas.pinned(d.configuration.ReadYourWritesWindow()) {
+		for r := d.replicas.choose(); nil != r; r = d.replicas.choose() {
+			tx, err := d.begin(ctx, r.db)
+			if nil != ctx.Err() {
+				return errors.Wrap(ctx.Err())
+			}
+			if nil != err {
+				d.replicas.fail(r, err)
+				continue
+			}
+			return utility.CommitOrRollback(tx, fn(tx), d.configuration.Logger())
+		}
+	}
+	// reads made on the primary outside of a transaction do not extend the read
+	// your writes window
+	tx, err := d.begin(ctx, d.db)
+	if nil != err {
+		return err
+	}
+	return utility.CommitOrRollback(tx, fn(tx), d.configuration.Logger())
+}
+
+func (d *api) runInTransaction(ctx context.Context,
+	fn func(transaction.Transaction) errors.TracerError) errors.TracerError {
 	var (
 		err    errors.TracerError
 		commit bool
 	)
 	if d.tx == nil {
 		commit = true
-		err = d.Begin()
+		err = d.BeginContext(ctx)
 	}
 	if nil != err {
 		return err
@@ -10,12 +10,16 @@
 package database
 
 import (
+	context "context"
+	iter "iter"
 	reflect "reflect"
 
+	audit "github.com/beaconsoftwarellc/gadget/v2/database/audit"
 	qb "github.com/beaconsoftwarellc/gadget/v2/database/qb"
 	record "github.com/beaconsoftwarellc/gadget/v2/database/record"
 	transaction "github.com/beaconsoftwarellc/gadget/v2/database/transaction"
 	errors "github.com/beaconsoftwarellc/gadget/v2/errors"
+	sqlx "github.com/jmoiron/sqlx"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -57,6 +61,20 @@ func (mr *MockAPIMockRecorder) Begin() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockAPI)(nil).Begin))
 }
 
+// BeginContext mocks base method.
+func (m *MockAPI) BeginContext(ctx context.Context) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginContext", ctx)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// BeginContext indicates an expected call of BeginContext.
+func (mr *MockAPIMockRecorder) BeginContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginContext", reflect.TypeOf((*MockAPI)(nil).BeginContext), ctx)
+}
+
 // Commit mocks base method.
 func (m *MockAPI) Commit() errors.TracerError {
 	m.ctrl.T.Helper()
@@ -100,6 +118,21 @@ func (mr *MockAPIMockRecorder) Count(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockAPI)(nil).Count), arg0, arg1)
 }
 
+// CountContext mocks base method.
+func (m *MockAPI) CountContext(arg0 context.Context, arg1 qb.Table, arg2 *qb.SelectQuery) (int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountContext indicates an expected call of CountContext.
+func (mr *MockAPIMockRecorder) CountContext(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountContext", reflect.TypeOf((*MockAPI)(nil).CountContext), arg0, arg1, arg2)
+}
+
 // CountWhere mocks base method.
 func (m *MockAPI) CountWhere(arg0 qb.Table, arg1 *qb.ConditionExpression) (int32, error) {
 	m.ctrl.T.Helper()
@@ -115,6 +148,21 @@ func (mr *MockAPIMockRecorder) CountWhere(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountWhere", reflect.TypeOf((*MockAPI)(nil).CountWhere), arg0, arg1)
 }
 
+// CountWhereContext mocks base method.
+func (m *MockAPI) CountWhereContext(arg0 context.Context, arg1 qb.Table, arg2 *qb.ConditionExpression) (int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountWhereContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountWhereContext indicates an expected call of CountWhereContext.
+func (mr *MockAPIMockRecorder) CountWhereContext(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountWhereContext", reflect.TypeOf((*MockAPI)(nil).CountWhereContext), arg0, arg1, arg2)
+}
+
 // Create mocks base method.
 func (m *MockAPI) Create(obj record.Record) errors.TracerError {
 	m.ctrl.T.Helper()
@@ -129,6 +177,20 @@ func (mr *MockAPIMockRecorder) Create(obj any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAPI)(nil).Create), obj)
 }
 
+// CreateContext mocks base method.
+func (m *MockAPI) CreateContext(ctx context.Context, obj record.Record) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateContext", ctx, obj)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// CreateContext indicates an expected call of CreateContext.
+func (mr *MockAPIMockRecorder) CreateContext(ctx, obj any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateContext", reflect.TypeOf((*MockAPI)(nil).CreateContext), ctx, obj)
+}
+
 // Delete mocks base method.
 func (m *MockAPI) Delete(obj record.Record) errors.TracerError {
 	m.ctrl.T.Helper()
@@ -143,6 +205,20 @@ func (mr *MockAPIMockRecorder) Delete(obj any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAPI)(nil).Delete), obj)
 }
 
+// DeleteContext mocks base method.
+func (m *MockAPI) DeleteContext(ctx context.Context, obj record.Record) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteContext", ctx, obj)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// DeleteContext indicates an expected call of DeleteContext.
+func (mr *MockAPIMockRecorder) DeleteContext(ctx, obj any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteContext", reflect.TypeOf((*MockAPI)(nil).DeleteContext), ctx, obj)
+}
+
 // DeleteWhere mocks base method.
 func (m *MockAPI) DeleteWhere(obj record.Record, condition *qb.ConditionExpression) errors.TracerError {
 	m.ctrl.T.Helper()
@@ -157,6 +233,34 @@ func (mr *MockAPIMockRecorder) DeleteWhere(obj, condition any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWhere", reflect.TypeOf((*MockAPI)(nil).DeleteWhere), obj, condition)
 }
 
+// DeleteWhereContext mocks base method.
+func (m *MockAPI) DeleteWhereContext(ctx context.Context, obj record.Record, condition *qb.ConditionExpression) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWhereContext", ctx, obj, condition)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// DeleteWhereContext indicates an expected call of DeleteWhereContext.
+func (mr *MockAPIMockRecorder) DeleteWhereContext(ctx, obj, condition any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWhereContext", reflect.TypeOf((*MockAPI)(nil).DeleteWhereContext), ctx, obj, condition)
+}
+
+// Depth mocks base method.
+func (m *MockAPI) Depth() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Depth")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Depth indicates an expected call of Depth.
+func (mr *MockAPIMockRecorder) Depth() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Depth", reflect.TypeOf((*MockAPI)(nil).Depth))
+}
+
 // GetTransaction mocks base method.
 func (m *MockAPI) GetTransaction() transaction.Transaction {
 	m.ctrl.T.Helper()
@@ -171,6 +275,36 @@ func (mr *MockAPIMockRecorder) GetTransaction() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransaction", reflect.TypeOf((*MockAPI)(nil).GetTransaction))
 }
 
+// History mocks base method.
+func (m *MockAPI) History(obj record.Record) ([]*audit.Entry, errors.TracerError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", obj)
+	ret0, _ := ret[0].([]*audit.Entry)
+	ret1, _ := ret[1].(errors.TracerError)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockAPIMockRecorder) History(obj any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockAPI)(nil).History), obj)
+}
+
+// HistoryContext mocks base method.
+func (m *MockAPI) HistoryContext(ctx context.Context, obj record.Record) ([]*audit.Entry, errors.TracerError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HistoryContext", ctx, obj)
+	ret0, _ := ret[0].([]*audit.Entry)
+	ret1, _ := ret[1].(errors.TracerError)
+	return ret0, ret1
+}
+
+// HistoryContext indicates an expected call of HistoryContext.
+func (mr *MockAPIMockRecorder) HistoryContext(ctx, obj any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HistoryContext", reflect.TypeOf((*MockAPI)(nil).HistoryContext), ctx, obj)
+}
+
 // ListWhere mocks base method.
 func (m *MockAPI) ListWhere(meta record.Record, target any, condition *qb.ConditionExpression, options qb.LimitOffset) errors.TracerError {
 	m.ctrl.T.Helper()
@@ -185,6 +319,50 @@ func (mr *MockAPIMockRecorder) ListWhere(meta, target, condition, options any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWhere", reflect.TypeOf((*MockAPI)(nil).ListWhere), meta, target, condition, options)
 }
 
+// ListWhereContext mocks base method.
+func (m *MockAPI) ListWhereContext(ctx context.Context, meta record.Record, target any, condition *qb.ConditionExpression, options qb.LimitOffset) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWhereContext", ctx, meta, target, condition, options)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// ListWhereContext indicates an expected call of ListWhereContext.
+func (mr *MockAPIMockRecorder) ListWhereContext(ctx, meta, target, condition, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWhereContext", reflect.TypeOf((*MockAPI)(nil).ListWhereContext), ctx, meta, target, condition, options)
+}
+
+// NextPage mocks base method.
+func (m *MockAPI) NextPage(target any, query *qb.SelectQuery, cursor string, limit uint) (string, errors.TracerError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NextPage", target, query, cursor, limit)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(errors.TracerError)
+	return ret0, ret1
+}
+
+// NextPage indicates an expected call of NextPage.
+func (mr *MockAPIMockRecorder) NextPage(target, query, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextPage", reflect.TypeOf((*MockAPI)(nil).NextPage), target, query, cursor, limit)
+}
+
+// NextPageContext mocks base method.
+func (m *MockAPI) NextPageContext(ctx context.Context, target any, query *qb.SelectQuery, cursor string, limit uint) (string, errors.TracerError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NextPageContext", ctx, target, query, cursor, limit)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(errors.TracerError)
+	return ret0, ret1
+}
+
+// NextPageContext indicates an expected call of NextPageContext.
+func (mr *MockAPIMockRecorder) NextPageContext(ctx, target, query, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextPageContext", reflect.TypeOf((*MockAPI)(nil).NextPageContext), ctx, target, query, cursor, limit)
+}
+
 // Read mocks base method.
 func (m *MockAPI) Read(obj record.Record, pk record.PrimaryKeyValue) errors.TracerError {
 	m.ctrl.T.Helper()
@@ -199,6 +377,20 @@ func (mr *MockAPIMockRecorder) Read(obj, pk any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockAPI)(nil).Read), obj, pk)
 }
 
+// ReadContext mocks base method.
+func (m *MockAPI) ReadContext(ctx context.Context, obj record.Record, pk record.PrimaryKeyValue) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadContext", ctx, obj, pk)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// ReadContext indicates an expected call of ReadContext.
+func (mr *MockAPIMockRecorder) ReadContext(ctx, obj, pk any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadContext", reflect.TypeOf((*MockAPI)(nil).ReadContext), ctx, obj, pk)
+}
+
 // ReadOneWhere mocks base method.
 func (m *MockAPI) ReadOneWhere(obj record.Record, condition *qb.ConditionExpression) errors.TracerError {
 	m.ctrl.T.Helper()
@@ -213,6 +405,48 @@ func (mr *MockAPIMockRecorder) ReadOneWhere(obj, condition any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOneWhere", reflect.TypeOf((*MockAPI)(nil).ReadOneWhere), obj, condition)
 }
 
+// ReadOneWhereContext mocks base method.
+func (m *MockAPI) ReadOneWhereContext(ctx context.Context, obj record.Record, condition *qb.ConditionExpression) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadOneWhereContext", ctx, obj, condition)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// ReadOneWhereContext indicates an expected call of ReadOneWhereContext.
+func (mr *MockAPIMockRecorder) ReadOneWhereContext(ctx, obj, condition any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOneWhereContext", reflect.TypeOf((*MockAPI)(nil).ReadOneWhereContext), ctx, obj, condition)
+}
+
+// Restore mocks base method.
+func (m *MockAPI) Restore(obj record.Record) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", obj)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockAPIMockRecorder) Restore(obj any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockAPI)(nil).Restore), obj)
+}
+
+// RestoreContext mocks base method.
+func (m *MockAPI) RestoreContext(ctx context.Context, obj record.Record) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreContext", ctx, obj)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// RestoreContext indicates an expected call of RestoreContext.
+func (mr *MockAPIMockRecorder) RestoreContext(ctx, obj any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreContext", reflect.TypeOf((*MockAPI)(nil).RestoreContext), ctx, obj)
+}
+
 // Rollback mocks base method.
 func (m *MockAPI) Rollback() errors.TracerError {
 	m.ctrl.T.Helper()
@@ -241,6 +475,48 @@ func (mr *MockAPIMockRecorder) Select(target, query, options any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Select", reflect.TypeOf((*MockAPI)(nil).Select), target, query, options)
 }
 
+// SelectContext mocks base method.
+func (m *MockAPI) SelectContext(ctx context.Context, target any, query *qb.SelectQuery, options qb.LimitOffset) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectContext", ctx, target, query, options)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// SelectContext indicates an expected call of SelectContext.
+func (mr *MockAPIMockRecorder) SelectContext(ctx, target, query, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectContext", reflect.TypeOf((*MockAPI)(nil).SelectContext), ctx, target, query, options)
+}
+
+// SelectRows mocks base method.
+func (m *MockAPI) SelectRows(query *qb.SelectQuery, options qb.LimitOffset) iter.Seq2[*sqlx.Rows, error] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectRows", query, options)
+	ret0, _ := ret[0].(iter.Seq2[*sqlx.Rows, error])
+	return ret0
+}
+
+// SelectRows indicates an expected call of SelectRows.
+func (mr *MockAPIMockRecorder) SelectRows(query, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectRows", reflect.TypeOf((*MockAPI)(nil).SelectRows), query, options)
+}
+
+// SelectRowsContext mocks base method.
+func (m *MockAPI) SelectRowsContext(ctx context.Context, query *qb.SelectQuery, options qb.LimitOffset) iter.Seq2[*sqlx.Rows, error] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectRowsContext", ctx, query, options)
+	ret0, _ := ret[0].(iter.Seq2[*sqlx.Rows, error])
+	return ret0
+}
+
+// SelectRowsContext indicates an expected call of SelectRowsContext.
+func (mr *MockAPIMockRecorder) SelectRowsContext(ctx, query, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectRowsContext", reflect.TypeOf((*MockAPI)(nil).SelectRowsContext), ctx, query, options)
+}
+
 // Sum mocks base method.
 func (m *MockAPI) Sum(arg0 qb.TableField, arg1 *qb.SelectQuery) (int32, error) {
 	m.ctrl.T.Helper()
@@ -256,6 +532,21 @@ func (mr *MockAPIMockRecorder) Sum(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sum", reflect.TypeOf((*MockAPI)(nil).Sum), arg0, arg1)
 }
 
+// SumContext mocks base method.
+func (m *MockAPI) SumContext(arg0 context.Context, arg1 qb.TableField, arg2 *qb.SelectQuery) (int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumContext indicates an expected call of SumContext.
+func (mr *MockAPIMockRecorder) SumContext(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumContext", reflect.TypeOf((*MockAPI)(nil).SumContext), arg0, arg1, arg2)
+}
+
 // Update mocks base method.
 func (m *MockAPI) Update(obj record.Record) errors.TracerError {
 	m.ctrl.T.Helper()
@@ -270,6 +561,20 @@ func (mr *MockAPIMockRecorder) Update(obj any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAPI)(nil).Update), obj)
 }
 
+// UpdateContext mocks base method.
+func (m *MockAPI) UpdateContext(ctx context.Context, obj record.Record) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateContext", ctx, obj)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// UpdateContext indicates an expected call of UpdateContext.
+func (mr *MockAPIMockRecorder) UpdateContext(ctx, obj any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateContext", reflect.TypeOf((*MockAPI)(nil).UpdateContext), ctx, obj)
+}
+
 // UpdateIgnoreWhere mocks base method.
 func (m *MockAPI) UpdateIgnoreWhere(arg0 record.Record, arg1 *qb.ConditionExpression, arg2 ...qb.FieldValue) (int64, errors.TracerError) {
 	m.ctrl.T.Helper()
@@ -290,6 +595,26 @@ func (mr *MockAPIMockRecorder) UpdateIgnoreWhere(arg0, arg1 any, arg2 ...any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIgnoreWhere", reflect.TypeOf((*MockAPI)(nil).UpdateIgnoreWhere), varargs...)
 }
 
+// UpdateIgnoreWhereContext mocks base method.
+func (m *MockAPI) UpdateIgnoreWhereContext(arg0 context.Context, arg1 record.Record, arg2 *qb.ConditionExpression, arg3 ...qb.FieldValue) (int64, errors.TracerError) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateIgnoreWhereContext", varargs...)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(errors.TracerError)
+	return ret0, ret1
+}
+
+// UpdateIgnoreWhereContext indicates an expected call of UpdateIgnoreWhereContext.
+func (mr *MockAPIMockRecorder) UpdateIgnoreWhereContext(arg0, arg1, arg2 any, arg3 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIgnoreWhereContext", reflect.TypeOf((*MockAPI)(nil).UpdateIgnoreWhereContext), varargs...)
+}
+
 // UpdateWhere mocks base method.
 func (m *MockAPI) UpdateWhere(obj record.Record, where *qb.ConditionExpression, fields ...qb.FieldValue) (int64, errors.TracerError) {
 	m.ctrl.T.Helper()
@@ -309,3 +634,79 @@ func (mr *MockAPIMockRecorder) UpdateWhere(obj, where any, fields ...any) *gomoc
 	varargs := append([]any{obj, where}, fields...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWhere", reflect.TypeOf((*MockAPI)(nil).UpdateWhere), varargs...)
 }
+
+// UpdateWhereContext mocks base method.
+func (m *MockAPI) UpdateWhereContext(ctx context.Context, obj record.Record, where *qb.ConditionExpression, fields ...qb.FieldValue) (int64, errors.TracerError) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, obj, where}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateWhereContext", varargs...)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(errors.TracerError)
+	return ret0, ret1
+}
+
+// UpdateWhereContext indicates an expected call of UpdateWhereContext.
+func (mr *MockAPIMockRecorder) UpdateWhereContext(ctx, obj, where any, fields ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, obj, where}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWhereContext", reflect.TypeOf((*MockAPI)(nil).UpdateWhereContext), varargs...)
+}
+
+// WithRetryingTransaction mocks base method.
+func (m *MockAPI) WithRetryingTransaction(fn func(API) error) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithRetryingTransaction", fn)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// WithRetryingTransaction indicates an expected call of WithRetryingTransaction.
+func (mr *MockAPIMockRecorder) WithRetryingTransaction(fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithRetryingTransaction", reflect.TypeOf((*MockAPI)(nil).WithRetryingTransaction), fn)
+}
+
+// WithRetryingTransactionContext mocks base method.
+func (m *MockAPI) WithRetryingTransactionContext(ctx context.Context, fn func(API) error) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithRetryingTransactionContext", ctx, fn)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// WithRetryingTransactionContext indicates an expected call of WithRetryingTransactionContext.
+func (mr *MockAPIMockRecorder) WithRetryingTransactionContext(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithRetryingTransactionContext", reflect.TypeOf((*MockAPI)(nil).WithRetryingTransactionContext), ctx, fn)
+}
+
+// WithTransaction mocks base method.
+func (m *MockAPI) WithTransaction(fn func(API) error) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTransaction", fn)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// WithTransaction indicates an expected call of WithTransaction.
+func (mr *MockAPIMockRecorder) WithTransaction(fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTransaction", reflect.TypeOf((*MockAPI)(nil).WithTransaction), fn)
+}
+
+// WithTransactionContext mocks base method.
+func (m *MockAPI) WithTransactionContext(ctx context.Context, fn func(API) error) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTransactionContext", ctx, fn)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// WithTransactionContext indicates an expected call of WithTransactionContext.
+func (mr *MockAPIMockRecorder) WithTransactionContext(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTransactionContext", reflect.TypeOf((*MockAPI)(nil).WithTransactionContext), ctx, fn)
+}
@@ -1,13 +1,20 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/beaconsoftwarellc/gadget/v2/database/audit"
+	dberrors "github.com/beaconsoftwarellc/gadget/v2/database/errors"
 	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
 	"github.com/beaconsoftwarellc/gadget/v2/database/record"
 	"github.com/beaconsoftwarellc/gadget/v2/database/transaction"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
 	"github.com/beaconsoftwarellc/gadget/v2/generator"
+	"github.com/beaconsoftwarellc/gadget/v2/log"
+	"github.com/jmoiron/sqlx"
 	assert1 "github.com/stretchr/testify/assert"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -170,7 +177,7 @@ func Test_api_Count(t *testing.T) {
 	}
 	query := qb.Select(MetaTestRecord.ID).From(MetaTestRecord)
 	expected := generator.Int32()
-	transaction.EXPECT().Select(&countMatcher{count: expected},
+	transaction.EXPECT().SelectContext(gomock.Any(), &countMatcher{count: expected},
 		&queryMatcher{t: t, sql: "SELECT COUNT(*) as count FROM " +
 			"`test_record` AS `test_record`"},
 		qb.NewLimitOffset[int]().SetLimit(1).SetOffset(0),
@@ -190,7 +197,7 @@ func Test_api_CountWhere_nil(t *testing.T) {
 	}
 
 	expected := generator.Int32()
-	transaction.EXPECT().Select(&countMatcher{count: expected},
+	transaction.EXPECT().SelectContext(gomock.Any(), &countMatcher{count: expected},
 		&queryMatcher{t: t, sql: "SELECT COUNT(*) as count FROM " +
 			"`test_record` AS `test_record`"},
 		qb.NewLimitOffset[int]().SetLimit(1).SetOffset(0),
@@ -210,7 +217,7 @@ func Test_api_CountWhere(t *testing.T) {
 	}
 
 	expected := generator.Int32()
-	transaction.EXPECT().Select(&countMatcher{count: expected},
+	transaction.EXPECT().SelectContext(gomock.Any(), &countMatcher{count: expected},
 		&queryMatcher{t: t, sql: "SELECT COUNT(*) as count FROM `test_record` AS" +
 			" `test_record` WHERE `test_record`.`name` = ?"},
 		qb.NewLimitOffset[int]().SetLimit(1).SetOffset(0),
@@ -221,4 +228,295 @@ func Test_api_CountWhere(t *testing.T) {
 	assert.Equal(expected, actual)
 }
 
+type softDeletableTestRecord struct {
+	*metaTestRecord
+}
+
+func (t *softDeletableTestRecord) DeletedColumn() qb.TableField {
+	return qb.TableField{Name: record.DeletedOnColumn, Table: t.alias}
+}
+
+func Test_api_CountWhere_SoftDeletable(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	transaction := transaction.NewMockTransaction(ctrl)
+	api := &api{
+		tx:            transaction,
+		configuration: &InstanceConfig{MaxLimit: 100},
+	}
+	table := &softDeletableTestRecord{MetaTestRecord}
+	where := qb.FieldComparison(MetaTestRecord.Name, qb.Equal, "")
+
+	transaction.EXPECT().SelectContext(gomock.Any(), &countMatcher{count: 1},
+		&queryMatcher{t: t, sql: "SELECT COUNT(*) as count FROM `test_record` AS `test_record` " +
+			"WHERE (`test_record`.`name` = ? AND `test_record`.`deleted_on` IS NULL)"},
+		qb.NewLimitOffset[int]().SetLimit(1).SetOffset(0),
+	).Return(nil)
+	actual, err := api.CountWhere(table, where)
+	assert.NoError(err)
+	assert.Equal(int32(1), actual)
+
+	transaction.EXPECT().SelectContext(gomock.Any(), &countMatcher{count: 2},
+		&queryMatcher{t: t, sql: "SELECT COUNT(*) as count FROM `test_record` AS `test_record` " +
+			"WHERE `test_record`.`name` = ?"},
+		qb.NewLimitOffset[int]().SetLimit(1).SetOffset(0),
+	).Return(nil)
+	actual, err = api.CountWhereContext(record.WithDeleted(context.Background()), table, where)
+	assert.NoError(err)
+	assert.Equal(int32(2), actual)
+
+	// the condition passed is not modified
+	sql, _ := where.SQL()
+	assert.Equal("`test_record`.`name` = ?", sql)
+}
+
+func Test_SelectWithTotal_SoftDeletable(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	tx := transaction.NewMockTransaction(ctrl)
+	api := &api{
+		tx:            tx,
+		configuration: &InstanceConfig{MaxLimit: 100},
+	}
+	table := &softDeletableTestRecord{MetaTestRecord}
+	query := qb.Select(MetaTestRecord.ID, MetaTestRecord.Name).From(table).
+		Where(MetaTestRecord.Name.Equal("a"))
+	scope := "(`test_record`.`name` = ? AND `test_record`.`deleted_on` IS NULL)"
+
+	// one of the two matching rows is deleted
+	gomock.InOrder(
+		tx.EXPECT().SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, target any, query *qb.SelectQuery, _ qb.LimitOffset) errors.TracerError {
+				sql, _, err := query.SQL(nil)
+				assert.NoError(err)
+				assert.Equal("SELECT COUNT(*) as count FROM `test_record` AS `test_record` WHERE "+scope, sql)
+				*target.(*[]*qb.RowCount) = []*qb.RowCount{{Count: 1}}
+				return nil
+			}),
+		tx.EXPECT().SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, target any, query *qb.SelectQuery, _ qb.LimitOffset) errors.TracerError {
+				sql, _, err := query.SQL(nil)
+				assert.NoError(err)
+				assert.Equal("SELECT `test_record`.`id`, `test_record`.`name` FROM `test_record` AS "+
+					"`test_record` WHERE "+scope, sql)
+				*target.(*[]*TestRecord) = []*TestRecord{{ID: "1", Name: "a"}}
+				return nil
+			}),
+	)
+	rows, total, err := SelectWithTotal(api, table, []*TestRecord{}, query,
+		qb.NewLimitOffset[int]().SetLimit(10))
+	assert.NoError(err)
+	assert.Equal(len(rows), total)
+
+	// the query passed is not modified
+	sql, _, _ := query.SQL(nil)
+	assert.Equal("SELECT `test_record`.`id`, `test_record`.`name` FROM `test_record` AS "+
+		"`test_record` WHERE `test_record`.`name` = ?", sql)
+}
+
+func Test_api_Sum_SoftDeletable(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	tx := transaction.NewMockTransaction(ctrl)
+	api := &api{
+		tx:            tx,
+		configuration: &InstanceConfig{MaxLimit: 100},
+	}
+	table := &softDeletableTestRecord{MetaTestRecord}
+	tx.EXPECT().SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, target any, query *qb.SelectQuery, _ qb.LimitOffset) errors.TracerError {
+			sql, _, err := query.SQL(nil)
+			assert.NoError(err)
+			assert.Contains(sql, "WHERE `test_record`.`deleted_on` IS NULL")
+			sum := 3
+			*target.(*[]*qb.SumResult) = []*qb.SumResult{{Sum: &sum}}
+			return nil
+		})
+	actual, err := api.Sum(MetaTestRecord.Name, qb.Select(MetaTestRecord.Name).From(table))
+	assert.NoError(err)
+	assert.Equal(int32(3), actual)
+}
+
 // TODO: [COR-587] finish tests for API
+
+func Test_api_ReadContext(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	transaction := transaction.NewMockTransaction(ctrl)
+	api := &api{
+		tx:            transaction,
+		configuration: &InstanceConfig{MaxLimit: 100},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	obj := &TestRecord{ID: generator.ID("test")}
+	transaction.EXPECT().ReadContext(ctx, obj, obj.PrimaryKey()).Return(nil)
+	assert.NoError(api.ReadContext(ctx, obj, obj.PrimaryKey()))
+}
+
+func Test_api_HistoryContext(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	transaction := transaction.NewMockTransaction(ctrl)
+	api := &api{
+		tx:            transaction,
+		configuration: &InstanceConfig{MaxLimit: 100},
+	}
+	ctx := context.Background()
+	obj := &TestRecord{ID: generator.ID("test")}
+	expected := []*audit.Entry{{ID: "AUD1", RecordID: obj.ID, Operation: audit.Create}}
+	transaction.EXPECT().HistoryContext(ctx, obj).Return(expected, nil)
+	actual, err := api.HistoryContext(ctx, obj)
+	assert.NoError(err)
+	assert.Equal(expected, actual)
+}
+
+func Test_api_Savepoints(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	tx := transaction.NewMockTransaction(ctrl)
+	implementation := transaction.NewMockImplementation(ctrl)
+	tx.EXPECT().Implementation().Return(implementation).AnyTimes()
+	api := &api{
+		tx:            tx,
+		configuration: &InstanceConfig{Log: log.Global()},
+	}
+	assert.Equal(1, api.Depth())
+
+	implementation.EXPECT().ExecContext(gomock.Any(), "SAVEPOINT sp_1").Return(nil, nil)
+	implementation.EXPECT().ExecContext(gomock.Any(), "SAVEPOINT sp_2").Return(nil, nil)
+	assert.NoError(api.Begin())
+	assert.NoError(api.Begin())
+	assert.Equal(3, api.Depth())
+
+	implementation.EXPECT().ExecContext(gomock.Any(), "ROLLBACK TO SAVEPOINT sp_2").Return(nil, nil)
+	assert.NoError(api.Rollback())
+	implementation.EXPECT().ExecContext(gomock.Any(), "RELEASE SAVEPOINT sp_1").Return(nil, nil)
+	assert.NoError(api.Commit())
+	assert.Equal(1, api.Depth())
+
+	tx.EXPECT().Commit().Return(nil)
+	assert.NoError(api.Commit())
+	assert.Equal(0, api.Depth())
+	assert.Equal(ErrMissingTransaction, api.Commit())
+}
+
+func Test_api_WithTransaction(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	tx := transaction.NewMockTransaction(ctrl)
+	implementation := transaction.NewMockImplementation(ctrl)
+	tx.EXPECT().Implementation().Return(implementation).AnyTimes()
+	database := &api{
+		tx:            tx,
+		configuration: &InstanceConfig{Log: log.Global()},
+	}
+	expected := generator.String(20)
+
+	implementation.EXPECT().ExecContext(gomock.Any(), "SAVEPOINT sp_1").Return(nil, nil)
+	implementation.EXPECT().ExecContext(gomock.Any(), "SAVEPOINT sp_2").Return(nil, nil)
+	implementation.EXPECT().ExecContext(gomock.Any(), "ROLLBACK TO SAVEPOINT sp_2").Return(nil, nil)
+	implementation.EXPECT().ExecContext(gomock.Any(), "RELEASE SAVEPOINT sp_1").Return(nil, nil)
+	err := database.WithTransaction(func(outer API) error {
+		assert.Equal(2, outer.Depth())
+		innerErr := outer.WithTransaction(func(inner API) error {
+			assert.Equal(3, inner.Depth())
+			return errors.New(expected)
+		})
+		assert.EqualError(innerErr, expected)
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal(1, database.Depth())
+}
+
+func Test_api_WithRetryingTransaction(t *testing.T) {
+	var tests = []struct {
+		name     string
+		err      error
+		attempts int
+	}{
+		{
+			name:     "deadlock",
+			err:      dberrors.NewDeadlockError(dberrors.Update, "", errors.New("deadlock")),
+			attempts: 3,
+		},
+		{
+			name:     "lock wait timeout",
+			err:      dberrors.NewLockWaitTimeoutError(dberrors.Update, "", errors.New("timeout")),
+			attempts: 3,
+		},
+		{
+			name:     "not retryable",
+			err:      errors.New("foo"),
+			attempts: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert1.New(t)
+			ctrl := gomock.NewController(t)
+			client := NewMockClient(ctrl)
+			database := &api{
+				db: &transactable{db: client},
+				configuration: &InstanceConfig{
+					Log:                          log.Global(),
+					TransactionRetryMinimumCycle: time.Millisecond,
+					TransactionRetryMaxCycle:     2 * time.Millisecond,
+				},
+			}
+			client.EXPECT().BeginTxx(gomock.Any(), nil).Return(nil, tc.err).Times(tc.attempts)
+			err := database.WithRetryingTransaction(func(API) error {
+				assert.Fail("unit of work should not be called without a transaction")
+				return nil
+			})
+			assert.EqualError(err, tc.err.Error())
+		})
+	}
+}
+
+func Test_api_SelectRows(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	tx := transaction.NewMockTransaction(ctrl)
+	database := &api{
+		tx:            tx,
+		configuration: &InstanceConfig{Log: log.Global()},
+	}
+	ctx := context.Background()
+	query := qb.Select(MetaTestRecord.AllColumns()).From(MetaTestRecord)
+	expected := errors.New("connection lost")
+	tx.EXPECT().SelectRowsContext(ctx, query, nil).Return(
+		func(yield func(*sqlx.Rows, error) bool) {
+			_ = yield(nil, nil) && yield(nil, nil) && yield(nil, expected)
+		})
+	var (
+		rows int
+		errs []error
+	)
+	for _, err := range database.SelectRowsContext(ctx, query, nil) {
+		if nil != err {
+			errs = append(errs, err)
+			continue
+		}
+		rows++
+	}
+	assert.Equal(2, rows)
+	assert.Equal([]error{expected}, errs)
+	// the enclosing transaction is not ended
+	assert.Equal(1, database.Depth())
+
+	// a transaction that can not be started is yielded as an error
+	client := NewMockClient(ctrl)
+	database = &api{
+		db:            &transactable{db: client},
+		configuration: &InstanceConfig{Log: log.Global()},
+	}
+	client.EXPECT().BeginTxx(ctx, nil).Return(nil, expected)
+	errs = nil
+	for _, err := range Stream[TestRecord](database, query, nil) {
+		errs = append(errs, err)
+	}
+	assert.Len(errs, 1)
+	assert.EqualError(errs[0], expected.Error())
+}
@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+var mapper = reflectx.NewMapperFunc("db", sqlx.NameMapper)
+
+// Change to the value of a single column, Before is null for a create and After
+// is null for a delete
+type Change struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// Diff the read columns of table between the before and after states of a
+// record, either of which may be nil. The changed columns are returned as a
+// JSON object of Change keyed by column name along with whether any changed.
+func Diff(table qb.Table, before, after any) (string, bool, error) {
+	var (
+		changes = make(map[string]Change)
+		err     error
+	)
+	for _, column := range table.ReadColumns() {
+		var change Change
+		if change.Before, err = columnJSON(before, column.Name); nil != err {
+			return "", false, err
+		}
+		if change.After, err = columnJSON(after, column.Name); nil != err {
+			return "", false, err
+		}
+		if !bytes.Equal(change.Before, change.After) {
+			changes[column.Name] = change
+		}
+	}
+	diff, err := json.Marshal(changes)
+	if nil != err {
+		return "", false, errors.Wrap(err)
+	}
+	return string(diff), len(changes) > 0, nil
+}
+
+func columnJSON(obj any, column string) (json.RawMessage, error) {
+	if nil == obj {
+		return json.RawMessage("null"), nil
+	}
+	value := reflect.Indirect(reflect.ValueOf(obj))
+	if value.Kind() != reflect.Struct {
+		return nil, errors.Newf("record must be a struct, got %T", obj)
+	}
+	info, ok := mapper.TypeMap(value.Type()).Names[column]
+	if !ok {
+		return nil, errors.Newf("%s has no field for column '%s'", value.Type(), column)
+	}
+	encoded, err := json.Marshal(reflectx.FieldByIndexesReadOnly(value, info.Index).Interface())
+	return encoded, errors.Wrap(err)
+}
@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+type diffRecord struct {
+	ID    string  `db:"id"`
+	Name  string  `db:"name"`
+	Score *int    `db:"score"`
+	Other float64 `db:"-"`
+}
+
+type diffMeta struct{}
+
+func (m *diffMeta) GetName() string                            { return "diff" }
+func (m *diffMeta) GetAlias() string                           { return "diff" }
+func (m *diffMeta) PrimaryKey() qb.TableField                  { return qb.TableField{Name: "id", Table: "diff"} }
+func (m *diffMeta) SortBy() (qb.TableField, qb.OrderDirection) { return m.PrimaryKey(), qb.Ascending }
+func (m *diffMeta) AllColumns() qb.TableField                  { return qb.TableField{Name: "*", Table: "diff"} }
+func (m *diffMeta) ReadColumns() []qb.TableField {
+	return []qb.TableField{m.PrimaryKey(), {Name: "name", Table: "diff"}, {Name: "score", Table: "diff"}}
+}
+func (m *diffMeta) WriteColumns() []qb.TableField { return m.ReadColumns() }
+
+func TestDiff(t *testing.T) {
+	score := 10
+	tests := []struct {
+		name     string
+		before   any
+		after    any
+		expected string
+		changed  bool
+	}{
+		{
+			name:     "create",
+			after:    &diffRecord{ID: "a", Name: "first"},
+			expected: `{"id":{"before":null,"after":"a"},"name":{"before":null,"after":"first"}}`,
+			changed:  true,
+		},
+		{
+			name:     "update",
+			before:   &diffRecord{ID: "a", Name: "first"},
+			after:    &diffRecord{ID: "a", Name: "second", Score: &score, Other: 1},
+			expected: `{"name":{"before":"first","after":"second"},"score":{"before":null,"after":10}}`,
+			changed:  true,
+		},
+		{
+			name:     "unchanged",
+			before:   diffRecord{ID: "a", Name: "first"},
+			after:    &diffRecord{ID: "a", Name: "first", Other: 1},
+			expected: `{}`,
+		},
+		{
+			name:     "delete",
+			before:   &diffRecord{ID: "a", Name: "first", Score: &score},
+			expected: `{"id":{"before":"a","after":null},"name":{"before":"first","after":null},"score":{"before":10,"after":null}}`,
+			changed:  true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert1.New(t)
+			actual, changed, err := Diff(&diffMeta{}, tc.before, tc.after)
+			assert.NoError(err)
+			assert.Equal(tc.changed, changed)
+			assert.JSONEq(tc.expected, actual)
+		})
+	}
+}
+
+func TestDiff_Errors(t *testing.T) {
+	assert := assert1.New(t)
+	_, _, err := Diff(&diffMeta{}, "a", nil)
+	assert.Error(err)
+	_, _, err = Diff(&diffMeta{}, nil, &struct {
+		ID string `db:"id"`
+	}{ID: "a"})
+	assert.EqualError(err, "struct { ID string \"db:\\\"id\\\"\" } has no field for column 'name'")
+}
@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/database/record"
+	"github.com/beaconsoftwarellc/gadget/v2/generator"
+)
+
+// Operation that changed a record
+type Operation string
+
+const (
+	// Create indicates the record was inserted
+	Create Operation = "CREATE"
+	// Upsert indicates the record was inserted or replaced
+	Upsert Operation = "UPSERT"
+	// Update indicates the record was updated
+	Update Operation = "UPDATE"
+	// Delete indicates the record was deleted
+	Delete Operation = "DELETE"
+
+	entryIDPrefix = "AUD"
+)
+
+// MySQLTable is the definition of the table entries are written to, it should be
+// created by a delta before auditing is enabled
+const MySQLTable = "CREATE TABLE IF NOT EXISTS `audit_entry` (\n" +
+	"  `id` varchar(32) NOT NULL,\n" +
+	"  `table_name` varchar(64) NOT NULL,\n" +
+	"  `record_id` varchar(64) NOT NULL,\n" +
+	"  `operation` varchar(16) NOT NULL,\n" +
+	"  `actor` varchar(128) NOT NULL,\n" +
+	"  `created` datetime(6) NOT NULL,\n" +
+	"  `changes` json NOT NULL,\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  KEY `audit_entry_record` (`table_name`, `record_id`, `created`)\n" +
+	")"
+
+// Entry is a change made to a single record
+type Entry struct {
+	ID string `db:"id"`
+	// TableName of the record that was changed
+	TableName string `db:"table_name"`
+	// RecordID is the primary key of the record that was changed
+	RecordID string `db:"record_id"`
+	// Operation that changed the record
+	Operation Operation `db:"operation"`
+	// Actor that made the change, see WithActor
+	Actor string `db:"actor"`
+	// Created is when the change was made
+	Created time.Time `db:"created"`
+	// Changes is a JSON object of the changed columns, see Diff
+	Changes string `db:"changes"`
+}
+
+// NewEntry for a change to the record with the passed primary key in table
+func NewEntry(table qb.Table, pk record.PrimaryKeyValue, operation Operation,
+	actor, changes string) *Entry {
+	return &Entry{
+		ID:        generator.ID(entryIDPrefix),
+		TableName: table.GetName(),
+		RecordID:  fmt.Sprint(pk.Value()),
+		Operation: operation,
+		Actor:     actor,
+		Created:   time.Now().UTC(),
+		Changes:   changes,
+	}
+}
+
+// Initialize the entry with an id
+func (e *Entry) Initialize() {
+	e.ID = generator.ID(entryIDPrefix)
+}
+
+// PrimaryKey of this record
+func (e *Entry) PrimaryKey() record.PrimaryKeyValue {
+	return record.NewPrimaryKey(e.ID)
+}
+
+// Key field name
+func (e *Entry) Key() string {
+	return "id"
+}
+
+// Meta object for this record
+func (e *Entry) Meta() qb.Table {
+	return EntryMeta
+}
+
+// HistoryQuery selects the entries for the record with the passed primary key
+// in table, oldest first
+func HistoryQuery(table qb.Table, pk record.PrimaryKeyValue) *qb.SelectQuery {
+	return qb.Select(EntryMeta.AllColumns()).
+		From(EntryMeta).
+		Where(EntryMeta.TableName.Equal(table.GetName()).
+			And(EntryMeta.RecordID.Equal(fmt.Sprint(pk.Value())))).
+		OrderBy(EntryMeta.Created, qb.Ascending).
+		OrderBy(EntryMeta.ID, qb.Ascending)
+}
+
+type actorKey struct{}
+
+// WithActor returns a context under which changes are attributed to the passed
+// actor rather than the session of the transaction logger
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// GetActor from the passed context
+func GetActor(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok
+}
@@ -0,0 +1,41 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/record"
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+func TestNewEntry(t *testing.T) {
+	assert := assert1.New(t)
+	entry := NewEntry(&diffMeta{}, record.NewPrimaryKey("a"), Update, "tester", "{}")
+	assert.NotEmpty(entry.ID)
+	assert.Equal("diff", entry.TableName)
+	assert.Equal("a", entry.RecordID)
+	assert.Equal(Update, entry.Operation)
+	assert.Equal("tester", entry.Actor)
+	assert.False(entry.Created.IsZero())
+	assert.Equal("{}", entry.Changes)
+	assert.Equal(EntryMeta, entry.Meta())
+}
+
+func TestHistoryQuery(t *testing.T) {
+	assert := assert1.New(t)
+	stmt, values, err := HistoryQuery(&diffMeta{}, record.NewPrimaryKey("a")).SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `audit_entry`.* FROM `audit_entry` AS `audit_entry` WHERE "+
+		"(`audit_entry`.`table_name` = ? AND `audit_entry`.`record_id` = ?) ORDER BY "+
+		"`audit_entry`.`created` ASC, `audit_entry`.`id` ASC", stmt)
+	assert.Equal([]any{"diff", "a"}, values)
+}
+
+func TestActor(t *testing.T) {
+	assert := assert1.New(t)
+	_, ok := GetActor(context.Background())
+	assert.False(ok)
+	actor, ok := GetActor(WithActor(context.Background(), "tester"))
+	assert.True(ok)
+	assert.Equal("tester", actor)
+}
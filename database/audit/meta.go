@@ -0,0 +1,70 @@
+package audit
+
+import "github.com/beaconsoftwarellc/gadget/v2/database/qb"
+
+type entryMeta struct {
+	alias     string
+	ID        qb.TableField
+	TableName qb.TableField
+	RecordID  qb.TableField
+	Operation qb.TableField
+	Actor     qb.TableField
+	Created   qb.TableField
+	Changes   qb.TableField
+
+	allColumns qb.TableField
+}
+
+func (p *entryMeta) AllColumns() qb.TableField {
+	return p.allColumns
+}
+
+func (p *entryMeta) GetName() string {
+	return "audit_entry"
+}
+
+func (p *entryMeta) GetAlias() string {
+	return p.alias
+}
+
+func (p *entryMeta) PrimaryKey() qb.TableField {
+	return p.ID
+}
+
+func (p *entryMeta) SortBy() (qb.TableField, qb.OrderDirection) {
+	return p.Created, qb.Ascending
+}
+
+func (p *entryMeta) ReadColumns() []qb.TableField {
+	return []qb.TableField{
+		p.ID,
+		p.TableName,
+		p.RecordID,
+		p.Operation,
+		p.Actor,
+		p.Created,
+		p.Changes,
+	}
+}
+
+func (p *entryMeta) WriteColumns() []qb.TableField {
+	return p.ReadColumns()
+}
+
+func (p *entryMeta) Alias(alias string) *entryMeta {
+	return &entryMeta{
+		alias:     alias,
+		ID:        qb.TableField{Name: "id", Table: alias},
+		TableName: qb.TableField{Name: "table_name", Table: alias},
+		RecordID:  qb.TableField{Name: "record_id", Table: alias},
+		Operation: qb.TableField{Name: "operation", Table: alias},
+		Actor:     qb.TableField{Name: "actor", Table: alias},
+		Created:   qb.TableField{Name: "created", Table: alias},
+		Changes:   qb.TableField{Name: "changes", Table: alias},
+
+		allColumns: qb.TableField{Name: "*", Table: alias},
+	}
+}
+
+// EntryMeta is a meta representation of the audit entry table for building ad hoc queries
+var EntryMeta = (&entryMeta{}).Alias("audit_entry")
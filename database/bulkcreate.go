@@ -2,6 +2,7 @@ package database
 
 //go:generate mockgen -source=$GOFILE -package mocks -destination mocks/bulkcreate.mock.gen.go
 import (
+	"context"
 	"database/sql"
 
 	dberrors "github.com/beaconsoftwarellc/gadget/v2/database/errors"
@@ -12,12 +13,17 @@ import (
 )
 
 // BulkCreate allows for bulk creation of a single resource within a
-// transaction.
+// transaction. Pending records are sent in chunks of the configured number of
+// rows or estimated size as they are created.
 type BulkCreate[T record.Record] interface {
 	CommitRollbackReset
 	// Create initializes the passed Records and buffers them pending
-	// the call to commit
+	// the call to commit. An error sending a chunk is returned by commit.
 	Create(objs ...T)
+	// CreateContext initializes the passed Records and buffers them pending the
+	// call to commit, an error sending a chunk rolls back the transaction and
+	// is returned
+	CreateContext(ctx context.Context, objs ...T) errors.TracerError
 }
 
 type bulkCreate[T record.Record] struct {
@@ -26,46 +32,47 @@ type bulkCreate[T record.Record] struct {
 }
 
 func (api *bulkCreate[T]) Create(objs ...T) {
+	_ = api.CreateContext(context.Background(), objs...)
+}
+
+func (api *bulkCreate[T]) CreateContext(ctx context.Context, objs ...T) errors.TracerError {
+	if len(objs) == 0 {
+		return nil
+	}
 	for _, obj := range objs {
 		obj.Initialize()
-		api.pending = append(api.pending, obj)
 	}
+	columns := api.columns(objs[0])
+	return api.add(ctx, api.insert, columns, len(columns), objs)
 }
 
 func (api *bulkCreate[T]) Commit() (sql.Result, errors.TracerError) {
-	if nil == api.tx {
-		return nil, errors.New("commit called on nil transaction")
-	}
-	defer func() {
-		api.pending = make([]T, 0)
-		api.tx = nil
-	}()
-	if len(api.pending) == 0 {
-		return nil, api.tx.Commit()
-	}
-	var (
-		result    sql.Result
-		stmt      string
-		err       error
-		log       = api.configuration.Logger()
-		writeCols = utility.AppendIfMissing(
-			api.pending[0].Meta().WriteColumns(),
-			api.pending[0].Meta().PrimaryKey(),
-		)
-		query = qb.Insert(writeCols...)
-	)
+	return api.CommitContext(context.Background())
+}
+
+func (api *bulkCreate[T]) CommitContext(ctx context.Context) (sql.Result, errors.TracerError) {
+	return api.commit(ctx, api.insert)
+}
+
+// columns written for obj
+func (api *bulkCreate[T]) columns(obj T) []qb.TableField {
+	return utility.AppendIfMissing(obj.Meta().WriteColumns(), obj.Meta().PrimaryKey())
+}
+
+// insert the chunk in a single statement
+func (api *bulkCreate[T]) insert(ctx context.Context, chunk []T) (sql.Result, errors.TracerError) {
+	query := qb.Insert(api.columns(chunk[0])...).WithDialect(api.dialect())
 	if api.upsert {
-		query.OnDuplicate(api.pending[0].Meta().WriteColumns())
+		query.OnDuplicate(chunk[0].Meta().WriteColumns()).
+			ConflictOn(chunk[0].Meta().PrimaryKey())
 	}
-	stmt, err = query.ParameterizedSQL()
+	stmt, err := query.ParameterizedSQL()
 	if nil != err {
-		_ = log.Error(api.tx.Rollback())
 		return nil, errors.Wrap(err)
 	}
-	result, err = api.tx.Implementation().NamedExec(stmt, api.pending)
+	result, err := api.tx.Implementation().NamedExecContext(ctx, stmt, chunk)
 	if nil != err {
-		_ = log.Error(api.tx.Rollback())
-		return nil, dberrors.TranslateError(err, dberrors.Insert, stmt)
+		return nil, dberrors.TranslateErrorFor(api.dialect(), err, dberrors.Insert, stmt)
 	}
-	return result, api.tx.Commit()
+	return result, nil
 }
@@ -1,8 +1,12 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"testing"
+	"time"
 
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
 	"github.com/beaconsoftwarellc/gadget/v2/database/transaction"
 	"github.com/beaconsoftwarellc/gadget/v2/errors"
 	"github.com/beaconsoftwarellc/gadget/v2/generator"
@@ -45,7 +49,7 @@ func TestBulkCreateReset(t *testing.T) {
 	bulkCreate.tx = nil
 
 	tx := &sqlx.Tx{}
-	client.EXPECT().Beginx().Return(tx, nil)
+	client.EXPECT().BeginTxx(gomock.Any(), nil).Return(tx, nil)
 	actual = bulkCreate.Reset()
 	assert.NoError(actual)
 	assert.Empty(bulkCreate.pending)
@@ -111,14 +115,46 @@ func TestBulkCreateCommit(t *testing.T) {
 	testRecord := &TestRecord{Name: generator.String(32)}
 	testRecord1 := &TestRecord{Name: generator.String(32)}
 	bulkCreate.Create(testRecord, testRecord1)
-	implementation.EXPECT().NamedExec("INSERT INTO `test_record` "+
+	implementation.EXPECT().NamedExecContext(gomock.Any(), "INSERT INTO `test_record` "+
 		"(`test_record`.`id`, `test_record`.`name`) VALUES (:id, :name)",
-		bulkCreate.pending).Return(nil, nil)
+		bulkCreate.pending).Return(&sqlResult{}, nil)
 	transaction.EXPECT().Commit().Return(nil)
 	_, actualErr := bulkCreate.Commit()
 	assert.NoError(actualErr)
 }
 
+func TestBulkCreateCommit_Replicas(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+
+	configuration := &InstanceConfig{
+		Log: log.Global(),
+	}
+	implementation := transaction.NewMockImplementation(ctrl)
+	transaction := transaction.NewMockTransaction(ctrl)
+	transaction.EXPECT().Implementation().Return(implementation).AnyTimes()
+	client := NewMockClient(ctrl)
+	replicas, _ := newTestReplicaSet(t, RoundRobin, 1)
+
+	bulkCreate := &bulkCreate[*TestRecord]{
+		bulkOperation: &bulkOperation[*TestRecord]{
+			tx:            transaction,
+			db:            &transactable{db: client},
+			replicas:      replicas,
+			configuration: configuration,
+		},
+	}
+	bulkCreate.Create(&TestRecord{Name: generator.String(32)})
+	implementation.EXPECT().NamedExecContext(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&sqlResult{}, nil)
+	transaction.EXPECT().Commit().Return(nil)
+	assert.Zero(replicas.committed.Load())
+	_, actualErr := bulkCreate.Commit()
+	assert.NoError(actualErr)
+	// reads are pinned to the primary after the bulk commit
+	assert.True(replicas.pinned(time.Minute))
+}
+
 func TestBulkCreateCommitUpsert(t *testing.T) {
 	assert := assert1.New(t)
 	ctrl := gomock.NewController(t)
@@ -143,11 +179,11 @@ func TestBulkCreateCommitUpsert(t *testing.T) {
 	testRecord := &TestRecord{Name: generator.String(32)}
 	testRecord1 := &TestRecord{Name: generator.String(32)}
 	bulkCreate.Create(testRecord, testRecord1)
-	implementation.EXPECT().NamedExec("INSERT INTO `test_record` "+
+	implementation.EXPECT().NamedExecContext(gomock.Any(), "INSERT INTO `test_record` "+
 		"(`test_record`.`id`, `test_record`.`name`) VALUES (:id, :name) "+
 		"ON DUPLICATE KEY UPDATE `test_record`.`id` = VALUES(`test_record`.`id`), "+
 		"`test_record`.`name` = VALUES(`test_record`.`name`)",
-		bulkCreate.pending).Return(nil, nil)
+		bulkCreate.pending).Return(&sqlResult{}, nil)
 	transaction.EXPECT().Commit().Return(nil)
 	_, actualErr := bulkCreate.Commit()
 	assert.NoError(actualErr)
@@ -181,3 +217,161 @@ func TestBulkCreateRollback(t *testing.T) {
 	assert.Nil(bulkCreate.tx)
 	assert.Empty(bulkCreate.pending)
 }
+
+type chunkResult struct {
+	id   int64
+	rows int64
+}
+
+func (r *chunkResult) LastInsertId() (int64, error) {
+	return r.id, nil
+}
+
+func (r *chunkResult) RowsAffected() (int64, error) {
+	return r.rows, nil
+}
+
+func TestBulkCreateChunks(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+
+	implementation := transaction.NewMockImplementation(ctrl)
+	tx := transaction.NewMockTransaction(ctrl)
+	tx.EXPECT().Implementation().Return(implementation).AnyTimes()
+	bulkCreate := &bulkCreate[*TestRecord]{
+		bulkOperation: &bulkOperation[*TestRecord]{
+			tx:            tx,
+			db:            &transactable{db: NewMockClient(ctrl)},
+			configuration: &InstanceConfig{Log: log.Global(), BulkRows: 2},
+		},
+	}
+	var progress []BulkProgress
+	bulkCreate.OnProgress(func(p BulkProgress) { progress = append(progress, p) })
+	records := make([]*TestRecord, 5)
+	for i := range records {
+		records[i] = &TestRecord{Name: generator.String(32)}
+	}
+	stmt := "INSERT INTO `test_record` (`test_record`.`id`, `test_record`.`name`) VALUES (:id, :name)"
+
+	// full chunks are sent as records are created
+	gomock.InOrder(
+		implementation.EXPECT().NamedExecContext(gomock.Any(), stmt, records[0:2]).
+			Return(&chunkResult{id: 1, rows: 2}, nil),
+		implementation.EXPECT().NamedExecContext(gomock.Any(), stmt, records[2:4]).
+			Return(&chunkResult{id: 3, rows: 2}, nil),
+	)
+	assert.NoError(bulkCreate.CreateContext(context.Background(), records[0:3]...))
+	bulkCreate.Create(records[3:]...)
+	assert.Equal(records[4:], bulkCreate.pending)
+	assert.Equal([]BulkProgress{{Chunks: 1, Rows: 2, RowsAffected: 2},
+		{Chunks: 2, Rows: 4, RowsAffected: 4}}, progress)
+
+	// the remainder is sent on commit
+	implementation.EXPECT().NamedExecContext(gomock.Any(), stmt, records[4:]).
+		Return(&chunkResult{id: 5, rows: 1}, nil)
+	tx.EXPECT().Commit().Return(nil)
+	result, err := bulkCreate.Commit()
+	assert.NoError(err)
+	assert.Equal(BulkProgress{Chunks: 3, Rows: 5, RowsAffected: 5}, progress[2])
+	rows, _ := result.RowsAffected()
+	assert.Equal(int64(5), rows)
+	id, _ := result.LastInsertId()
+	assert.Equal(int64(5), id)
+	assert.Equal([]int64{1, 3, 5}, result.(BulkResult).InsertIDs())
+	assert.Nil(bulkCreate.tx)
+}
+
+func TestBulkCreateChunks_Limits(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+
+	implementation := transaction.NewMockImplementation(ctrl)
+	tx := transaction.NewMockTransaction(ctrl)
+	tx.EXPECT().Implementation().Return(implementation).AnyTimes()
+	configuration := &InstanceConfig{Log: log.Global(), BulkRows: maxPlaceholders, BulkBytes: 1 << 30}
+	bulkCreate := &bulkCreate[*TestRecord]{
+		bulkOperation: &bulkOperation[*TestRecord]{
+			tx:            tx,
+			db:            &transactable{db: NewMockClient(ctrl)},
+			configuration: configuration,
+		},
+	}
+
+	// each record uses 2 placeholders
+	records := make([]*TestRecord, maxPlaceholders/2+1)
+	for i := range records {
+		records[i] = &TestRecord{}
+	}
+	implementation.EXPECT().NamedExecContext(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, chunk []*TestRecord) (sql.Result, error) {
+			assert.Len(chunk, maxPlaceholders/2)
+			return &sqlResult{}, nil
+		})
+	assert.NoError(bulkCreate.CreateContext(context.Background(), records...))
+	assert.Len(bulkCreate.pending, 1)
+
+	// the estimated size is reached by a single record
+	configuration.BulkBytes = 1
+	implementation.EXPECT().NamedExecContext(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, chunk []*TestRecord) (sql.Result, error) {
+			assert.Len(chunk, 2)
+			return &sqlResult{}, nil
+		})
+	assert.NoError(bulkCreate.CreateContext(context.Background(), &TestRecord{}))
+	assert.Empty(bulkCreate.pending)
+}
+
+func TestBulkCreateChunks_Error(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+
+	implementation := transaction.NewMockImplementation(ctrl)
+	tx := transaction.NewMockTransaction(ctrl)
+	tx.EXPECT().Implementation().Return(implementation).AnyTimes()
+	bulkCreate := &bulkCreate[*TestRecord]{
+		bulkOperation: &bulkOperation[*TestRecord]{
+			tx:            tx,
+			db:            &transactable{db: NewMockClient(ctrl)},
+			configuration: &InstanceConfig{Log: log.Global(), BulkRows: 1},
+		},
+	}
+	expected := generator.String(32)
+	implementation.EXPECT().NamedExecContext(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, errors.New(expected))
+	tx.EXPECT().Rollback().Return(nil)
+
+	// the transaction is rolled back and later records are not sent
+	bulkCreate.Create(&TestRecord{}, &TestRecord{})
+	err := bulkCreate.CreateContext(context.Background(), &TestRecord{})
+	assert.ErrorContains(err, expected)
+	assert.Nil(bulkCreate.tx)
+	assert.Empty(bulkCreate.pending)
+	_, actual := bulkCreate.Commit()
+	assert.Equal(err, actual)
+	_, err = bulkCreate.Commit()
+	assert.EqualError(err, "commit called on nil transaction")
+
+	bulkCreate.Create(&TestRecord{})
+	bulkCreate.err = errors.New(expected)
+	assert.NoError(bulkCreate.Rollback())
+	assert.Nil(bulkCreate.err)
+}
+
+func TestEstimateSize(t *testing.T) {
+	assert := assert1.New(t)
+	name := "abcd"
+	type sized struct {
+		ID       string
+		Name     *string
+		Missing  *string
+		Data     []byte
+		Count    int
+		Nullable sql.NullString
+	}
+	obj := &sized{ID: "ab", Name: &name, Data: []byte{1, 2, 3}, Count: 1000,
+		Nullable: sql.NullString{String: "xyz", Valid: true}}
+	columns := []qb.TableField{{Name: "id"}, {Name: "name"}, {Name: "missing"}, {Name: "data"},
+		{Name: "count"}, {Name: "nullable"}, {Name: "unknown"}}
+	assert.Equal(6*valueOverhead+2+4+3+3, estimateSize(obj, columns))
+	assert.Equal(0, estimateSize("string", columns))
+}
@@ -1,11 +1,24 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"reflect"
 
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
 	"github.com/beaconsoftwarellc/gadget/v2/database/record"
 	"github.com/beaconsoftwarellc/gadget/v2/database/transaction"
 	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+const (
+	// maxPlaceholders in a single statement for both MySQL and PostgreSQL
+	maxPlaceholders = 65535
+	// valueOverhead added to the estimated size of each value sent for its
+	// encoding, this is also the size of values that are not strings or bytes
+	valueOverhead = 8
 )
 
 // CommitRollbackReset is the base interface for Bulk operation
@@ -13,41 +26,234 @@ import (
 type CommitRollbackReset interface {
 	// Reset the pending records and transaction on this instance
 	Reset() errors.TracerError
-	// Commit the bulk operation to the database
+	// ResetContext the pending records and begin a new transaction on this
+	// instance that is rolled back if the context is done before it is committed
+	ResetContext(ctx context.Context) errors.TracerError
+	// Commit the bulk operation to the database, the returned result is a
+	// BulkResult aggregated over every chunk sent in the transaction
 	Commit() (sql.Result, errors.TracerError)
+	// CommitContext the bulk operation to the database
+	CommitContext(ctx context.Context) (sql.Result, errors.TracerError)
 	// Rollback the bulk operation and close the transaction
 	Rollback() errors.TracerError
+	// OnProgress calls fn after each chunk of pending records is sent, nil
+	// stops reporting progress
+	OnProgress(fn func(BulkProgress))
+}
+
+// BulkProgress of a bulk operation within its transaction
+type BulkProgress struct {
+	// Chunks sent to the database
+	Chunks int
+	// Rows sent to the database
+	Rows int
+	// RowsAffected by the chunks sent
+	RowsAffected int64
 }
 
+// sendFunc sends a chunk of the pending records to the transaction
+type sendFunc[T record.Record] func(ctx context.Context, chunk []T) (sql.Result, errors.TracerError)
+
+// bulkOperation buffers records and sends them in chunks once the configured
+// number of rows or estimated size is reached, all in the same transaction
 type bulkOperation[T record.Record] struct {
 	tx            transaction.Transaction
 	pending       []T
 	db            *transactable
+	replicas      *replicaSet
 	configuration Configuration
+	// pendingBytes is the estimated size of the values of the pending records
+	pendingBytes int
+	result       *result
+	progress     BulkProgress
+	onProgress   func(BulkProgress)
+	// err that rolled back the transaction while sending a chunk, it is
+	// returned by Commit
+	err errors.TracerError
 }
 
 func (bop *bulkOperation[T]) Reset() errors.TracerError {
+	return bop.ResetContext(context.Background())
+}
+
+func (bop *bulkOperation[T]) ResetContext(ctx context.Context) errors.TracerError {
 	if nil != bop.tx {
 		return errors.New("transaction should be committed or rolled " +
 			"back prior to calling Reset")
 	}
 	var err error
-	bop.pending = make([]T, 0)
-	bop.tx, err = transaction.New(bop.db,
+	bop.clear()
+	bop.err = nil
+	bop.result = &result{}
+	bop.progress = BulkProgress{}
+	bop.tx, err = transaction.NewContext(ctx, bop.db,
+		bop.dialect(),
 		bop.configuration.Logger(),
 		bop.configuration.SlowQueryThreshold(),
 		bop.configuration.LoggedSlowQueries(),
+		queryInterceptors(bop.configuration, bop.db)...,
 	)
 	return errors.Wrap(err)
 }
 
 func (bop *bulkOperation[T]) Rollback() errors.TracerError {
+	if nil != bop.err {
+		// the transaction was rolled back when the error occurred
+		bop.err = nil
+		return nil
+	}
 	if nil == bop.tx {
 		return errors.New("rollback called on nil transaction")
 	}
 	defer func() {
-		bop.pending = make([]T, 0)
+		bop.clear()
 		bop.tx = nil
 	}()
 	return bop.tx.Rollback()
 }
+
+func (bop *bulkOperation[T]) OnProgress(fn func(BulkProgress)) {
+	bop.onProgress = fn
+}
+
+func (bop *bulkOperation[T]) dialect() qb.Dialect {
+	return qb.DialectFor(bop.configuration.DatabaseDialect())
+}
+
+// clear the pending records
+func (bop *bulkOperation[T]) clear() {
+	bop.pending = make([]T, 0)
+	bop.pendingBytes = 0
+}
+
+// add objs to the pending records, sending them with send whenever they reach
+// the configured number of rows or estimated size. The estimated size is of
+// the passed columns and placeholders is the number each record uses in a
+// statement, 0 when each record is sent in its own statement.
+func (bop *bulkOperation[T]) add(ctx context.Context, send sendFunc[T], columns []qb.TableField,
+	placeholders int, objs []T) errors.TracerError {
+	if nil != bop.err {
+		return bop.err
+	}
+	rows := bop.configuration.BulkChunkRows()
+	if placeholders > 0 {
+		rows = max(1, min(rows, maxPlaceholders/placeholders))
+	}
+	for _, obj := range objs {
+		bop.pending = append(bop.pending, obj)
+		bop.pendingBytes += estimateSize(obj, columns)
+		if len(bop.pending) >= rows || bop.pendingBytes >= bop.configuration.BulkChunkBytes() {
+			if err := bop.send(ctx, send); nil != err {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// send the pending records as a chunk, the transaction is rolled back on
+// failure and the error is kept for Commit
+func (bop *bulkOperation[T]) send(ctx context.Context, send sendFunc[T]) errors.TracerError {
+	if len(bop.pending) == 0 {
+		return nil
+	}
+	if nil == bop.tx {
+		return errors.New("send called on nil transaction")
+	}
+	if nil == bop.result {
+		bop.result = &result{}
+	}
+	sqlResult, err := send(ctx, bop.pending)
+	if nil == err {
+		err = errors.Wrap(bop.result.ConsumeChunk(sqlResult))
+	}
+	if nil != err {
+		_ = bop.configuration.Logger().Error(bop.tx.Rollback())
+		bop.clear()
+		bop.tx = nil
+		bop.err = err
+		return err
+	}
+	bop.progress.Chunks++
+	bop.progress.Rows += len(bop.pending)
+	bop.progress.RowsAffected = bop.result.rowsAffected
+	bop.clear()
+	if nil != bop.onProgress {
+		bop.onProgress(bop.progress)
+	}
+	return nil
+}
+
+// commit sends the remaining pending records with send and commits the
+// transaction
+func (bop *bulkOperation[T]) commit(ctx context.Context, send sendFunc[T]) (sql.Result, errors.TracerError) {
+	if nil != bop.err {
+		err := bop.err
+		bop.err = nil
+		return nil, err
+	}
+	if nil == bop.tx {
+		return nil, errors.New("commit called on nil transaction")
+	}
+	if err := bop.send(ctx, send); nil != err {
+		bop.err = nil
+		return nil, err
+	}
+	defer func() {
+		bop.clear()
+		bop.tx = nil
+	}()
+	if err := bop.tx.Commit(); nil != err {
+		return nil, err
+	}
+	if nil != bop.replicas {
+		bop.replicas.commit()
+	}
+	if nil == bop.result {
+		bop.result = &result{}
+	}
+	return bop.result, nil
+}
+
+// estimateSize of the values of the passed columns on obj when they are sent
+// in a statement
+func estimateSize(obj any, columns []qb.TableField) int {
+	value := reflect.Indirect(reflect.ValueOf(obj))
+	if value.Kind() != reflect.Struct {
+		return 0
+	}
+	names := mapper.TypeMap(value.Type()).Names
+	size := 0
+	for _, column := range columns {
+		info, ok := names[column.Name]
+		if !ok {
+			continue
+		}
+		size += valueOverhead + valueSize(reflectx.FieldByIndexesReadOnly(value, info.Index))
+	}
+	return size
+}
+
+// valueSize of strings and bytes, other values are counted as overhead only
+func valueSize(value reflect.Value) int {
+	if value.Kind() == reflect.Pointer && value.IsNil() {
+		return 0
+	}
+	v := value.Interface()
+	if valuer, ok := v.(driver.Valuer); ok {
+		var err error
+		if v, err = valuer.Value(); nil != err {
+			return 0
+		}
+	} else {
+		v = reflect.Indirect(value).Interface()
+	}
+	switch typed := v.(type) {
+	case string:
+		return len(typed)
+	case []byte:
+		return len(typed)
+	default:
+		return 0
+	}
+}
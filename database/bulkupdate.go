@@ -2,22 +2,32 @@ package database
 
 //go:generate mockgen -source=$GOFILE -package mocks -destination mocks/bulkupdate.mock.gen.go
 import (
+	"context"
 	"database/sql"
 
 	dberrors "github.com/beaconsoftwarellc/gadget/v2/database/errors"
 	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
 	"github.com/beaconsoftwarellc/gadget/v2/database/record"
 	"github.com/beaconsoftwarellc/gadget/v2/database/transaction"
+	"github.com/beaconsoftwarellc/gadget/v2/database/utility"
 	"github.com/beaconsoftwarellc/gadget/v2/errors"
 )
 
 // BulkUpdate allows for bulk updating of a single resource within a
-// transaction.
+// transaction. Pending records are sent in chunks of the configured number of
+// rows or estimated size as they are updated.
 type BulkUpdate[T record.Record] interface {
 	CommitRollbackReset
 	// Update buffers the update statements until commit is
-	// called on this instance.
+	// called on this instance. Records implementing record.Versioned are only
+	// updated when their version is current, otherwise commit rolls back and
+	// returns a dberrors.StaleRecordError. The versions on the passed records
+	// are not refreshed. An error sending a chunk is returned by commit.
 	Update(objs ...T)
+	// UpdateContext buffers the update statements until commit is called on
+	// this instance, an error sending a chunk rolls back the transaction and is
+	// returned
+	UpdateContext(ctx context.Context, objs ...T) errors.TracerError
 }
 
 type bulkUpdate[T record.Record] struct {
@@ -26,64 +36,81 @@ type bulkUpdate[T record.Record] struct {
 }
 
 func (api *bulkUpdate[T]) Update(objs ...T) {
-	api.pending = append(api.pending, objs...)
+	_ = api.UpdateContext(context.Background(), objs...)
 }
 
-func (api *bulkUpdate[T]) Commit() (sql.Result, errors.TracerError) {
-	if nil == api.tx {
-		return nil, errors.New("commit called on nil transaction")
-	}
-	defer func() {
-		api.pending = make([]T, 0)
-		api.tx = nil
-	}()
-	if len(api.pending) == 0 {
-		return nil, api.tx.Commit()
+func (api *bulkUpdate[T]) UpdateContext(ctx context.Context, objs ...T) errors.TracerError {
+	if len(objs) == 0 {
+		return nil
 	}
+	// each record is updated by its own statement so placeholders do not
+	// limit the chunk
+	columns := utility.AppendIfMissing(api.columns, objs[0].Meta().PrimaryKey())
+	return api.add(ctx, api.update, columns, 0, objs)
+}
+
+func (api *bulkUpdate[T]) Commit() (sql.Result, errors.TracerError) {
+	return api.CommitContext(context.Background())
+}
+
+func (api *bulkUpdate[T]) CommitContext(ctx context.Context) (sql.Result, errors.TracerError) {
+	return api.commit(ctx, api.update)
+}
+
+// update each record of the chunk with a prepared statement
+func (api *bulkUpdate[T]) update(ctx context.Context, chunk []T) (sql.Result, errors.TracerError) {
 	var (
 		log = api.configuration.Logger()
 		// grab a single instance to create the parameterized sql
-		obj            = api.pending[0]
-		query          = qb.Update(obj.Meta())
+		obj            = chunk[0]
+		query          = qb.Update(obj.Meta()).WithDialect(api.dialect())
 		namedStatement transaction.NamedStatement
-		tracerErr      errors.TracerError
 		err            error
 		result         = &result{}
 	)
 	// values are inconsequential because we are using named
 	// and not order based
+	version, versioned := record.VersionColumn(obj)
 	for _, column := range api.columns {
-		query.SetParam(column)
+		if !versioned || column != version {
+			query.SetParam(column)
+		}
 	}
-	query.Where(obj.Meta().PrimaryKey().Equal(":" + obj.Meta().PrimaryKey().Name))
+	where := obj.Meta().PrimaryKey().Equal(":" + obj.Meta().PrimaryKey().Name)
+	if versioned {
+		query.Increment(version)
+		where.And(version.Equal(":" + version.GetName()))
+	}
+	query.Where(where)
 	sql, err := query.ParameterizedSQL(qb.NoLimit)
 	if nil != err {
-		_ = log.Error(api.tx.Rollback())
 		return nil, errors.Wrap(err)
 	}
-	namedStatement, err = api.tx.PrepareNamed(sql)
+	namedStatement, err = api.tx.PrepareNamedContext(ctx, sql)
 	if nil != err {
-		_ = log.Error(api.tx.Rollback())
 		return nil, errors.Wrap(err)
 	}
 	defer func() {
 		_ = log.Error(namedStatement.Close())
 	}()
-	for _, obj := range api.pending {
-		sqlResult, err := namedStatement.Exec(obj)
+	for _, obj := range chunk {
+		sqlResult, err := namedStatement.ExecContext(ctx, obj)
 		if nil != err {
-			_ = log.Error(api.tx.Rollback())
-			return nil, dberrors.TranslateError(err, dberrors.Update, sql)
+			return nil, dberrors.TranslateErrorFor(api.dialect(), err, dberrors.Update, sql)
+		}
+		if versioned {
+			rowsAffected, err := sqlResult.RowsAffected()
+			if nil == err && rowsAffected == 0 {
+				err = dberrors.NewStaleRecordError(dberrors.Update, sql)
+			}
+			if nil != err {
+				return nil, errors.Wrap(err)
+			}
 		}
 		err = result.Consume(sqlResult)
 		if nil != err {
-			_ = log.Error(api.tx.Rollback())
-			return nil, dberrors.TranslateError(err, dberrors.Update, sql)
+			return nil, dberrors.TranslateErrorFor(api.dialect(), err, dberrors.Update, sql)
 		}
 	}
-	tracerErr = api.tx.Commit()
-	if nil != tracerErr {
-		return nil, tracerErr
-	}
 	return result, nil
 }
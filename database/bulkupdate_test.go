@@ -1,8 +1,10 @@
 package database
 
 import (
+	"context"
 	"testing"
 
+	dberrors "github.com/beaconsoftwarellc/gadget/v2/database/errors"
 	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
 	"github.com/beaconsoftwarellc/gadget/v2/database/transaction"
 	"github.com/beaconsoftwarellc/gadget/v2/generator"
@@ -45,10 +47,10 @@ func TestBulkUpdate(t *testing.T) {
 		Name: generator.String(32),
 	}
 
-	tx.EXPECT().PrepareNamed(
+	tx.EXPECT().PrepareNamedContext(gomock.Any(),
 		"UPDATE `test_record` SET  `test_record`.`name` = :name "+
 			"WHERE `test_record`.`id` = :id").Return(statement, nil)
-	statement.EXPECT().Exec(expected).Return(&sqlResult{}, nil)
+	statement.EXPECT().ExecContext(gomock.Any(), expected).Return(&sqlResult{}, nil)
 	statement.EXPECT().Close().Return(nil)
 	tx.EXPECT().Commit().Return(nil)
 
@@ -56,3 +58,91 @@ func TestBulkUpdate(t *testing.T) {
 	_, err := bulkUpdate.Commit()
 	assert.NoError(err)
 }
+
+type versionedTestRecord struct {
+	TestRecord
+	Version int `db:"version"`
+}
+
+func (tc *versionedTestRecord) VersionColumn() qb.TableField {
+	return qb.TableField{Name: "version", Table: MetaTestRecord.GetName()}
+}
+
+type staleResult struct {
+	sqlResult
+}
+
+func (*staleResult) RowsAffected() (int64, error) {
+	return 0, nil
+}
+
+func TestBulkUpdate_Versioned(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	statement := transaction.NewMockNamedStatement(ctrl)
+	tx := transaction.NewMockTransaction(ctrl)
+	bulkUpdate := &bulkUpdate[*versionedTestRecord]{
+		bulkOperation: &bulkOperation[*versionedTestRecord]{
+			tx:            tx,
+			db:            &transactable{db: NewMockClient(ctrl)},
+			configuration: &InstanceConfig{Log: log.Global()},
+		},
+		columns: []qb.TableField{MetaTestRecord.Name, (&versionedTestRecord{}).VersionColumn()},
+	}
+	current := &versionedTestRecord{TestRecord: TestRecord{ID: generator.ID("test")}, Version: 2}
+	stale := &versionedTestRecord{TestRecord: TestRecord{ID: generator.ID("test")}, Version: 1}
+
+	tx.EXPECT().PrepareNamedContext(gomock.Any(),
+		"UPDATE `test_record` SET  `test_record`.`name` = :name, "+
+			"`test_record`.`version` = `test_record`.`version` + 1 "+
+			"WHERE (`test_record`.`id` = :id AND `test_record`.`version` = :version)").Return(statement, nil)
+	statement.EXPECT().ExecContext(gomock.Any(), current).Return(&sqlResult{}, nil)
+	statement.EXPECT().ExecContext(gomock.Any(), stale).Return(&staleResult{}, nil)
+	statement.EXPECT().Close().Return(nil)
+	tx.EXPECT().Rollback().Return(nil)
+
+	bulkUpdate.Update(current, stale)
+	_, err := bulkUpdate.Commit()
+	assert.IsType(&dberrors.StaleRecordError{}, err)
+}
+
+func TestBulkUpdateChunks(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	statement := transaction.NewMockNamedStatement(ctrl)
+	tx := transaction.NewMockTransaction(ctrl)
+	bulkUpdate := &bulkUpdate[*TestRecord]{
+		bulkOperation: &bulkOperation[*TestRecord]{
+			tx:            tx,
+			db:            &transactable{db: NewMockClient(ctrl)},
+			configuration: &InstanceConfig{Log: log.Global(), BulkRows: 2},
+		},
+		columns: []qb.TableField{MetaTestRecord.Name},
+	}
+	var progress []BulkProgress
+	bulkUpdate.OnProgress(func(p BulkProgress) { progress = append(progress, p) })
+	records := []*TestRecord{
+		{ID: generator.ID("test"), Name: generator.String(32)},
+		{ID: generator.ID("test"), Name: generator.String(32)},
+		{ID: generator.ID("test"), Name: generator.String(32)},
+	}
+
+	// each chunk prepares the statement and updates its records
+	tx.EXPECT().PrepareNamedContext(gomock.Any(), gomock.Any()).Return(statement, nil).Times(2)
+	for _, record := range records {
+		statement.EXPECT().ExecContext(gomock.Any(), record).Return(&sqlResult{}, nil)
+	}
+	statement.EXPECT().Close().Return(nil).Times(2)
+	tx.EXPECT().Commit().Return(nil)
+
+	assert.NoError(bulkUpdate.UpdateContext(context.Background(), records[0], records[1]))
+	assert.Empty(bulkUpdate.pending)
+	bulkUpdate.Update(records[2])
+	result, err := bulkUpdate.Commit()
+	assert.NoError(err)
+	rows, _ := result.RowsAffected()
+	assert.Equal(int64(3), rows)
+	assert.Empty(result.(BulkResult).InsertIDs())
+	assert.Equal([]BulkProgress{{Chunks: 1, Rows: 2, RowsAffected: 2},
+		{Chunks: 2, Rows: 3, RowsAffected: 3}}, progress)
+}
@@ -2,9 +2,12 @@
 package database
 
 import (
+	"crypto/rand"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/beaconsoftwarellc/gadget/v2/database/transaction"
 	"github.com/beaconsoftwarellc/gadget/v2/log"
 )
 
@@ -13,6 +16,25 @@ const (
 	DefaultMaxTries = 10
 	// DefaultMaxLimit for row counts on select queries
 	DefaultMaxLimit = 100
+	// DefaultMaxTransactionAttempts for transactions that fail with a deadlock or
+	// lock wait timeout
+	DefaultMaxTransactionAttempts = 3
+	// DefaultTransactionRetryMinimumCycle is the minimum wait between transaction
+	// attempts
+	DefaultTransactionRetryMinimumCycle = 10 * time.Millisecond
+	// DefaultTransactionRetryMaxCycle is the maximum wait between transaction
+	// attempts
+	DefaultTransactionRetryMaxCycle = time.Second
+	// DefaultReplicaHealthCheckInterval between pings of each replica
+	DefaultReplicaHealthCheckInterval = 5 * time.Second
+	// DefaultBulkChunkRows sent in each statement of a bulk operation
+	DefaultBulkChunkRows = 1000
+	// DefaultBulkChunkBytes is the estimated size of the values sent in each
+	// statement of a bulk operation, well below the smallest default MySQL
+	// max_allowed_packet of 4MB
+	DefaultBulkChunkBytes = 1 << 20
+
+	cursorKeyLength = 32
 )
 
 // Configuration defines the interface for a specification to establish a database connection
@@ -33,6 +55,52 @@ type Configuration interface {
 	SlowQueryThreshold() time.Duration
 	// LoggedSlowQueries is a map of queries that have been logged as slow
 	LoggedSlowQueries() map[string]time.Duration
+	// QueryInterceptors called around each query after the slow query logger
+	QueryInterceptors() []transaction.QueryInterceptor
+	// ExplainSlowQueries asynchronously and log a summary of the plan with the
+	// slow query, only MySQL is supported
+	ExplainSlowQueries() bool
+	// MaxTransactionAttempts for transactions that fail with a deadlock or lock
+	// wait timeout
+	MaxTransactionAttempts() int
+	// MinimumWaitBetweenTransactionRetries is the minimum backoff between
+	// transaction attempts
+	MinimumWaitBetweenTransactionRetries() time.Duration
+	// MaxWaitBetweenTransactionRetries is the maximum backoff between
+	// transaction attempts
+	MaxWaitBetweenTransactionRetries() time.Duration
+	// CursorKey used to sign keyset pagination cursors
+	CursorKey() []byte
+	// AuditChanges made to records in an audit table, see transaction.Transaction.SetAudit
+	AuditChanges() bool
+	// ReplicaConnections strings for addressing read replicas of the database
+	ReplicaConnections() []string
+	// ReplicaSelection determines which healthy replica serves a read
+	ReplicaSelection() ReplicaSelection
+	// ReplicaHealthCheckInterval between pings of each replica
+	ReplicaHealthCheckInterval() time.Duration
+	// ReadYourWritesWindow after a transaction is committed during which reads
+	// on the same API are made on the primary
+	ReadYourWritesWindow() time.Duration
+	// MaxOpenConnections in the pool of each database, 0 is unlimited
+	MaxOpenConnections() int
+	// MaxIdleConnections in the pool of each database, 0 uses the database/sql
+	// default
+	MaxIdleConnections() int
+	// ConnectionMaxLifetime before a connection is closed, 0 is unlimited
+	ConnectionMaxLifetime() time.Duration
+	// ConnectionMaxIdleTime before an idle connection is closed, 0 is unlimited
+	ConnectionMaxIdleTime() time.Duration
+	// PoolStatsInterval between checks of the pool statistics that warn when
+	// callers waited for a connection, 0 disables the checks
+	PoolStatsInterval() time.Duration
+	// BulkChunkRows is the maximum number of rows a bulk operation sends in a
+	// single statement, inserts are further limited by the number of
+	// placeholders a statement can have
+	BulkChunkRows() int
+	// BulkChunkBytes is the estimated size of the values at which a bulk
+	// operation sends its pending rows
+	BulkChunkBytes() int
 }
 
 // InstanceConfig is a simple struct that satisfies the Config interface
@@ -55,6 +123,52 @@ type InstanceConfig struct {
 	MaxLimit uint
 	// SlowQuery duration establishes the defintion of a slow query for logging
 	SlowQuery time.Duration
+	// Interceptors called around each query in order after the slow query logger,
+	// see transaction.QueryInterceptor
+	Interceptors []transaction.QueryInterceptor
+	// ExplainSlow queries on a separate connection and log a summary of the
+	// plan with the slow query, only MySQL is supported
+	ExplainSlow bool
+	// TransactionMaxAttempts is the maximum number of times a transaction that fails
+	// with a deadlock or lock wait timeout is attempted
+	TransactionMaxAttempts int
+	// TransactionRetryMinimumCycle is the minimum wait between transaction attempts
+	TransactionRetryMinimumCycle time.Duration
+	// TransactionRetryMaxCycle is the maximum wait between transaction attempts
+	TransactionRetryMaxCycle time.Duration
+	// CursorSecret used to sign keyset pagination cursors. If this is not set a
+	// random secret is generated, and cursors will only be accepted by this
+	// instance.
+	CursorSecret []byte
+	// Audit changes made to records by writing an audit.Entry for each in the
+	// same transaction, the audit table must exist
+	Audit bool
+	// Replicas are connection strings for read replicas of this instance
+	Replicas []string
+	// ReplicaStrategy determines which healthy replica serves a read
+	ReplicaStrategy ReplicaSelection
+	// ReplicaHealthCheck is the interval between pings of each replica
+	ReplicaHealthCheck time.Duration
+	// ReadYourWrites is the duration after a commit during which reads are made
+	// on the primary so that they observe the write, 0 disables this
+	ReadYourWrites time.Duration
+	// MaxOpenConns in the pool, 0 is unlimited
+	MaxOpenConns int `env:"DATABASE_MAX_OPEN_CONNS,optional"`
+	// MaxIdleConns in the pool, 0 uses the database/sql default
+	MaxIdleConns int `env:"DATABASE_MAX_IDLE_CONNS,optional"`
+	// ConnMaxLifetime before a connection is closed, 0 is unlimited
+	ConnMaxLifetime time.Duration `env:"DATABASE_CONN_MAX_LIFETIME,optional"`
+	// ConnMaxIdleTime before an idle connection is closed, 0 is unlimited
+	ConnMaxIdleTime time.Duration `env:"DATABASE_CONN_MAX_IDLE_TIME,optional"`
+	// PoolStats is the interval between checks of the pool statistics, 0
+	// disables the checks
+	PoolStats time.Duration `env:"DATABASE_POOL_STATS_INTERVAL,optional"`
+	// BulkRows is the maximum number of rows sent in each statement of a bulk
+	// operation
+	BulkRows int
+	// BulkBytes is the estimated size of the values sent in each statement of a
+	// bulk operation
+	BulkBytes int
 	// Log for this instance
 	Log           log.Logger
 	loggedQueries map[string]time.Duration
@@ -133,10 +247,142 @@ func (config *InstanceConfig) Logger() log.Logger {
 	return config.Log
 }
 
+// loggedQueriesMutex guards creating the logged queries of an InstanceConfig,
+// which is passed around by value
+var loggedQueriesMutex sync.Mutex
+
 // LoggedSlowQueries is a map of queries that have been logged as slow
 func (config *InstanceConfig) LoggedSlowQueries() map[string]time.Duration {
+	loggedQueriesMutex.Lock()
+	defer loggedQueriesMutex.Unlock()
 	if config.loggedQueries == nil {
 		config.loggedQueries = make(map[string]time.Duration)
 	}
 	return config.loggedQueries
 }
+
+// QueryInterceptors called around each query after the slow query logger
+func (config *InstanceConfig) QueryInterceptors() []transaction.QueryInterceptor {
+	return config.Interceptors
+}
+
+// ExplainSlowQueries and log a summary of the plan with the slow query
+func (config *InstanceConfig) ExplainSlowQueries() bool {
+	return config.ExplainSlow
+}
+
+// MaxTransactionAttempts for transactions that fail with a deadlock or lock wait
+// timeout
+func (config *InstanceConfig) MaxTransactionAttempts() int {
+	if config.TransactionMaxAttempts == 0 {
+		config.TransactionMaxAttempts = DefaultMaxTransactionAttempts
+	}
+	return config.TransactionMaxAttempts
+}
+
+// MinimumWaitBetweenTransactionRetries is the minimum backoff between transaction
+// attempts
+func (config *InstanceConfig) MinimumWaitBetweenTransactionRetries() time.Duration {
+	if config.TransactionRetryMinimumCycle == 0 {
+		config.TransactionRetryMinimumCycle = DefaultTransactionRetryMinimumCycle
+	}
+	return config.TransactionRetryMinimumCycle
+}
+
+// MaxWaitBetweenTransactionRetries is the maximum backoff between transaction
+// attempts
+func (config *InstanceConfig) MaxWaitBetweenTransactionRetries() time.Duration {
+	if config.TransactionRetryMaxCycle == 0 {
+		config.TransactionRetryMaxCycle = DefaultTransactionRetryMaxCycle
+	}
+	return config.TransactionRetryMaxCycle
+}
+
+// cursorKeyMutex guards generating the cursor secret of an InstanceConfig so
+// that every cursor of the instance is signed with the same key
+var cursorKeyMutex sync.Mutex
+
+// CursorKey used to sign keyset pagination cursors
+func (config *InstanceConfig) CursorKey() []byte {
+	cursorKeyMutex.Lock()
+	defer cursorKeyMutex.Unlock()
+	if len(config.CursorSecret) == 0 {
+		config.Logger().Warnf("no cursor secret is configured, cursors will not be accepted after a " +
+			"restart or by other instances")
+		secret := make([]byte, cursorKeyLength)
+		_, _ = rand.Read(secret)
+		config.CursorSecret = secret
+	}
+	return config.CursorSecret
+}
+
+// AuditChanges made to records in an audit table
+func (config *InstanceConfig) AuditChanges() bool {
+	return config.Audit
+}
+
+// ReplicaConnections strings for addressing read replicas of the database
+func (config *InstanceConfig) ReplicaConnections() []string {
+	return config.Replicas
+}
+
+// ReplicaSelection determines which healthy replica serves a read
+func (config *InstanceConfig) ReplicaSelection() ReplicaSelection {
+	return config.ReplicaStrategy
+}
+
+// ReplicaHealthCheckInterval between pings of each replica
+func (config *InstanceConfig) ReplicaHealthCheckInterval() time.Duration {
+	if config.ReplicaHealthCheck == 0 {
+		config.ReplicaHealthCheck = DefaultReplicaHealthCheckInterval
+	}
+	return config.ReplicaHealthCheck
+}
+
+// ReadYourWritesWindow after a commit during which reads are made on the primary
+func (config *InstanceConfig) ReadYourWritesWindow() time.Duration {
+	return config.ReadYourWrites
+}
+
+// MaxOpenConnections in the pool of each database, 0 is unlimited
+func (config *InstanceConfig) MaxOpenConnections() int {
+	return config.MaxOpenConns
+}
+
+// MaxIdleConnections in the pool of each database
+func (config *InstanceConfig) MaxIdleConnections() int {
+	return config.MaxIdleConns
+}
+
+// ConnectionMaxLifetime before a connection is closed, 0 is unlimited
+func (config *InstanceConfig) ConnectionMaxLifetime() time.Duration {
+	return config.ConnMaxLifetime
+}
+
+// ConnectionMaxIdleTime before an idle connection is closed, 0 is unlimited
+func (config *InstanceConfig) ConnectionMaxIdleTime() time.Duration {
+	return config.ConnMaxIdleTime
+}
+
+// PoolStatsInterval between checks of the pool statistics, 0 disables them
+func (config *InstanceConfig) PoolStatsInterval() time.Duration {
+	return config.PoolStats
+}
+
+// BulkChunkRows is the maximum number of rows sent in each statement of a bulk
+// operation
+func (config *InstanceConfig) BulkChunkRows() int {
+	if config.BulkRows <= 0 {
+		config.BulkRows = DefaultBulkChunkRows
+	}
+	return config.BulkRows
+}
+
+// BulkChunkBytes is the estimated size of the values sent in each statement of
+// a bulk operation
+func (config *InstanceConfig) BulkChunkBytes() int {
+	if config.BulkBytes <= 0 {
+		config.BulkBytes = DefaultBulkChunkBytes
+	}
+	return config.BulkBytes
+}
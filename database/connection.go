@@ -2,6 +2,8 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"time"
 
 	dberrors "github.com/beaconsoftwarellc/gadget/v2/database/errors"
@@ -18,41 +20,92 @@ import (
 type Client interface {
 	// necessary for executing non-computed queries
 	Select(dest interface{}, query string, args ...interface{}) error
+	// SelectContext is Select with a context that is passed to the driver
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
 	// Beginx starts a sqlx.Tx and returns it
 	Beginx() (*sqlx.Tx, error)
+	// BeginTxx starts a sqlx.Tx that is rolled back if the context is done
+	// before it is committed and returns it
+	BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error)
+	// PingContext verifies the connection to the database is alive
+	PingContext(ctx context.Context) error
+	// Connx returns a single connection from the pool that is held until it is
+	// closed, for state that is scoped to a session such as named locks
+	Connx(ctx context.Context) (*sqlx.Conn, error)
+	// Stats of the connection pool
+	Stats() sql.DBStats
 	// Close this client
 	Close() error
 }
 
 type transactable struct {
-	db Client
+	db      Client
+	options *sql.TxOptions
+	// explainer of slow queries, nil when they are not explained
+	explainer *transaction.Explainer
+}
+
+// transactableFor the passed connection
+func transactableFor(c Connection) *transactable {
+	if conn, ok := c.(*connection); ok {
+		return conn.db
+	}
+	return &transactable{db: c.Client()}
+}
+
+// replicasFor the passed connection, nil when it has none
+func replicasFor(c Connection) *replicaSet {
+	if conn, ok := c.(*connection); ok {
+		return conn.replicas
+	}
+	return nil
+}
+
+// queryInterceptors for a transaction on db, a slow query logger that explains
+// slow queries is included when db has an explainer
+func queryInterceptors(cfg Configuration, db *transactable) []transaction.QueryInterceptor {
+	interceptors := cfg.QueryInterceptors()
+	if nil == db.explainer {
+		return interceptors
+	}
+	slow := transaction.NewSlowQueryLogger(cfg.SlowQueryThreshold(), cfg.Logger(),
+		cfg.LoggedSlowQueries()).WithExplainer(db.explainer)
+	return append([]transaction.QueryInterceptor{slow}, interceptors...)
 }
 
 func (t *transactable) Begin() (transaction.Implementation, error) {
 	return t.db.Beginx()
 }
 
+func (t *transactable) BeginContext(ctx context.Context) (transaction.Implementation, error) {
+	return t.db.BeginTxx(ctx, t.options)
+}
+
 // Connection represents a connection to a database
 type Connection interface {
 	// GetConfiguration used to create this instance
 	GetConfiguration() Configuration
 	// Client for working with this connection at the driver level
 	Client() Client
-	// Database API that this connection is connected to
+	// Database API that this connection is connected to, reads made outside of
+	// a transaction are routed to a healthy replica when replicas are configured
 	Database() API
+	// Stats is a snapshot of the connection pool statistics of the primary
+	Stats() sql.DBStats
 	// Close this collection, further calls will panic
 	Close() error
 }
 
-func connect(dialect, url string, logger log.Logger) (*sqlx.DB, errors.TracerError) {
-	conn, err := sqlx.Connect(dialect, url)
-
+func connect(cfg Configuration) (*sqlx.DB, errors.TracerError) {
+	conn, err := sqlx.Open(cfg.DatabaseDialect(), cfg.DatabaseConnection())
 	if nil != err {
 		return nil, dberrors.NewDatabaseConnectionError(err)
 	}
+	ConfigurePool(conn, cfg)
 
 	if err = conn.Ping(); nil != err {
-		logger.Warnf("Could not ping the database\n%v", err)
+		cfg.Logger().Warnf("Could not ping the database\n%v", err)
+		_ = conn.Close()
 		return nil, dberrors.NewDatabaseConnectionError(err)
 	}
 	return conn, nil
@@ -68,7 +121,7 @@ func Connect(cfg Configuration) (Connection, error) {
 		obfuscatedConnection)
 
 	for retries := 0; retries < cfg.NumberOfRetries(); retries++ {
-		conn, err = connect(cfg.DatabaseDialect(), cfg.DatabaseConnection(), cfg.Logger())
+		conn, err = connect(cfg)
 		if nil == err {
 			break
 		}
@@ -79,11 +132,28 @@ func Connect(cfg Configuration) (Connection, error) {
 		return nil, err
 	}
 	log.Infof("database connection success: %s, %s", cfg.DatabaseDialect(), obfuscatedConnection)
-	return &connection{client: conn, configuration: cfg, connected: true}, nil
+	replicas, replicaErr := connectReplicas(cfg)
+	if nil != replicaErr {
+		_ = conn.Close()
+		return nil, dberrors.NewDatabaseConnectionError(replicaErr)
+	}
+	c := &connection{client: conn, db: &transactable{db: conn}, replicas: replicas,
+		configuration: cfg, connected: true}
+	if cfg.ExplainSlowQueries() && qb.DialectFor(cfg.DatabaseDialect()) == qb.MySQL {
+		c.db.explainer = transaction.NewExplainer(conn, cfg.Logger())
+	}
+	if interval := cfg.PoolStatsInterval(); interval > 0 {
+		c.monitor = newPoolMonitor(conn.Stats, interval, cfg.Logger())
+		c.monitor.start()
+	}
+	return c, nil
 }
 
 type connection struct {
 	client        Client
+	db            *transactable
+	replicas      *replicaSet
+	monitor       *poolMonitor
 	configuration Configuration
 	connected     bool
 }
@@ -99,11 +169,15 @@ func (c *connection) Client() Client {
 	return c.client
 }
 
+func (c *connection) Stats() sql.DBStats {
+	return c.client.Stats()
+}
+
 func (c *connection) Database() API {
 	if !c.connected {
 		panic("Database() called on disconnected connection")
 	}
-	return &api{db: &transactable{c.client}, configuration: c.configuration}
+	return &api{db: c.db, replicas: c.replicas, configuration: c.configuration}
 }
 
 // NewBulkCreate API creating multiple records at the same time.
@@ -111,7 +185,8 @@ func NewBulkCreate[T record.Record](c Connection) (BulkCreate[T], error) {
 	// get a new connection with multistatement enabled
 	bc := &bulkCreate[T]{
 		bulkOperation: &bulkOperation[T]{
-			db:            &transactable{c.Client()},
+			db:            transactableFor(c),
+			replicas:      replicasFor(c),
 			configuration: c.GetConfiguration(),
 		},
 		upsert: false,
@@ -124,7 +199,8 @@ func NewBulkInsert[T record.Record](c Connection, upsert bool) (BulkCreate[T], e
 	// get a new connection with multistatement enabled
 	bc := &bulkCreate[T]{
 		bulkOperation: &bulkOperation[T]{
-			db:            &transactable{c.Client()},
+			db:            transactableFor(c),
+			replicas:      replicasFor(c),
 			configuration: c.GetConfiguration(),
 		},
 		upsert: upsert,
@@ -143,7 +219,8 @@ func NewBulkUpdate[T record.Record](
 	}
 	bu := &bulkUpdate[T]{
 		bulkOperation: &bulkOperation[T]{
-			db:            &transactable{c.Client()},
+			db:            transactableFor(c),
+			replicas:      replicasFor(c),
 			configuration: c.GetConfiguration(),
 		},
 		columns: columns,
@@ -156,5 +233,11 @@ func (c *connection) Close() error {
 		return errors.New("Close() called on disconnected connection")
 	}
 	c.connected = false
+	if nil != c.monitor {
+		c.monitor.Close()
+	}
+	if nil != c.replicas {
+		_ = c.replicas.Close()
+	}
 	return c.client.Close()
 }
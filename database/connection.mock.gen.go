@@ -10,6 +10,8 @@
 package database
 
 import (
+	context "context"
+	sql "database/sql"
 	reflect "reflect"
 
 	sqlx "github.com/jmoiron/sqlx"
@@ -40,6 +42,21 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// BeginTxx mocks base method.
+func (m *MockClient) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginTxx", ctx, opts)
+	ret0, _ := ret[0].(*sqlx.Tx)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginTxx indicates an expected call of BeginTxx.
+func (mr *MockClientMockRecorder) BeginTxx(ctx, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTxx", reflect.TypeOf((*MockClient)(nil).BeginTxx), ctx, opts)
+}
+
 // Beginx mocks base method.
 func (m *MockClient) Beginx() (*sqlx.Tx, error) {
 	m.ctrl.T.Helper()
@@ -69,6 +86,35 @@ func (mr *MockClientMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockClient)(nil).Close))
 }
 
+// Connx mocks base method.
+func (m *MockClient) Connx(ctx context.Context) (*sqlx.Conn, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Connx", ctx)
+	ret0, _ := ret[0].(*sqlx.Conn)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Connx indicates an expected call of Connx.
+func (mr *MockClientMockRecorder) Connx(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Connx", reflect.TypeOf((*MockClient)(nil).Connx), ctx)
+}
+
+// PingContext mocks base method.
+func (m *MockClient) PingContext(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PingContext", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PingContext indicates an expected call of PingContext.
+func (mr *MockClientMockRecorder) PingContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PingContext", reflect.TypeOf((*MockClient)(nil).PingContext), ctx)
+}
+
 // Select mocks base method.
 func (m *MockClient) Select(dest any, query string, args ...any) error {
 	m.ctrl.T.Helper()
@@ -88,6 +134,39 @@ func (mr *MockClientMockRecorder) Select(dest, query any, args ...any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Select", reflect.TypeOf((*MockClient)(nil).Select), varargs...)
 }
 
+// SelectContext mocks base method.
+func (m *MockClient) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, dest, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SelectContext", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SelectContext indicates an expected call of SelectContext.
+func (mr *MockClientMockRecorder) SelectContext(ctx, dest, query any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, dest, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectContext", reflect.TypeOf((*MockClient)(nil).SelectContext), varargs...)
+}
+
+// Stats mocks base method.
+func (m *MockClient) Stats() sql.DBStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(sql.DBStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockClientMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockClient)(nil).Stats))
+}
+
 // MockConnection is a mock of Connection interface.
 type MockConnection struct {
 	ctrl     *gomock.Controller
@@ -167,3 +246,17 @@ func (mr *MockConnectionMockRecorder) GetConfiguration() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfiguration", reflect.TypeOf((*MockConnection)(nil).GetConfiguration))
 }
+
+// Stats mocks base method.
+func (m *MockConnection) Stats() sql.DBStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(sql.DBStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockConnectionMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockConnection)(nil).Stats))
+}
@@ -1,25 +1,56 @@
 package deltas
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
 
+	"github.com/beaconsoftwarellc/gadget/v2/database"
 	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
 	"github.com/beaconsoftwarellc/gadget/v2/database/record"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
 )
 
+// MigrateFunc is a Go-coded step of a delta for data migrations that need logic,
+// it is called with the transaction the delta is executed in
+type MigrateFunc func(ctx context.Context, db database.API) errors.TracerError
+
 // Delta represents a set of changes that are to be applied to the database as an atomic unit
 type Delta struct {
 	ID     int
 	Name   string
 	Script string
+	// Down is the optional script that reverses Script, it is required for the
+	// delta to be rolled back
+	Down string
+	// Migrate is called after Script is executed when it is set
+	Migrate MigrateFunc
+	// Revert is called before Down is executed when it is set, either Revert or
+	// Down is required for a delta with Migrate to be rolled back
+	Revert MigrateFunc
+}
+
+// Checksum of the content of Script, it is stored when the delta is applied so
+// that later edits of an applied script are detected. Changes to Migrate are not
+// detected.
+func (d *Delta) Checksum() string {
+	sum := sha256.Sum256([]byte(d.Script))
+	return hex.EncodeToString(sum[:])
 }
 
 // DeltaRecord is the database representation of delta script and indicates that it has been
 // executed on the database.
 type DeltaRecord struct {
 	record.DefaultRecord
-	ID       int       `db:"id"`
-	Name     string    `db:"name"`
+	ID       int    `db:"id"`
+	Name     string `db:"name"`
+	Checksum string `db:"checksum"`
+	// Status is Applied, InProgress while a delta executed by ExecuteEach is
+	// running or Failed when it did not complete
+	Status State `db:"status"`
+	// Message of the error a Failed delta returned
+	Message  string    `db:"message"`
 	Created  time.Time `db:"created,read_only"`
 	Modified time.Time `db:"modified,read_only"`
 }
@@ -41,3 +72,9 @@ func (dbm *DeltaRecord) Key() string {
 func (dbm *DeltaRecord) Meta() qb.Table {
 	return DeltaMeta
 }
+
+// incomplete is true when the delta was started by ExecuteEach and did not
+// complete
+func (dbm *DeltaRecord) incomplete() bool {
+	return dbm.Status == InProgress || dbm.Status == Failed
+}
@@ -0,0 +1,109 @@
+package deltas
+
+import (
+	"context"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database"
+	dberrors "github.com/beaconsoftwarellc/gadget/v2/database/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/database/utility"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/log"
+)
+
+// ExecuteEach executes the passed deltas sequentially if they have not already
+// been applied to the database, each in its own transaction. This is intended
+// for databases without transactional DDL (MySQL) where a failed Execute leaves
+// the scripts that ran applied but not recorded.
+//
+// Each delta is recorded as InProgress before it is executed and marked Applied
+// in the same transaction as its script, or Failed with the error when it
+// returns one. Deltas are not executed past a Failed or InProgress delta until
+// it is resolved with Repair.
+func ExecuteEach(config database.InstanceConfig, schema string, deltas []*Delta) errors.TracerError {
+	return ExecuteEachContext(context.Background(), config, schema, deltas)
+}
+
+// ExecuteEachContext is ExecuteEach with a context that is passed to the driver
+// for every statement.
+func ExecuteEachContext(ctx context.Context, config database.InstanceConfig, schema string,
+	deltas []*Delta) errors.TracerError {
+	mutex.Lock()
+	defer mutex.Unlock()
+	config.Connection = utility.SetMultiStatement(config.Connection)
+	connection, err := database.Connect(&config)
+	if nil != err {
+		return errors.Wrap(err)
+	}
+	return executeEach(ctx, config, connection, schema, deltas)
+}
+
+func executeEach(ctx context.Context, config database.InstanceConfig, connection database.Connection,
+	schema string, deltas []*Delta) errors.TracerError {
+	log.Infof("executing %d deltas on %s each in a transaction", len(deltas), schema)
+	return locked(ctx, config, connection, func(ctx context.Context) errors.TracerError {
+		db := connection.Database()
+		var tracked bool
+		err := inTransaction(ctx, connection.Client(), db, schema,
+			func(_ database.API, exists bool) errors.TracerError {
+				tracked = exists
+				return nil
+			})
+		if nil != err {
+			return err
+		}
+		for _, delta := range deltas {
+			if tracked {
+				err = executeOne(ctx, db, delta)
+			} else {
+				err = apply(ctx, db, delta)
+			}
+			if nil != err {
+				log.Errorf("stopping deltas: error encountered executing delta %d %s: %s",
+					delta.ID, delta.Name, err)
+				return err
+			}
+		}
+		log.Infof("all deltas processed")
+		return nil
+	})
+}
+
+// executeOne executes the delta in its own transaction if it has not already
+// been executed, recording its progress in the delta table
+func executeOne(ctx context.Context, db database.API, delta *Delta) errors.TracerError {
+	log.Infof("processing delta %d %s", delta.ID, delta.Name)
+	existing := new(DeltaRecord)
+	err := db.ReadOneWhereContext(ctx, existing, DeltaMeta.ID.Equal(delta.ID))
+	if nil == err {
+		log.Infof("%d %s already executed at %s", delta.ID, delta.Name, existing.Created)
+		return verify(ctx, db, delta, existing)
+	}
+	if !dberrors.IsNotFoundError(err) {
+		return errors.Wrap(err)
+	}
+	if _, dry := dryRun(ctx); dry {
+		return apply(ctx, db, delta)
+	}
+	if err = db.CreateContext(ctx, &DeltaRecord{ID: delta.ID, Name: delta.Name, Checksum: delta.Checksum(),
+		Status: InProgress}); nil != err {
+		return errors.Wrap(err)
+	}
+	err = db.WithTransactionContext(ctx, func(tx database.API) error {
+		if err := migrate(ctx, tx, delta); nil != err {
+			return err
+		}
+		_, err := tx.UpdateWhereContext(ctx, &DeltaRecord{}, DeltaMeta.ID.Equal(delta.ID),
+			qb.FieldValue{Field: DeltaMeta.Status, Value: Applied})
+		return err
+	})
+	if nil != err {
+		_, updateErr := db.UpdateWhereContext(ctx, &DeltaRecord{}, DeltaMeta.ID.Equal(delta.ID),
+			qb.FieldValue{Field: DeltaMeta.Status, Value: Failed},
+			qb.FieldValue{Field: DeltaMeta.Message, Value: err.Error()})
+		_ = log.Error(updateErr)
+		return errors.Wrap(err)
+	}
+	log.Infof("successfully applied delta %d %s", delta.ID, delta.Name)
+	return nil
+}
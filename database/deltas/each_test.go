@@ -0,0 +1,88 @@
+package deltas
+
+import (
+	"context"
+	"testing"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database"
+	dberrors "github.com/beaconsoftwarellc/gadget/v2/database/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	assert1 "github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// expectWithTransaction calls the function passed to WithTransactionContext
+// with api
+func expectWithTransaction(api *database.MockAPI) {
+	api.EXPECT().WithTransactionContext(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, fn func(database.API) error) errors.TracerError {
+			return errors.Wrap(fn(api))
+		})
+}
+
+func TestExecuteEach(t *testing.T) {
+	assert := assert1.New(t)
+	connection, client, api, _, txImp := GetMocks(t)
+	deltas := testDeltas()[:2]
+	schema := "schema"
+	expectRun(connection, client, api, schema)
+	api.EXPECT().Commit().Return(nil)
+
+	api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), DeltaMeta.ID.Equal(1)).
+		SetArg(1, DeltaRecord{ID: 1, Checksum: deltas[0].Checksum(), Status: Applied}).Return(nil)
+	api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), DeltaMeta.ID.Equal(2)).
+		Return(dberrors.NewNotFoundError())
+	gomock.InOrder(
+		api.EXPECT().CreateContext(gomock.Any(), &DeltaRecord{ID: 2, Name: "create_b",
+			Checksum: deltas[1].Checksum(), Status: InProgress}).Return(nil),
+		txImp.EXPECT().ExecContext(gomock.Any(), deltas[1].Script).Return(nil, nil),
+		api.EXPECT().UpdateWhereContext(gomock.Any(), &DeltaRecord{}, DeltaMeta.ID.Equal(2),
+			qb.FieldValue{Field: DeltaMeta.Status, Value: Applied}).Return(int64(1), nil),
+	)
+	expectWithTransaction(api)
+
+	assert.NoError(executeEach(context.Background(), rollbackConfig, connection, schema, deltas))
+}
+
+func TestExecuteEach_Failure(t *testing.T) {
+	assert := assert1.New(t)
+	connection, client, api, _, txImp := GetMocks(t)
+	deltas := testDeltas()
+	schema := "schema"
+	expectRun(connection, client, api, schema)
+	api.EXPECT().Commit().Return(nil)
+
+	api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), DeltaMeta.ID.Equal(1)).
+		Return(dberrors.NewNotFoundError())
+	api.EXPECT().CreateContext(gomock.Any(), gomock.Any()).Return(nil)
+	expectWithTransaction(api)
+	txImp.EXPECT().ExecContext(gomock.Any(), deltas[0].Script).Return(nil, errors.New("table a exists"))
+	api.EXPECT().UpdateWhereContext(gomock.Any(), &DeltaRecord{}, DeltaMeta.ID.Equal(1),
+		qb.FieldValue{Field: DeltaMeta.Status, Value: Failed},
+		qb.FieldValue{Field: DeltaMeta.Message, Value: "table a exists"}).Return(int64(1), nil)
+
+	// the remaining deltas are not executed
+	assert.EqualError(executeEach(context.Background(), rollbackConfig, connection, schema, deltas),
+		"table a exists")
+}
+
+func TestExecuteEach_Incomplete(t *testing.T) {
+	for _, status := range []State{Failed, InProgress} {
+		t.Run(string(status), func(t *testing.T) {
+			assert := assert1.New(t)
+			connection, client, api, _, _ := GetMocks(t)
+			deltas := testDeltas()
+			schema := "schema"
+			expectRun(connection, client, api, schema)
+			api.EXPECT().Commit().Return(nil)
+			api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), DeltaMeta.ID.Equal(1)).
+				SetArg(1, DeltaRecord{ID: 1, Name: "create_a", Status: status, Message: "table a exists"}).
+				Return(nil)
+
+			err := executeEach(context.Background(), rollbackConfig, connection, schema, deltas)
+			assert.True(IsIncompleteDeltaError(err))
+			assert.EqualError(err, "delta 1 create_a is "+string(status)+" and must be repaired: table a exists")
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package deltas
+
+import (
+	"fmt"
+
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+)
+
+// ChecksumMismatchError is returned when the script of an applied delta has
+// been changed since it was applied
+type ChecksumMismatchError struct {
+	ID      int
+	Name    string
+	Stored  string
+	Current string
+	trace   []string
+}
+
+// NewChecksumMismatchError for the passed delta and the checksum stored when it
+// was applied
+func NewChecksumMismatchError(delta *Delta, stored string) errors.TracerError {
+	return &ChecksumMismatchError{
+		ID:      delta.ID,
+		Name:    delta.Name,
+		Stored:  stored,
+		Current: delta.Checksum(),
+		trace:   errors.GetStackTrace(),
+	}
+}
+
+func (err *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("delta %d %s has changed since it was applied (checksum %s, applied %s)",
+		err.ID, err.Name, err.Current, err.Stored)
+}
+
+// Trace returns the stack trace for the error
+func (err *ChecksumMismatchError) Trace() []string {
+	return err.trace
+}
+
+// IsChecksumMismatchError indicates if the passed error (which may be wrapped)
+// is a ChecksumMismatchError
+func IsChecksumMismatchError(err error) bool {
+	var dst *ChecksumMismatchError
+	return errors.As(err, &dst)
+}
+
+// IncompleteDeltaError is returned when a delta started by ExecuteEach failed
+// or was interrupted, deltas are not executed past it until it is repaired
+type IncompleteDeltaError struct {
+	ID      int
+	Name    string
+	Status  State
+	Message string
+	trace   []string
+}
+
+// NewIncompleteDeltaError for the passed record of a delta that did not complete
+func NewIncompleteDeltaError(existing *DeltaRecord) errors.TracerError {
+	return &IncompleteDeltaError{
+		ID:      existing.ID,
+		Name:    existing.Name,
+		Status:  existing.Status,
+		Message: existing.Message,
+		trace:   errors.GetStackTrace(),
+	}
+}
+
+func (err *IncompleteDeltaError) Error() string {
+	if err.Message == "" {
+		return fmt.Sprintf("delta %d %s is %s and must be repaired", err.ID, err.Name, err.Status)
+	}
+	return fmt.Sprintf("delta %d %s is %s and must be repaired: %s", err.ID, err.Name, err.Status,
+		err.Message)
+}
+
+// Trace returns the stack trace for the error
+func (err *IncompleteDeltaError) Trace() []string {
+	return err.trace
+}
+
+// IsIncompleteDeltaError indicates if the passed error (which may be wrapped)
+// is an IncompleteDeltaError
+func IsIncompleteDeltaError(err error) bool {
+	var dst *IncompleteDeltaError
+	return errors.As(err, &dst)
+}
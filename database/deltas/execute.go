@@ -1,15 +1,21 @@
 package deltas
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/beaconsoftwarellc/gadget/v2/database"
 	dberrors "github.com/beaconsoftwarellc/gadget/v2/database/errors"
 	"github.com/beaconsoftwarellc/gadget/v2/database/lock"
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
 	"github.com/beaconsoftwarellc/gadget/v2/database/utility"
 	"github.com/beaconsoftwarellc/gadget/v2/errors"
 	"github.com/beaconsoftwarellc/gadget/v2/log"
-	"github.com/beaconsoftwarellc/gadget/v2/net"
 )
 
 const (
@@ -23,22 +29,68 @@ const (
 	CreateDeltaTableSQL = `CREATE TABLE ` + "`" + DeltaTableName + "`" + ` (
 		` + "`id`" + ` int NOT NULL,
 		` + "`name`" + ` varchar(120) NOT NULL,
+		` + "`checksum`" + ` char(64) NOT NULL DEFAULT '',
+		` + "`status`" + ` varchar(16) NOT NULL DEFAULT 'applied',
+		` + "`message`" + ` text NOT NULL,
 		` + "`created`" + ` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		` + "`modified`" + ` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
 		PRIMARY KEY (` + "`id`" + `)
 		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;`
+
+	// AddChecksumColumnSQL upgrades a delta table created before checksums were
+	// stored. Deltas applied before the upgrade have their checksum recorded the
+	// next time they are executed.
+	AddChecksumColumnSQL = `ALTER TABLE ` + "`" + DeltaTableName + "`" + ` ADD COLUMN ` +
+		"`checksum`" + ` char(64) NOT NULL DEFAULT '' AFTER ` + "`name`;"
+
+	// AddStatusColumnsSQL upgrades a delta table created before the status of
+	// deltas executed by ExecuteEach was stored
+	AddStatusColumnsSQL = `ALTER TABLE ` + "`" + DeltaTableName + "`" + ` ADD COLUMN ` +
+		"`status`" + ` varchar(16) NOT NULL DEFAULT 'applied' AFTER ` + "`checksum`, ADD COLUMN " +
+		"`message`" + ` text NOT NULL AFTER ` + "`status`;"
 )
 
+// goMigration is written in place of the Go-coded steps of a delta on a dry run
+const goMigration = "-- go migration"
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context under which Execute, ExecuteDelta and Rollback
+// write the scripts that would run to w instead of executing them. The
+// transaction is rolled back and the delta table is neither created nor
+// upgraded, the statements that would do so are written to w.
+func WithDryRun(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, w)
+}
+
+// dryRun returns the writer passed to WithDryRun and true if ctx is a dry run
+func dryRun(ctx context.Context) (io.Writer, bool) {
+	w, ok := ctx.Value(dryRunKey{}).(io.Writer)
+	return w, ok
+}
+
 var mutex sync.Mutex
 
+// newLocker for the delta lock, replaced in tests
+var newLocker = lock.NewLocker
+
 // Execute the passed deltas sequentially if they have not already been applied to the database.
 // WARN: This function will create the table 'delta' which it needs to track changes if it does not
 // already exist.
 //
 // NOTE: This function assumes that the database has fully transactional DDL
 // (not MySQL). Using this function with a non-transactional DDL database
-// will cause errors to leave the database in an indeterminate state.
+// will cause errors to leave the database in an indeterminate state, use
+// ExecuteEach instead.
 func Execute(config database.InstanceConfig, schema string, deltas []*Delta) errors.TracerError {
+	return ExecuteContext(context.Background(), config, schema, deltas)
+}
+
+// ExecuteContext is Execute with a context that is passed to the driver for every
+// statement. Cancelling the context rolls back any deltas that have not been
+// committed.
+func ExecuteContext(ctx context.Context, config database.InstanceConfig, schema string,
+	deltas []*Delta) errors.TracerError {
 	mutex.Lock()
 	defer mutex.Unlock()
 	config.Connection = utility.SetMultiStatement(config.Connection)
@@ -46,89 +98,226 @@ func Execute(config database.InstanceConfig, schema string, deltas []*Delta) err
 	if nil != err {
 		return errors.Wrap(err)
 	}
-	return execute(config, connection, schema, deltas)
+	return execute(ctx, config, connection, schema, deltas)
 }
 
-func getLock(client database.Client) func() error {
-	return func() error {
-		locked, err := lock.Acquire(client, LockName, 0)
-		if nil != err {
-			return err
-		}
-		if !locked {
-			return errors.New("unable to acquire lock")
+func execute(ctx context.Context, config database.InstanceConfig, connection database.Connection,
+	schema string, deltas []*Delta) errors.TracerError {
+	log.Infof("executing %d deltas on %s", len(deltas), schema)
+	return run(ctx, config, connection, schema, func(db database.API, tracked bool) errors.TracerError {
+		for i, delta := range deltas {
+			var err errors.TracerError
+			if tracked {
+				err = ExecuteDeltaContext(ctx, db, delta)
+			} else {
+				err = apply(ctx, db, delta)
+			}
+			if nil != err {
+				log.Errorf("rolling back deltas: error encountered executing delta %d %s: %s",
+					i, delta.Name, err)
+				return err
+			}
 		}
+		log.Infof("all deltas processed")
 		return nil
-	}
+	})
 }
 
-func execute(config database.InstanceConfig, connection database.Connection,
-	schema string, deltas []*Delta) errors.TracerError {
-	defer connection.Close()
-	var err error
+// run fn in a transaction while holding the delta lock, creating or upgrading
+// the delta table first. tracked is false when the delta table does not exist
+// because it was not created for a dry run. The transaction is committed when
+// fn succeeds unless ctx is a dry run.
+func run(ctx context.Context, config database.InstanceConfig, connection database.Connection,
+	schema string, fn func(db database.API, tracked bool) errors.TracerError) errors.TracerError {
+	return locked(ctx, config, connection, func(ctx context.Context) errors.TracerError {
+		return inTransaction(ctx, connection.Client(), connection.Database(), schema, fn)
+	})
+}
 
-	log.Infof("executing %d deltas on %s", len(deltas), schema)
-	// get the lock first
-	err = net.BackoffExtended(
-		getLock(connection.Client()),
-		config.NumberOfDeltaLockTries(),
-		config.MinimumWaitBetweenDeltaLockRetries(),
-		config.MaxWaitBetweenDeltaLockRetries(),
-	)
+// locked calls fn while holding the delta lock and closes the connection once
+// it returns. The lock is waited for up to the configured number of tries
+// times the maximum wait between them, and the context passed to fn is
+// cancelled if the lock is lost which rolls back a transaction begun with it.
+func locked(ctx context.Context, config database.InstanceConfig, connection database.Connection,
+	fn func(ctx context.Context) errors.TracerError) errors.TracerError {
+	defer connection.Close()
+	wait, cancel := context.WithTimeout(ctx,
+		time.Duration(config.NumberOfDeltaLockTries())*config.MaxWaitBetweenDeltaLockRetries())
+	defer cancel()
+	handle, err := newLocker(connection).Lock(wait, LockName, 0)
 	if nil != err {
-		return errors.Wrap(err)
+		return err
 	}
 	log.Debugf("db lock acquired")
-	defer func() { _ = lock.Release(connection.Client(), LockName) }()
+	return lock.Hold(ctx, handle, 0, func(ctx context.Context) error {
+		// avoid returning a nil TracerError as a non-nil error
+		if err := fn(ctx); nil != err {
+			return err
+		}
+		return nil
+	})
+}
 
-	db := connection.Database()
-	if err = db.Begin(); nil != err {
+// inTransaction calls fn in a transaction on db after creating or upgrading the
+// delta table, see run
+func inTransaction(ctx context.Context, client database.Client, db database.API, schema string,
+	fn func(db database.API, tracked bool) errors.TracerError) errors.TracerError {
+	var err error
+	if err = db.BeginContext(ctx); nil != err {
 		return errors.Wrap(err)
 	}
+	w, dry := dryRun(ctx)
+	tracked, err := prepareTable(ctx, client, db, schema, w)
+	if nil == err {
+		err = fn(db, tracked)
+	}
+	if nil != err || dry {
+		_ = log.Error(db.Rollback())
+		return errors.Wrap(err)
+	}
+	return errors.Wrap(log.Error(db.Commit()))
+}
 
-	exists, err := database.TableExists(connection.Client(), schema, DeltaTableName)
+// prepareTable creates the delta table if it does not exist and adds the
+// columns that are missing from a table created by an earlier version. When w
+// is not nil the statements are written to it instead of being executed and
+// false is returned if the table does not exist.
+func prepareTable(ctx context.Context, client database.Client, db database.API, schema string,
+	w io.Writer) (bool, error) {
+	exists, err := database.TableExistsContext(ctx, client, schema, DeltaTableName)
 	if nil != err {
-		return errors.Wrap(err)
+		return false, err
 	}
 	if !exists {
+		if nil != w {
+			_, err = fmt.Fprintf(w, "-- create delta table\n%s\n", CreateDeltaTableSQL)
+			return false, err
+		}
 		log.Infof("deltas table does not exist, it will be created")
-		_, err = db.GetTransaction().Implementation().Exec(CreateDeltaTableSQL)
+		_, err = db.GetTransaction().Implementation().ExecContext(ctx, CreateDeltaTableSQL)
+		return nil == err, err
+	}
+	for _, upgrade := range upgrades {
+		exists, err = database.ColumnExistsContext(ctx, client, schema, DeltaTableName, upgrade.column.Name)
 		if nil != err {
-			return errors.Wrap(err)
+			return false, err
 		}
-	}
-	for i, delta := range deltas {
-		if err := ExecuteDelta(db, delta); nil != err {
-			log.Errorf("rolling back deltas: error encountered executing delta %d %s: %s",
-				i, delta.Name, err)
-			_ = log.Error(db.Rollback())
-			return err
+		if exists {
+			continue
+		}
+		if nil != w {
+			if _, err = fmt.Fprintf(w, "-- upgrade delta table\n%s\n", upgrade.statement); nil != err {
+				return false, err
+			}
+			continue
+		}
+		log.Infof("deltas table does not have a %s column, it will be added", upgrade.column.Name)
+		if _, err = db.GetTransaction().Implementation().ExecContext(ctx, upgrade.statement); nil != err {
+			return false, err
 		}
 	}
-	log.Infof("all deltas processed")
-	return errors.Wrap(log.Error(db.Commit()))
+	return true, nil
+}
+
+// upgrades of the delta table, each statement adds the column and those after
+// it in the table
+var upgrades = []struct {
+	column    qb.TableField
+	statement string
+}{
+	{column: DeltaMeta.Checksum, statement: AddChecksumColumnSQL},
+	{column: DeltaMeta.Status, statement: AddStatusColumnsSQL},
 }
 
 // ExecuteDelta checks if the passed delta has already been executed according to the Deltas table, and then executes
 // if it has not been using the passed transaction for both queries.
 func ExecuteDelta(db database.API, delta *Delta) errors.TracerError {
+	return ExecuteDeltaContext(context.Background(), db, delta)
+}
+
+// ExecuteDeltaContext is ExecuteDelta with a context that is passed to the driver
+// for every statement.
+func ExecuteDeltaContext(ctx context.Context, db database.API, delta *Delta) errors.TracerError {
 	log.Infof("processing delta %d %s", delta.ID, delta.Name)
 	// check that the delta has not already been executed
 	existing := new(DeltaRecord)
 	var err error
-	err = db.ReadOneWhere(existing, DeltaMeta.ID.Equal(delta.ID))
+	err = db.ReadOneWhereContext(ctx, existing, DeltaMeta.ID.Equal(delta.ID))
 	if nil == err {
 		log.Infof("%d %s already executed at %s", delta.ID, delta.Name, existing.Created)
-		return nil
+		return verify(ctx, db, delta, existing)
 	}
 	if !dberrors.IsNotFoundError(err) {
 		return errors.Wrap(err)
 	}
+	return apply(ctx, db, delta)
+}
 
-	// actually execute the script
-	if _, err = db.GetTransaction().Implementation().Exec(delta.Script); nil != err {
+// verify that an executed delta completed and that its script has not changed
+// since it was applied, recording the checksum if it was applied before they
+// were stored
+func verify(ctx context.Context, db database.API, delta *Delta, existing *DeltaRecord) errors.TracerError {
+	if existing.incomplete() {
+		return NewIncompleteDeltaError(existing)
+	}
+	if existing.Checksum == "" {
+		return recordChecksum(ctx, db, delta)
+	}
+	if existing.Checksum != delta.Checksum() {
+		return NewChecksumMismatchError(delta, existing.Checksum)
+	}
+	return nil
+}
+
+// recordChecksum of a delta applied before checksums were stored
+func recordChecksum(ctx context.Context, db database.API, delta *Delta) errors.TracerError {
+	if _, dry := dryRun(ctx); dry {
+		return nil
+	}
+	_, err := db.UpdateWhereContext(ctx, &DeltaRecord{}, DeltaMeta.ID.Equal(delta.ID),
+		qb.FieldValue{Field: DeltaMeta.Checksum, Value: delta.Checksum()})
+	return err
+}
+
+// apply the script of a delta that has not been executed and record it
+func apply(ctx context.Context, db database.API, delta *Delta) errors.TracerError {
+	if w, dry := dryRun(ctx); dry {
+		_, err := fmt.Fprintf(w, "-- apply delta %d %s\n%s\n", delta.ID, delta.Name,
+			describe(delta.Script, goStep(delta.Migrate)))
 		return errors.Wrap(err)
 	}
+	if err := migrate(ctx, db, delta); nil != err {
+		return err
+	}
 	log.Infof("successfully applied delta %d %s", delta.ID, delta.Name)
-	return db.Create(&DeltaRecord{ID: delta.ID, Name: delta.Name})
+	return db.CreateContext(ctx, &DeltaRecord{ID: delta.ID, Name: delta.Name, Checksum: delta.Checksum(),
+		Status: Applied})
+}
+
+// migrate executes the script and then the Go-coded step of a delta in the
+// transaction of db
+func migrate(ctx context.Context, db database.API, delta *Delta) errors.TracerError {
+	if delta.Script != "" {
+		if _, err := db.GetTransaction().Implementation().ExecContext(ctx, delta.Script); nil != err {
+			return errors.Wrap(err)
+		}
+	}
+	if nil != delta.Migrate {
+		return delta.Migrate(ctx, db)
+	}
+	return nil
+}
+
+// describe the steps of a delta for a dry run in the order they are executed,
+// Go-coded steps are described by goStep
+func describe(steps ...string) string {
+	return strings.Join(slices.DeleteFunc(steps, func(step string) bool { return step == "" }), "\n")
+}
+
+// goStep describes fn for a dry run, empty when it is nil
+func goStep(fn MigrateFunc) string {
+	if nil == fn {
+		return ""
+	}
+	return goMigration
 }
@@ -1,12 +1,17 @@
 package deltas
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/beaconsoftwarellc/gadget/v2/database"
 	dberrors "github.com/beaconsoftwarellc/gadget/v2/database/errors"
 	"github.com/beaconsoftwarellc/gadget/v2/database/lock"
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
 	"github.com/beaconsoftwarellc/gadget/v2/database/transaction"
 	"github.com/beaconsoftwarellc/gadget/v2/errors"
 	"github.com/beaconsoftwarellc/gadget/v2/generator"
@@ -25,12 +30,25 @@ func GetMocks(t *testing.T) (*database.MockConnection, *database.MockClient, *da
 	api.EXPECT().GetTransaction().Return(tx).AnyTimes()
 	txImp := transaction.NewMockImplementation(ctrl)
 	tx.EXPECT().Implementation().Return(txImp).AnyTimes()
+	handle := lock.NewMockHandle(ctrl)
+	handle.EXPECT().Name().Return(LockName).AnyTimes()
+	handle.EXPECT().Unlock(gomock.Any()).Return(nil).AnyTimes()
+	locker := lock.NewMockLocker(ctrl)
+	locker.EXPECT().Lock(gomock.Any(), LockName, time.Duration(0)).Return(handle, nil).AnyTimes()
+	setLocker(t, locker)
 	return connection, client, api, tx, txImp
 }
 
+// setLocker used by the deltas for the duration of the test
+func setLocker(t *testing.T, locker lock.Locker) {
+	original := newLocker
+	newLocker = func(database.Connection) lock.Locker { return locker }
+	t.Cleanup(func() { newLocker = original })
+}
+
 func TestExecute_LockError(t *testing.T) {
 	assert := assert1.New(t)
-	connection, client, _, _, _ := GetMocks(t)
+	connection, _, _, _, _ := GetMocks(t)
 	deltas := []*Delta{
 		{
 			ID:     generator.Int(),
@@ -40,37 +58,30 @@ func TestExecute_LockError(t *testing.T) {
 	}
 	// lock acquisition
 	expected := generator.String(32)
-	client.EXPECT().Select(gomock.Any(),
-		"SELECT GET_LOCK('delta_exec', 0) AS STATUS").Return(
-		errors.New(expected))
-	connection.EXPECT().Close().Return(nil)
-	actual := execute(database.InstanceConfig{
+	config := database.InstanceConfig{
 		DeltaLockMaxTries:     1,
 		DeltaLockMinimumCycle: 1,
 		DeltaLockMaxCycle:     2,
-	}, connection, "", deltas)
-	assert.EqualError(actual, expected)
-}
-
-type lockMatcher struct {
-}
-
-func (lockMatcher) Matches(x interface{}) bool {
-	rows, ok := x.(*[]*lock.StatusResult)
-	if !ok {
-		return false
 	}
-	*rows = []*lock.StatusResult{{Status: 1}}
-	return true
-}
-
-func (lockMatcher) String() string {
-	return "lock matcher"
+	wait := time.Duration(config.NumberOfDeltaLockTries()) * config.MaxWaitBetweenDeltaLockRetries()
+	locker := lock.NewMockLocker(gomock.NewController(t))
+	locker.EXPECT().Lock(gomock.Any(), LockName, time.Duration(0)).DoAndReturn(
+		func(ctx context.Context, _ string, _ time.Duration) (lock.Handle, errors.TracerError) {
+			// the lock is waited for up to tries times the maximum wait
+			deadline, ok := ctx.Deadline()
+			assert.True(ok)
+			assert.WithinDuration(time.Now().Add(wait), deadline, time.Second)
+			return nil, errors.New(expected)
+		})
+	setLocker(t, locker)
+	connection.EXPECT().Close().Return(nil)
+	actual := execute(context.Background(), config, connection, "", deltas)
+	assert.EqualError(actual, expected)
 }
 
 func TestExecute_BeginError(t *testing.T) {
 	assert := assert1.New(t)
-	connection, client, api, _, _ := GetMocks(t)
+	connection, _, api, _, _ := GetMocks(t)
 	deltas := []*Delta{
 		{
 			ID:     generator.Int(),
@@ -78,15 +89,11 @@ func TestExecute_BeginError(t *testing.T) {
 			Script: generator.String(128),
 		},
 	}
-	// lock acquisition
-	client.EXPECT().Select(&lockMatcher{}, "SELECT GET_LOCK('delta_exec', 0) AS STATUS").Return(nil)
-	// lock release
-	client.EXPECT().Select(gomock.Any(), "SELECT RELEASE_LOCK('delta_exec') AS STATUS").Return(nil)
 	expected := generator.String(32)
 	connection.EXPECT().Database().Return(api)
-	api.EXPECT().Begin().Return(errors.New(expected))
+	api.EXPECT().BeginContext(gomock.Any()).Return(errors.New(expected))
 	connection.EXPECT().Close().Return(nil)
-	actual := execute(database.InstanceConfig{
+	actual := execute(context.Background(), database.InstanceConfig{
 		DeltaLockMaxTries:     1,
 		DeltaLockMinimumCycle: 1,
 		DeltaLockMaxCycle:     2,
@@ -113,6 +120,47 @@ func (matcher *tableExistsMatcher) String() string {
 	return fmt.Sprintf("tableExistsMatcher(%v)", matcher.tableExists)
 }
 
+type columnExistsMatcher struct {
+	columnExists bool
+}
+
+func (matcher *columnExistsMatcher) Matches(x interface{}) bool {
+	rows, ok := x.(*[]*database.ColumnNameResult)
+	if !ok {
+		return false
+	}
+	if matcher.columnExists {
+		*rows = []*database.ColumnNameResult{{}}
+	}
+	return true
+}
+
+func (matcher *columnExistsMatcher) String() string {
+	return fmt.Sprintf("columnExistsMatcher(%v)", matcher.columnExists)
+}
+
+// expectColumnsExist sets the expectations for checking that each of the
+// columns added by upgrades of the delta table exists
+func expectColumnsExist(client *database.MockClient, schema string, exists bool) {
+	for _, upgrade := range upgrades {
+		client.EXPECT().SelectContext(gomock.Any(), &columnExistsMatcher{exists},
+			fmt.Sprintf(database.ColumnExistenceQueryFormat, schema, DeltaTableName,
+				upgrade.column.Name)).Return(nil)
+	}
+}
+
+// expectRun sets the expectations for acquiring the lock and beginning the
+// transaction of a run where the delta table exists
+func expectRun(connection *database.MockConnection, client *database.MockClient, api *database.MockAPI,
+	schema string) {
+	connection.EXPECT().Database().Return(api)
+	api.EXPECT().BeginContext(gomock.Any()).Return(nil)
+	client.EXPECT().SelectContext(gomock.Any(), &tableExistsMatcher{true},
+		fmt.Sprintf(database.TableExistenceQueryFormat, schema, DeltaTableName)).Return(nil)
+	expectColumnsExist(client, schema, true)
+	connection.EXPECT().Close().Return(nil)
+}
+
 func TestExecute_TableExists_Error(t *testing.T) {
 	assert := assert1.New(t)
 	connection, client, api, _, _ := GetMocks(t)
@@ -124,20 +172,17 @@ func TestExecute_TableExists_Error(t *testing.T) {
 		},
 	}
 	schema := generator.String(32)
-	// lock acquisition
-	client.EXPECT().Select(&lockMatcher{}, "SELECT GET_LOCK('delta_exec', 0) AS STATUS").Return(nil)
-	// lock release
-	client.EXPECT().Select(gomock.Any(), "SELECT RELEASE_LOCK('delta_exec') AS STATUS").Return(nil)
 
 	connection.EXPECT().Database().Return(api)
-	api.EXPECT().Begin().Return(nil)
+	api.EXPECT().BeginContext(gomock.Any()).Return(nil)
 	tableExistsQuery := fmt.Sprintf(database.TableExistenceQueryFormat, schema, DeltaTableName)
 
 	expected := generator.String(32)
-	client.EXPECT().Select(&tableExistsMatcher{true}, tableExistsQuery).
+	client.EXPECT().SelectContext(gomock.Any(), &tableExistsMatcher{true}, tableExistsQuery).
 		Return(errors.New(expected))
+	api.EXPECT().Rollback().Return(nil)
 	connection.EXPECT().Close().Return(nil)
-	actual := execute(database.InstanceConfig{
+	actual := execute(context.Background(), database.InstanceConfig{
 		DeltaLockMaxTries:     1,
 		DeltaLockMinimumCycle: 1,
 		DeltaLockMaxCycle:     2,
@@ -156,30 +201,27 @@ func TestExecute_TableDoesNotExist(t *testing.T) {
 		},
 	}
 	schema := generator.String(32)
-	// lock acquisition
-	client.EXPECT().Select(&lockMatcher{}, "SELECT GET_LOCK('delta_exec', 0) AS STATUS").Return(nil)
-	// lock release
-	client.EXPECT().Select(gomock.Any(), "SELECT RELEASE_LOCK('delta_exec') AS STATUS").Return(nil)
 
 	connection.EXPECT().Database().Return(api)
-	api.EXPECT().Begin().Return(nil)
+	api.EXPECT().BeginContext(gomock.Any()).Return(nil)
 	tableExistsQuery := fmt.Sprintf(database.TableExistenceQueryFormat, schema, DeltaTableName)
 
-	client.EXPECT().Select(&tableExistsMatcher{false}, tableExistsQuery).Return(nil)
+	client.EXPECT().SelectContext(gomock.Any(), &tableExistsMatcher{false}, tableExistsQuery).Return(nil)
 
-	txImp.EXPECT().Exec(CreateDeltaTableSQL).Return(nil, nil)
+	txImp.EXPECT().ExecContext(gomock.Any(), CreateDeltaTableSQL).Return(nil, nil)
 
 	// ExecuteDelta calls
-	api.EXPECT().ReadOneWhere(gomock.Any(), DeltaMeta.ID.Equal(deltas[0].ID)).
+	api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), DeltaMeta.ID.Equal(deltas[0].ID)).
 		Return(dberrors.NewNotFoundError())
-	txImp.EXPECT().Exec(deltas[0].Script).Return(nil, nil)
-	api.EXPECT().Create(&DeltaRecord{ID: deltas[0].ID, Name: deltas[0].Name}).Return(nil)
+	txImp.EXPECT().ExecContext(gomock.Any(), deltas[0].Script).Return(nil, nil)
+	api.EXPECT().CreateContext(gomock.Any(), &DeltaRecord{ID: deltas[0].ID, Name: deltas[0].Name,
+		Checksum: deltas[0].Checksum(), Status: Applied}).Return(nil)
 	// / ExecuteDelta calls
 
 	api.EXPECT().Commit().Return(nil)
 	connection.EXPECT().Close().Return(nil)
 
-	actual := execute(database.InstanceConfig{
+	actual := execute(context.Background(), database.InstanceConfig{
 		DeltaLockMaxTries:     1,
 		DeltaLockMinimumCycle: 1,
 		DeltaLockMaxCycle:     2,
@@ -198,28 +240,26 @@ func TestExecute(t *testing.T) {
 		},
 	}
 	schema := generator.String(32)
-	// lock acquisition
-	client.EXPECT().Select(&lockMatcher{}, "SELECT GET_LOCK('delta_exec', 0) AS STATUS").Return(nil)
-	// lock release
-	client.EXPECT().Select(gomock.Any(), "SELECT RELEASE_LOCK('delta_exec') AS STATUS").Return(nil)
 
 	connection.EXPECT().Database().Return(api)
-	api.EXPECT().Begin().Return(nil)
+	api.EXPECT().BeginContext(gomock.Any()).Return(nil)
 	tableExistsQuery := fmt.Sprintf(database.TableExistenceQueryFormat, schema, DeltaTableName)
 
-	client.EXPECT().Select(&tableExistsMatcher{true}, tableExistsQuery).Return(nil)
+	client.EXPECT().SelectContext(gomock.Any(), &tableExistsMatcher{true}, tableExistsQuery).Return(nil)
+	expectColumnsExist(client, schema, true)
 
 	// ExecuteDelta calls
-	api.EXPECT().ReadOneWhere(gomock.Any(), DeltaMeta.ID.Equal(deltas[0].ID)).
+	api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), DeltaMeta.ID.Equal(deltas[0].ID)).
 		Return(dberrors.NewNotFoundError())
-	txImp.EXPECT().Exec(deltas[0].Script).Return(nil, nil)
-	api.EXPECT().Create(&DeltaRecord{ID: deltas[0].ID, Name: deltas[0].Name}).Return(nil)
+	txImp.EXPECT().ExecContext(gomock.Any(), deltas[0].Script).Return(nil, nil)
+	api.EXPECT().CreateContext(gomock.Any(), &DeltaRecord{ID: deltas[0].ID, Name: deltas[0].Name,
+		Checksum: deltas[0].Checksum(), Status: Applied}).Return(nil)
 	// / ExecuteDelta calls
 
 	api.EXPECT().Commit().Return(nil)
 	connection.EXPECT().Close().Return(nil)
 
-	actual := execute(database.InstanceConfig{
+	actual := execute(context.Background(), database.InstanceConfig{
 		DeltaLockMaxTries:     1,
 		DeltaLockMinimumCycle: 1,
 		DeltaLockMaxCycle:     2,
@@ -238,30 +278,28 @@ func TestExecute_Rollback(t *testing.T) {
 		},
 	}
 	schema := generator.String(32)
-	// lock acquisition
-	client.EXPECT().Select(&lockMatcher{}, "SELECT GET_LOCK('delta_exec', 0) AS STATUS").Return(nil)
-	// lock release
-	client.EXPECT().Select(gomock.Any(), "SELECT RELEASE_LOCK('delta_exec') AS STATUS").Return(nil)
 
 	connection.EXPECT().Database().Return(api)
-	api.EXPECT().Begin().Return(nil)
+	api.EXPECT().BeginContext(gomock.Any()).Return(nil)
 	tableExistsQuery := fmt.Sprintf(database.TableExistenceQueryFormat, schema, DeltaTableName)
 
-	client.EXPECT().Select(&tableExistsMatcher{true}, tableExistsQuery).Return(nil)
+	client.EXPECT().SelectContext(gomock.Any(), &tableExistsMatcher{true}, tableExistsQuery).Return(nil)
+	expectColumnsExist(client, schema, true)
 
 	// ExecuteDelta calls
-	api.EXPECT().ReadOneWhere(gomock.Any(), DeltaMeta.ID.Equal(deltas[0].ID)).
+	api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), DeltaMeta.ID.Equal(deltas[0].ID)).
 		Return(dberrors.NewNotFoundError())
-	txImp.EXPECT().Exec(deltas[0].Script).Return(nil, nil)
+	txImp.EXPECT().ExecContext(gomock.Any(), deltas[0].Script).Return(nil, nil)
 	expected := generator.String(32)
-	api.EXPECT().Create(&DeltaRecord{ID: deltas[0].ID, Name: deltas[0].Name}).
+	api.EXPECT().CreateContext(gomock.Any(), &DeltaRecord{ID: deltas[0].ID, Name: deltas[0].Name,
+		Checksum: deltas[0].Checksum(), Status: Applied}).
 		Return(errors.New(expected))
 	// / ExecuteDelta calls
 
 	api.EXPECT().Rollback().Return(nil)
 	connection.EXPECT().Close().Return(nil)
 
-	actual := execute(database.InstanceConfig{
+	actual := execute(context.Background(), database.InstanceConfig{
 		DeltaLockMaxTries:     1,
 		DeltaLockMinimumCycle: 1,
 		DeltaLockMaxCycle:     2,
@@ -274,14 +312,69 @@ func TestExecuteDelta_AlreadyExecuted(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	api := database.NewMockAPI(ctrl)
 	delta := &Delta{
-		ID:   generator.Int(),
-		Name: generator.String(32),
+		ID:     generator.Int(),
+		Name:   generator.String(32),
+		Script: generator.String(128),
 	}
-	api.EXPECT().ReadOneWhere(gomock.Any(), DeltaMeta.ID.Equal(delta.ID)).Return(nil)
+	api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), DeltaMeta.ID.Equal(delta.ID)).
+		SetArg(1, DeltaRecord{ID: delta.ID, Checksum: delta.Checksum()}).Return(nil)
 	err := ExecuteDelta(api, delta)
 	assert.NoError(err)
 }
 
+func TestExecuteDelta_RecordsChecksum(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	api := database.NewMockAPI(ctrl)
+	delta := &Delta{
+		ID:     generator.Int(),
+		Name:   generator.String(32),
+		Script: generator.String(128),
+	}
+	api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), DeltaMeta.ID.Equal(delta.ID)).
+		Return(nil).Times(2)
+	// not recorded on a dry run
+	assert.NoError(ExecuteDeltaContext(WithDryRun(context.Background(), io.Discard), api, delta))
+
+	api.EXPECT().UpdateWhereContext(gomock.Any(), &DeltaRecord{}, DeltaMeta.ID.Equal(delta.ID),
+		qb.FieldValue{Field: DeltaMeta.Checksum, Value: delta.Checksum()}).Return(int64(1), nil)
+	assert.NoError(ExecuteDelta(api, delta))
+}
+
+func TestExecuteDelta_ChecksumMismatch(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	api := database.NewMockAPI(ctrl)
+	delta := &Delta{
+		ID:     generator.Int(),
+		Name:   generator.String(32),
+		Script: generator.String(128),
+	}
+	stored := (&Delta{Script: generator.String(128)}).Checksum()
+	api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), DeltaMeta.ID.Equal(delta.ID)).
+		SetArg(1, DeltaRecord{ID: delta.ID, Checksum: stored}).Return(nil)
+	err := ExecuteDelta(api, delta)
+	assert.True(IsChecksumMismatchError(err))
+	assert.EqualError(err, fmt.Sprintf("delta %d %s has changed since it was applied (checksum %s, applied %s)",
+		delta.ID, delta.Name, delta.Checksum(), stored))
+}
+
+func TestExecuteDelta_DryRun(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	api := database.NewMockAPI(ctrl)
+	delta := &Delta{
+		ID:     1,
+		Name:   "create_a",
+		Script: "CREATE TABLE a (id int);",
+	}
+	api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), DeltaMeta.ID.Equal(delta.ID)).
+		Return(dberrors.NewNotFoundError())
+	var out strings.Builder
+	assert.NoError(ExecuteDeltaContext(WithDryRun(context.Background(), &out), api, delta))
+	assert.Equal("-- apply delta 1 create_a\nCREATE TABLE a (id int);\n", out.String())
+}
+
 func TestExecuteDelta_UnexpectedError(t *testing.T) {
 	assert := assert1.New(t)
 	ctrl := gomock.NewController(t)
@@ -291,7 +384,7 @@ func TestExecuteDelta_UnexpectedError(t *testing.T) {
 		Name: generator.String(32),
 	}
 	expected := generator.String(32)
-	api.EXPECT().ReadOneWhere(gomock.Any(), DeltaMeta.ID.Equal(delta.ID)).
+	api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), DeltaMeta.ID.Equal(delta.ID)).
 		Return(errors.New(expected))
 	actual := ExecuteDelta(api, delta)
 	assert.EqualError(actual, expected)
@@ -309,11 +402,11 @@ func TestExecuteDelta_ExecError(t *testing.T) {
 		Script: generator.String(128),
 	}
 	expected := generator.String(32)
-	api.EXPECT().ReadOneWhere(gomock.Any(), DeltaMeta.ID.Equal(delta.ID)).
+	api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), DeltaMeta.ID.Equal(delta.ID)).
 		Return(dberrors.NewNotFoundError())
 	api.EXPECT().GetTransaction().Return(tx)
 	tx.EXPECT().Implementation().Return(txImp)
-	txImp.EXPECT().Exec(delta.Script).Return(nil, errors.New(expected))
+	txImp.EXPECT().ExecContext(gomock.Any(), delta.Script).Return(nil, errors.New(expected))
 	actual := ExecuteDelta(api, delta)
 	assert.EqualError(actual, expected)
 }
@@ -330,13 +423,116 @@ func TestExecuteDelta(t *testing.T) {
 		Script: generator.String(128),
 	}
 	expected := generator.String(32)
-	api.EXPECT().ReadOneWhere(gomock.Any(), DeltaMeta.ID.Equal(delta.ID)).
+	api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), DeltaMeta.ID.Equal(delta.ID)).
 		Return(dberrors.NewNotFoundError())
 	api.EXPECT().GetTransaction().Return(tx)
 	tx.EXPECT().Implementation().Return(txImp)
-	txImp.EXPECT().Exec(delta.Script).Return(nil, nil)
-	api.EXPECT().Create(&DeltaRecord{ID: delta.ID, Name: delta.Name}).
+	txImp.EXPECT().ExecContext(gomock.Any(), delta.Script).Return(nil, nil)
+	api.EXPECT().CreateContext(gomock.Any(), &DeltaRecord{ID: delta.ID, Name: delta.Name,
+		Checksum: delta.Checksum(), Status: Applied}).
 		Return(errors.New(expected))
 	actual := ExecuteDelta(api, delta)
 	assert.EqualError(actual, expected)
 }
+
+func TestExecute_UpgradesTable(t *testing.T) {
+	assert := assert1.New(t)
+	connection, client, api, _, txImp := GetMocks(t)
+	schema := generator.String(32)
+	connection.EXPECT().Database().Return(api)
+	api.EXPECT().BeginContext(gomock.Any()).Return(nil)
+	client.EXPECT().SelectContext(gomock.Any(), &tableExistsMatcher{true},
+		fmt.Sprintf(database.TableExistenceQueryFormat, schema, DeltaTableName)).Return(nil)
+	expectColumnsExist(client, schema, false)
+	txImp.EXPECT().ExecContext(gomock.Any(), AddChecksumColumnSQL).Return(nil, nil)
+	txImp.EXPECT().ExecContext(gomock.Any(), AddStatusColumnsSQL).Return(nil, nil)
+	api.EXPECT().Commit().Return(nil)
+	connection.EXPECT().Close().Return(nil)
+
+	actual := execute(context.Background(), database.InstanceConfig{
+		DeltaLockMaxTries:     1,
+		DeltaLockMinimumCycle: 1,
+		DeltaLockMaxCycle:     2,
+	}, connection, schema, nil)
+	assert.NoError(actual)
+}
+
+func TestExecute_DryRun(t *testing.T) {
+	assert := assert1.New(t)
+	connection, client, api, _, _ := GetMocks(t)
+	deltas := []*Delta{{ID: 1, Name: "create_a", Script: "CREATE TABLE a (id int);"}}
+	schema := generator.String(32)
+	connection.EXPECT().Database().Return(api)
+	api.EXPECT().BeginContext(gomock.Any()).Return(nil)
+	// the delta table is not created and every delta is pending
+	client.EXPECT().SelectContext(gomock.Any(), &tableExistsMatcher{false},
+		fmt.Sprintf(database.TableExistenceQueryFormat, schema, DeltaTableName)).Return(nil)
+	api.EXPECT().Rollback().Return(nil)
+	connection.EXPECT().Close().Return(nil)
+
+	var out strings.Builder
+	actual := execute(WithDryRun(context.Background(), &out), database.InstanceConfig{
+		DeltaLockMaxTries:     1,
+		DeltaLockMinimumCycle: 1,
+		DeltaLockMaxCycle:     2,
+	}, connection, schema, deltas)
+	assert.NoError(actual)
+	assert.Equal("-- create delta table\n"+CreateDeltaTableSQL+"\n"+
+		"-- apply delta 1 create_a\nCREATE TABLE a (id int);\n", out.String())
+}
+
+func TestExecute_DryRun_UpgradesTable(t *testing.T) {
+	assert := assert1.New(t)
+	connection, client, api, _, _ := GetMocks(t)
+	deltas := []*Delta{{ID: 1, Name: "create_a", Script: "CREATE TABLE a (id int);"}}
+	schema := generator.String(32)
+	connection.EXPECT().Database().Return(api)
+	api.EXPECT().BeginContext(gomock.Any()).Return(nil)
+	client.EXPECT().SelectContext(gomock.Any(), &tableExistsMatcher{true},
+		fmt.Sprintf(database.TableExistenceQueryFormat, schema, DeltaTableName)).Return(nil)
+	// the upgrades are written rather than executed
+	expectColumnsExist(client, schema, false)
+	api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(dberrors.NewNotFoundError())
+	api.EXPECT().Rollback().Return(nil)
+	connection.EXPECT().Close().Return(nil)
+
+	var out strings.Builder
+	actual := execute(WithDryRun(context.Background(), &out), database.InstanceConfig{
+		DeltaLockMaxTries:     1,
+		DeltaLockMinimumCycle: 1,
+		DeltaLockMaxCycle:     2,
+	}, connection, schema, deltas)
+	assert.NoError(actual)
+	assert.Equal("-- upgrade delta table\n"+AddChecksumColumnSQL+"\n"+
+		"-- upgrade delta table\n"+AddStatusColumnsSQL+"\n"+
+		"-- apply delta 1 create_a\nCREATE TABLE a (id int);\n", out.String())
+}
+
+func TestExecuteDelta_Migrate(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	api := database.NewMockAPI(ctrl)
+	var migrated bool
+	delta := &Delta{
+		ID:   generator.Int(),
+		Name: generator.String(32),
+		Migrate: func(_ context.Context, db database.API) errors.TracerError {
+			assert.Equal(api, db)
+			migrated = true
+			return nil
+		},
+	}
+	api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), DeltaMeta.ID.Equal(delta.ID)).
+		Return(dberrors.NewNotFoundError()).Times(2)
+	var out strings.Builder
+	assert.NoError(ExecuteDeltaContext(WithDryRun(context.Background(), &out), api, delta))
+	assert.Equal(fmt.Sprintf("-- apply delta %d %s\n-- go migration\n", delta.ID, delta.Name), out.String())
+	assert.False(migrated)
+
+	// no script is executed for a delta without one
+	api.EXPECT().CreateContext(gomock.Any(), &DeltaRecord{ID: delta.ID, Name: delta.Name,
+		Checksum: delta.Checksum(), Status: Applied}).Return(nil)
+	assert.NoError(ExecuteDelta(api, delta))
+	assert.True(migrated)
+}
@@ -0,0 +1,132 @@
+package deltas
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+)
+
+const downDirection = "down"
+
+// deltaFileName matches files such as 0001_create_users.up.sql, capturing the
+// ID, name and direction
+var deltaFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+var registered = struct {
+	mutex  sync.Mutex
+	deltas map[int]*Delta
+}{deltas: make(map[int]*Delta)}
+
+// Register a Go-coded delta to be returned by Load along with the deltas read
+// from files. This is intended to be called from init, if Register is called
+// twice with the same ID it panics.
+func Register(delta *Delta) {
+	registered.mutex.Lock()
+	defer registered.mutex.Unlock()
+	if existing, ok := registered.deltas[delta.ID]; ok {
+		panic(fmt.Sprintf("deltas: Register called twice for ID %d (%s and %s)",
+			delta.ID, existing.Name, delta.Name))
+	}
+	registered.deltas[delta.ID] = delta
+}
+
+// Load the deltas in the directory dir of fsys, such as an embed.FS, and the
+// registered Go-coded deltas in ascending ID order. Files must be named
+// <id>_<name>.up.sql with an optional <id>_<name>.down.sql holding the Down
+// script, files without the .sql extension are ignored. IDs must be unique and
+// contiguous.
+func Load(fsys fs.FS, dir string) ([]*Delta, errors.TracerError) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if nil != err {
+		return nil, errors.Wrap(err)
+	}
+	byID := make(map[int]*Delta)
+	downs := make(map[int]*Delta)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		matches := deltaFileName.FindStringSubmatch(entry.Name())
+		if nil == matches {
+			return nil, errors.Newf("delta file %s is not named <id>_<name>.up.sql or <id>_<name>.down.sql",
+				entry.Name())
+		}
+		id, err := strconv.Atoi(matches[1])
+		if nil != err {
+			return nil, errors.Wrap(err)
+		}
+		script, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if nil != err {
+			return nil, errors.Wrap(err)
+		}
+		if err = add(byID, downs, id, matches[2], matches[3], string(script)); nil != err {
+			return nil, errors.Wrap(err)
+		}
+	}
+	if err = merge(byID, downs); nil != err {
+		return nil, errors.Wrap(err)
+	}
+	return sorted(byID)
+}
+
+// add the script of a delta file to byID or downs
+func add(byID, downs map[int]*Delta, id int, name, direction, script string) error {
+	if direction == downDirection {
+		if existing, ok := downs[id]; ok {
+			return errors.Newf("delta %d has down scripts named both %s and %s", id, existing.Name, name)
+		}
+		downs[id] = &Delta{ID: id, Name: name, Down: script}
+		return nil
+	}
+	if existing, ok := byID[id]; ok {
+		return errors.Newf("delta %d is used by both %s and %s", id, existing.Name, name)
+	}
+	byID[id] = &Delta{ID: id, Name: name, Script: script}
+	return nil
+}
+
+// merge the down scripts and the registered deltas with the deltas in byID
+func merge(byID, downs map[int]*Delta) error {
+	for id, down := range downs {
+		delta, ok := byID[id]
+		if !ok {
+			return errors.Newf("delta %d %s has a down script but no up script", id, down.Name)
+		}
+		if delta.Name != down.Name {
+			return errors.Newf("delta %d is named both %s and %s", id, delta.Name, down.Name)
+		}
+		delta.Down = down.Down
+	}
+	registered.mutex.Lock()
+	defer registered.mutex.Unlock()
+	for id, delta := range registered.deltas {
+		if existing, ok := byID[id]; ok {
+			return errors.Newf("delta %d is used by both %s and registered delta %s", id,
+				existing.Name, delta.Name)
+		}
+		byID[id] = delta
+	}
+	return nil
+}
+
+// sorted deltas in byID, which must have contiguous IDs
+func sorted(byID map[int]*Delta) ([]*Delta, errors.TracerError) {
+	deltas := make([]*Delta, 0, len(byID))
+	for _, delta := range byID {
+		deltas = append(deltas, delta)
+	}
+	slices.SortFunc(deltas, func(a, b *Delta) int { return a.ID - b.ID })
+	for i := 1; i < len(deltas); i++ {
+		if deltas[i].ID != deltas[i-1].ID+1 {
+			return nil, errors.Newf("delta IDs are not contiguous, %d is followed by %d",
+				deltas[i-1].ID, deltas[i].ID)
+		}
+	}
+	return deltas, nil
+}
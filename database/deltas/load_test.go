@@ -0,0 +1,102 @@
+package deltas
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+func sqlFile(script string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(script)}
+}
+
+func TestLoad(t *testing.T) {
+	assert := assert1.New(t)
+	fsys := fstest.MapFS{
+		"sql/0001_create_a.up.sql":   sqlFile("CREATE TABLE a (id int);"),
+		"sql/0001_create_a.down.sql": sqlFile("DROP TABLE a;"),
+		"sql/0002_create_b.up.sql":   sqlFile("CREATE TABLE b (id int);"),
+		"sql/README.md":              sqlFile("not a delta"),
+		"sql/nested/0009_x.up.sql":   sqlFile("ignored"),
+	}
+	migrate := func(context.Context, database.API) errors.TracerError { return nil }
+	Register(&Delta{ID: 3, Name: "backfill_b", Migrate: migrate})
+	t.Cleanup(func() { delete(registered.deltas, 3) })
+
+	deltas, err := Load(fsys, "sql")
+	assert.NoError(err)
+	if assert.Len(deltas, 3) {
+		assert.Equal(&Delta{ID: 1, Name: "create_a", Script: "CREATE TABLE a (id int);", Down: "DROP TABLE a;"},
+			deltas[0])
+		assert.Equal(&Delta{ID: 2, Name: "create_b", Script: "CREATE TABLE b (id int);"}, deltas[1])
+		assert.Equal(3, deltas[2].ID)
+		assert.NotNil(deltas[2].Migrate)
+	}
+}
+
+func TestRegister_Duplicate(t *testing.T) {
+	assert := assert1.New(t)
+	migrate := func(context.Context, database.API) errors.TracerError { return nil }
+	Register(&Delta{ID: 4, Name: "backfill_a", Migrate: migrate})
+	t.Cleanup(func() { delete(registered.deltas, 4) })
+	assert.PanicsWithValue("deltas: Register called twice for ID 4 (backfill_a and backfill_b)", func() {
+		Register(&Delta{ID: 4, Name: "backfill_b", Migrate: migrate})
+	})
+	assert.Equal("backfill_a", registered.deltas[4].Name)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		fsys     fstest.MapFS
+		expected string
+	}{
+		{
+			name:     "bad name",
+			fsys:     fstest.MapFS{"sql/create_a.sql": sqlFile("")},
+			expected: "delta file create_a.sql is not named <id>_<name>.up.sql or <id>_<name>.down.sql",
+		},
+		{
+			name: "duplicate",
+			fsys: fstest.MapFS{
+				"sql/0001_create_a.up.sql": sqlFile(""),
+				"sql/1_create_b.up.sql":    sqlFile(""),
+			},
+			expected: "delta 1 is used by both create_a and create_b",
+		},
+		{
+			name: "not contiguous",
+			fsys: fstest.MapFS{
+				"sql/0001_create_a.up.sql": sqlFile(""),
+				"sql/0003_create_c.up.sql": sqlFile(""),
+			},
+			expected: "delta IDs are not contiguous, 1 is followed by 3",
+		},
+		{
+			name:     "down only",
+			fsys:     fstest.MapFS{"sql/0001_create_a.down.sql": sqlFile("")},
+			expected: "delta 1 create_a has a down script but no up script",
+		},
+		{
+			name: "names differ",
+			fsys: fstest.MapFS{
+				"sql/0001_create_a.up.sql":   sqlFile(""),
+				"sql/0001_create_b.down.sql": sqlFile(""),
+			},
+			expected: "delta 1 is named both create_a and create_b",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Load(tc.fsys, "sql")
+			assert1.EqualError(t, err, tc.expected)
+		})
+	}
+
+	_, err := Load(fstest.MapFS{}, "missing")
+	assert1.Error(t, err)
+}
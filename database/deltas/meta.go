@@ -6,6 +6,9 @@ type deltaMeta struct {
 	alias    string
 	ID       qb.TableField
 	Name     qb.TableField
+	Checksum qb.TableField
+	Status   qb.TableField
+	Message  qb.TableField
 	Created  qb.TableField
 	Modified qb.TableField
 
@@ -36,6 +39,9 @@ func (p *deltaMeta) ReadColumns() []qb.TableField {
 	return []qb.TableField{
 		p.ID,
 		p.Name,
+		p.Checksum,
+		p.Status,
+		p.Message,
 		p.Created,
 		p.Modified,
 	}
@@ -45,6 +51,9 @@ func (p *deltaMeta) WriteColumns() []qb.TableField {
 	return []qb.TableField{
 		p.ID,
 		p.Name,
+		p.Checksum,
+		p.Status,
+		p.Message,
 	}
 }
 
@@ -53,6 +62,9 @@ func (p *deltaMeta) Alias(alias string) *deltaMeta {
 		alias:    alias,
 		ID:       qb.TableField{Name: "id", Table: alias},
 		Name:     qb.TableField{Name: "name", Table: alias},
+		Checksum: qb.TableField{Name: "checksum", Table: alias},
+		Status:   qb.TableField{Name: "status", Table: alias},
+		Message:  qb.TableField{Name: "message", Table: alias},
 		Created:  qb.TableField{Name: "created", Table: alias},
 		Modified: qb.TableField{Name: "modified", Table: alias},
 
@@ -0,0 +1,72 @@
+package deltas
+
+import (
+	"context"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database"
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/log"
+)
+
+// Resolution of a Failed or InProgress delta by Repair
+type Resolution int
+
+const (
+	// Retry the delta, its record is removed so that it is executed again. Use
+	// this when none of the delta was applied or the applied part was reverted.
+	Retry Resolution = iota
+	// MarkApplied records the delta as applied without executing it. Use this
+	// when the remainder of the delta was applied by hand.
+	MarkApplied
+)
+
+// Repair the Failed or InProgress delta with the passed ID once an operator has
+// inspected the database, so that ExecuteEach continues past it.
+func Repair(config database.InstanceConfig, schema string, id int, resolution Resolution) errors.TracerError {
+	return RepairContext(context.Background(), config, schema, id, resolution)
+}
+
+// RepairContext is Repair with a context that is passed to the driver for every
+// statement.
+func RepairContext(ctx context.Context, config database.InstanceConfig, schema string, id int,
+	resolution Resolution) errors.TracerError {
+	mutex.Lock()
+	defer mutex.Unlock()
+	connection, err := database.Connect(&config)
+	if nil != err {
+		return errors.Wrap(err)
+	}
+	return repair(ctx, config, connection, schema, id, resolution)
+}
+
+func repair(ctx context.Context, config database.InstanceConfig, connection database.Connection,
+	schema string, id int, resolution Resolution) errors.TracerError {
+	return run(ctx, config, connection, schema, func(db database.API, tracked bool) errors.TracerError {
+		if !tracked {
+			return errors.Newf("delta %d has not been executed", id)
+		}
+		existing := new(DeltaRecord)
+		if err := db.ReadOneWhereContext(ctx, existing, DeltaMeta.ID.Equal(id)); nil != err {
+			return err
+		}
+		if !existing.incomplete() {
+			return errors.Newf("delta %d %s is %s and does not need to be repaired", existing.ID,
+				existing.Name, existing.Status)
+		}
+		switch resolution {
+		case Retry:
+			log.Infof("removing %s delta %d %s so that it is retried", existing.Status, existing.ID,
+				existing.Name)
+			return db.DeleteContext(ctx, existing)
+		case MarkApplied:
+			log.Infof("marking %s delta %d %s as applied", existing.Status, existing.ID, existing.Name)
+			_, err := db.UpdateWhereContext(ctx, &DeltaRecord{}, DeltaMeta.ID.Equal(id),
+				qb.FieldValue{Field: DeltaMeta.Status, Value: Applied},
+				qb.FieldValue{Field: DeltaMeta.Message, Value: ""})
+			return err
+		default:
+			return errors.Newf("unknown resolution %d", resolution)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package deltas
+
+import (
+	"context"
+	"testing"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	assert1 "github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRepair(t *testing.T) {
+	failed := DeltaRecord{ID: 1, Name: "create_a", Status: Failed, Message: "table a exists"}
+	tests := []struct {
+		name       string
+		existing   DeltaRecord
+		resolution Resolution
+		expected   string
+	}{
+		{name: "retry", existing: failed, resolution: Retry},
+		{name: "mark applied", existing: failed, resolution: MarkApplied},
+		{
+			name:       "applied",
+			existing:   DeltaRecord{ID: 1, Name: "create_a", Status: Applied},
+			resolution: Retry,
+			expected:   "delta 1 create_a is applied and does not need to be repaired",
+		},
+		{name: "unknown resolution", existing: failed, resolution: -1, expected: "unknown resolution -1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert1.New(t)
+			connection, client, api, _, _ := GetMocks(t)
+			schema := "schema"
+			expectRun(connection, client, api, schema)
+			api.EXPECT().ReadOneWhereContext(gomock.Any(), gomock.Any(), DeltaMeta.ID.Equal(1)).
+				SetArg(1, tc.existing).Return(nil)
+			switch {
+			case tc.expected != "":
+				api.EXPECT().Rollback().Return(nil)
+			case tc.resolution == Retry:
+				api.EXPECT().DeleteContext(gomock.Any(), &tc.existing).Return(nil)
+				api.EXPECT().Commit().Return(nil)
+			default:
+				api.EXPECT().UpdateWhereContext(gomock.Any(), &DeltaRecord{}, DeltaMeta.ID.Equal(1),
+					qb.FieldValue{Field: DeltaMeta.Status, Value: Applied},
+					qb.FieldValue{Field: DeltaMeta.Message, Value: ""}).Return(int64(1), nil)
+				api.EXPECT().Commit().Return(nil)
+			}
+			err := repair(context.Background(), rollbackConfig, connection, schema, 1, tc.resolution)
+			if tc.expected == "" {
+				assert.NoError(err)
+			} else {
+				assert.EqualError(err, tc.expected)
+			}
+		})
+	}
+}
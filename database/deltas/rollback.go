@@ -0,0 +1,108 @@
+package deltas
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database"
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/database/utility"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/log"
+)
+
+// Rollback the applied deltas with an ID greater than toID by executing their
+// Down scripts in descending ID order. Every delta to be rolled back must be in
+// the passed deltas, have a Down script and be unchanged since it was applied,
+// otherwise nothing is rolled back.
+//
+// NOTE: See Execute for the transactional DDL requirements.
+func Rollback(config database.InstanceConfig, schema string, deltas []*Delta, toID int) errors.TracerError {
+	return RollbackContext(context.Background(), config, schema, deltas, toID)
+}
+
+// RollbackContext is Rollback with a context that is passed to the driver for
+// every statement.
+func RollbackContext(ctx context.Context, config database.InstanceConfig, schema string,
+	deltas []*Delta, toID int) errors.TracerError {
+	mutex.Lock()
+	defer mutex.Unlock()
+	config.Connection = utility.SetMultiStatement(config.Connection)
+	connection, err := database.Connect(&config)
+	if nil != err {
+		return errors.Wrap(err)
+	}
+	return rollback(ctx, config, connection, schema, deltas, toID)
+}
+
+func rollback(ctx context.Context, config database.InstanceConfig, connection database.Connection,
+	schema string, deltas []*Delta, toID int) errors.TracerError {
+	log.Infof("rolling back deltas after %d on %s", toID, schema)
+	return run(ctx, config, connection, schema, func(db database.API, tracked bool) errors.TracerError {
+		if !tracked {
+			return nil
+		}
+		records, err := applied(ctx, db)
+		if nil != err {
+			return err
+		}
+		byID := make(map[int]*Delta, len(deltas))
+		for _, delta := range deltas {
+			byID[delta.ID] = delta
+		}
+		for _, existing := range records {
+			if existing.ID <= toID {
+				break
+			}
+			if err = revert(ctx, db, byID[existing.ID], existing); nil != err {
+				log.Errorf("rolling back deltas: error encountered reverting delta %d %s: %s",
+					existing.ID, existing.Name, err)
+				return err
+			}
+		}
+		log.Infof("all deltas after %d rolled back", toID)
+		return nil
+	})
+}
+
+// applied deltas in descending ID order
+func applied(ctx context.Context, db database.API) ([]*DeltaRecord, errors.TracerError) {
+	var records []*DeltaRecord
+	err := db.GetTransaction().SelectContext(ctx, &records,
+		qb.Select(DeltaMeta.AllColumns()).From(DeltaMeta).OrderBy(DeltaMeta.ID, qb.Descending), nil)
+	return records, err
+}
+
+// revert the applied delta by executing its Down script and removing its record
+func revert(ctx context.Context, db database.API, delta *Delta, existing *DeltaRecord) errors.TracerError {
+	if nil == delta {
+		return errors.Newf("delta %d %s is applied but was not passed and can not be rolled back",
+			existing.ID, existing.Name)
+	}
+	if existing.incomplete() {
+		return NewIncompleteDeltaError(existing)
+	}
+	if delta.Down == "" && nil == delta.Revert {
+		return errors.Newf("delta %d %s does not have a down script", delta.ID, delta.Name)
+	}
+	if existing.Checksum != "" && existing.Checksum != delta.Checksum() {
+		return NewChecksumMismatchError(delta, existing.Checksum)
+	}
+	if w, dry := dryRun(ctx); dry {
+		_, err := fmt.Fprintf(w, "-- roll back delta %d %s\n%s\n", delta.ID, delta.Name,
+			describe(goStep(delta.Revert), delta.Down))
+		return errors.Wrap(err)
+	}
+	if nil != delta.Revert {
+		if err := delta.Revert(ctx, db); nil != err {
+			return err
+		}
+	}
+	if delta.Down != "" {
+		if _, err := db.GetTransaction().Implementation().ExecContext(ctx, delta.Down); nil != err {
+			return errors.Wrap(err)
+		}
+	}
+	log.Infof("successfully rolled back delta %d %s", delta.ID, delta.Name)
+	return db.DeleteContext(ctx, existing)
+}
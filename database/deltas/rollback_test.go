@@ -0,0 +1,141 @@
+package deltas
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database"
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	assert1 "github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	rollbackConfig = database.InstanceConfig{
+		DeltaLockMaxTries:     1,
+		DeltaLockMinimumCycle: 1,
+		DeltaLockMaxCycle:     2,
+	}
+	appliedQuery = qb.Select(DeltaMeta.AllColumns()).From(DeltaMeta).OrderBy(DeltaMeta.ID, qb.Descending)
+)
+
+func testDeltas() []*Delta {
+	return []*Delta{
+		{ID: 1, Name: "create_a", Script: "CREATE TABLE a (id int);", Down: "DROP TABLE a;"},
+		{ID: 2, Name: "create_b", Script: "CREATE TABLE b (id int);", Down: "DROP TABLE b;"},
+		{ID: 3, Name: "create_c", Script: "CREATE TABLE c (id int);", Down: "DROP TABLE c;"},
+	}
+}
+
+func appliedRecords(deltas ...*Delta) []*DeltaRecord {
+	records := make([]*DeltaRecord, len(deltas))
+	for i, delta := range deltas {
+		records[len(deltas)-1-i] = &DeltaRecord{ID: delta.ID, Name: delta.Name, Checksum: delta.Checksum()}
+	}
+	return records
+}
+
+func TestRollback(t *testing.T) {
+	assert := assert1.New(t)
+	connection, client, api, tx, txImp := GetMocks(t)
+	deltas := testDeltas()
+	schema := "schema"
+	expectRun(connection, client, api, schema)
+	records := appliedRecords(deltas...)
+	tx.EXPECT().SelectContext(gomock.Any(), gomock.Any(), appliedQuery, nil).SetArg(1, records).Return(nil)
+	gomock.InOrder(
+		txImp.EXPECT().ExecContext(gomock.Any(), "DROP TABLE c;").Return(nil, nil),
+		api.EXPECT().DeleteContext(gomock.Any(), records[0]).Return(nil),
+		txImp.EXPECT().ExecContext(gomock.Any(), "DROP TABLE b;").Return(nil, nil),
+		api.EXPECT().DeleteContext(gomock.Any(), records[1]).Return(nil),
+	)
+	api.EXPECT().Commit().Return(nil)
+	assert.NoError(rollback(context.Background(), rollbackConfig, connection, schema, deltas, 1))
+}
+
+func TestRollback_DryRun(t *testing.T) {
+	assert := assert1.New(t)
+	connection, client, api, tx, _ := GetMocks(t)
+	deltas := testDeltas()
+	schema := "schema"
+	expectRun(connection, client, api, schema)
+	tx.EXPECT().SelectContext(gomock.Any(), gomock.Any(), appliedQuery, nil).
+		SetArg(1, appliedRecords(deltas...)).Return(nil)
+	api.EXPECT().Rollback().Return(nil)
+	var out strings.Builder
+	assert.NoError(rollback(WithDryRun(context.Background(), &out), rollbackConfig, connection, schema,
+		deltas, 2))
+	assert.Equal("-- roll back delta 3 create_c\nDROP TABLE c;\n", out.String())
+}
+
+func TestRollback_Errors(t *testing.T) {
+	deltas := testDeltas()
+	drifted := *deltas[2]
+	drifted.Script = "CREATE TABLE c (id bigint);"
+	noDown := *deltas[2]
+	noDown.Down = ""
+	tests := []struct {
+		name     string
+		deltas   []*Delta
+		expected string
+	}{
+		{
+			name:     "unknown",
+			deltas:   deltas[:2],
+			expected: "delta 3 create_c is applied but was not passed and can not be rolled back",
+		},
+		{
+			name:     "no down",
+			deltas:   []*Delta{deltas[0], deltas[1], &noDown},
+			expected: "delta 3 create_c does not have a down script",
+		},
+		{
+			name:     "drifted",
+			deltas:   []*Delta{deltas[0], deltas[1], &drifted},
+			expected: "delta 3 create_c has changed since it was applied",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert1.New(t)
+			connection, client, api, tx, _ := GetMocks(t)
+			schema := "schema"
+			expectRun(connection, client, api, schema)
+			tx.EXPECT().SelectContext(gomock.Any(), gomock.Any(), appliedQuery, nil).
+				SetArg(1, appliedRecords(deltas...)).Return(nil)
+			api.EXPECT().Rollback().Return(nil)
+			err := rollback(context.Background(), rollbackConfig, connection, schema, tc.deltas, 0)
+			if assert.Error(err) {
+				assert.Contains(err.Error(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestRollback_Revert(t *testing.T) {
+	assert := assert1.New(t)
+	connection, client, api, tx, txImp := GetMocks(t)
+	deltas := testDeltas()
+	var calls []string
+	deltas[2].Revert = func(context.Context, database.API) errors.TracerError {
+		calls = append(calls, "revert")
+		return nil
+	}
+	schema := "schema"
+	expectRun(connection, client, api, schema)
+	records := appliedRecords(deltas...)
+	tx.EXPECT().SelectContext(gomock.Any(), gomock.Any(), appliedQuery, nil).SetArg(1, records).Return(nil)
+	// Revert is called before the down script
+	txImp.EXPECT().ExecContext(gomock.Any(), "DROP TABLE c;").DoAndReturn(
+		func(context.Context, string, ...any) (sql.Result, error) {
+			calls = append(calls, "down")
+			return nil, nil
+		})
+	api.EXPECT().DeleteContext(gomock.Any(), records[0]).Return(nil)
+	api.EXPECT().Commit().Return(nil)
+	assert.NoError(rollback(context.Background(), rollbackConfig, connection, schema, deltas, 2))
+	assert.Equal([]string{"revert", "down"}, calls)
+}
@@ -0,0 +1,107 @@
+package deltas
+
+import (
+	"context"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+)
+
+// State of a delta in the database
+type State string
+
+const (
+	// Applied deltas have been executed and are unchanged since
+	Applied State = "applied"
+	// Pending deltas have not been executed
+	Pending State = "pending"
+	// Drifted deltas have been executed but their script has changed since
+	Drifted State = "drifted"
+	// Unknown deltas have been executed but were not passed
+	Unknown State = "unknown"
+	// InProgress deltas were started by ExecuteEach and have not completed, if
+	// ExecuteEach is not running the delta was interrupted and must be repaired
+	InProgress State = "in_progress"
+	// Failed deltas were started by ExecuteEach and returned an error, they must
+	// be repaired
+	Failed State = "failed"
+)
+
+// DeltaStatus is the state of a single delta
+type DeltaStatus struct {
+	ID    int
+	Name  string
+	State State
+	// Applied is when the delta was executed, zero for pending deltas
+	Applied time.Time
+}
+
+// Status of the passed deltas and of any applied deltas that were not passed in
+// ascending ID order. The database is not modified, a delta table created by an
+// earlier version is not upgraded.
+func Status(config database.InstanceConfig, schema string, deltas []*Delta) ([]*DeltaStatus, errors.TracerError) {
+	return StatusContext(context.Background(), config, schema, deltas)
+}
+
+// StatusContext is Status with a context that is passed to the driver for every
+// statement.
+func StatusContext(ctx context.Context, config database.InstanceConfig, schema string,
+	deltas []*Delta) ([]*DeltaStatus, errors.TracerError) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	connection, err := database.Connect(&config)
+	if nil != err {
+		return nil, errors.Wrap(err)
+	}
+	return status(ctx, config, connection, schema, deltas)
+}
+
+func status(ctx context.Context, config database.InstanceConfig, connection database.Connection,
+	schema string, deltas []*Delta) ([]*DeltaStatus, errors.TracerError) {
+	var statuses []*DeltaStatus
+	err := run(WithDryRun(ctx, io.Discard), config, connection, schema,
+		func(db database.API, tracked bool) errors.TracerError {
+			var records []*DeltaRecord
+			if tracked {
+				var err errors.TracerError
+				if records, err = applied(ctx, db); nil != err {
+					return err
+				}
+			}
+			statuses = compare(deltas, records)
+			return nil
+		})
+	return statuses, err
+}
+
+// compare the deltas with the records of applied deltas
+func compare(deltas []*Delta, records []*DeltaRecord) []*DeltaStatus {
+	byID := make(map[int]*DeltaRecord, len(records))
+	for _, existing := range records {
+		byID[existing.ID] = existing
+	}
+	statuses := make([]*DeltaStatus, 0, len(deltas)+len(records))
+	for _, delta := range deltas {
+		status := &DeltaStatus{ID: delta.ID, Name: delta.Name, State: Pending}
+		if existing, ok := byID[delta.ID]; ok {
+			delete(byID, delta.ID)
+			status.Applied = existing.Created
+			status.State = Applied
+			if existing.incomplete() {
+				status.State = existing.Status
+			} else if existing.Checksum != "" && existing.Checksum != delta.Checksum() {
+				status.State = Drifted
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	for _, existing := range byID {
+		statuses = append(statuses, &DeltaStatus{ID: existing.ID, Name: existing.Name, State: Unknown,
+			Applied: existing.Created})
+	}
+	slices.SortStableFunc(statuses, func(a, b *DeltaStatus) int { return a.ID - b.ID })
+	return statuses
+}
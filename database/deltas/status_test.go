@@ -0,0 +1,41 @@
+package deltas
+
+import (
+	"context"
+	"testing"
+
+	assert1 "github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestStatus(t *testing.T) {
+	assert := assert1.New(t)
+	connection, client, api, tx, _ := GetMocks(t)
+	deltas := testDeltas()
+	schema := "schema"
+	expectRun(connection, client, api, schema)
+	records := appliedRecords(deltas[0], deltas[1])
+	records = append([]*DeltaRecord{{ID: 4, Name: "removed", Checksum: "removed"}}, records...)
+	records[1].Checksum = "drifted"
+	records[2].Status = Failed
+	tx.EXPECT().SelectContext(gomock.Any(), gomock.Any(), appliedQuery, nil).SetArg(1, records).Return(nil)
+	// the transaction is always rolled back
+	api.EXPECT().Rollback().Return(nil)
+
+	statuses, err := status(context.Background(), rollbackConfig, connection, schema, deltas)
+	assert.NoError(err)
+	assert.Equal([]*DeltaStatus{
+		{ID: 1, Name: "create_a", State: Failed},
+		{ID: 2, Name: "create_b", State: Drifted},
+		{ID: 3, Name: "create_c", State: Pending},
+		{ID: 4, Name: "removed", State: Unknown},
+	}, statuses)
+}
+
+func TestCompare_NotTracked(t *testing.T) {
+	statuses := compare(testDeltas(), nil)
+	assert1.Len(t, statuses, 3)
+	for _, status := range statuses {
+		assert1.Equal(t, Pending, status.State)
+	}
+}
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
@@ -30,6 +31,9 @@ const (
 	Delete = "DELETE"
 	// Update indicates an UPDATE statement triggered the error
 	Update = "UPDATE"
+	// Savepoint indicates a SAVEPOINT, RELEASE SAVEPOINT or ROLLBACK TO SAVEPOINT
+	// statement triggered the error
+	Savepoint = "SAVEPOINT"
 )
 
 const (
@@ -37,9 +41,20 @@ const (
 	invalidForeignKeyMsg      = "invalid reference"
 	dataTooLongMsg            = "data too long"
 	duplicateRecordMsg        = "already exists"
+	checkConstraintMsg        = "check constraint violation"
+	notNullMsg                = "required value missing"
+	deadlockMsg               = "deadlock detected"
+	lockWaitTimeoutMsg        = "lock wait timeout exceeded"
+	canceledMsg               = "query canceled"
+	staleRecordMsg            = "stale record"
+	staleRecordCause          = "record was modified or deleted since it was read"
 	mysqlDuplicateEntry       = 1062
 	mysqlDataTooLong          = 1406
 	mysqlInvalidForeignKey    = 1452
+	mysqlNotNull              = 1048
+	mysqlCheckConstraint      = 3819
+	mysqlDeadlock             = 1213
+	mysqlLockWaitTimeout      = 1205
 	primaryKeyConstraintCheck = "for key 'PRIMARY'"
 )
 
@@ -50,6 +65,34 @@ func IsNotFoundError(err error) bool {
 	return errors.As(err, &dst)
 }
 
+// IsRetryableError returns a boolean indicating that the passed error (can be nil)
+// is a *DeadlockError or *LockWaitTimeoutError and the transaction that caused it
+// can be retried
+func IsRetryableError(err error) bool {
+	var (
+		deadlock *DeadlockError
+		timeout  *LockWaitTimeoutError
+	)
+	return errors.As(err, &deadlock) || errors.As(err, &timeout)
+}
+
+// IsDuplicateError returns a boolean indicating that the passed error (can be
+// nil) is a *DuplicateRecordError or *UniqueConstraintError
+func IsDuplicateError(err error) bool {
+	var (
+		duplicate *DuplicateRecordError
+		unique    *UniqueConstraintError
+	)
+	return errors.As(err, &duplicate) || errors.As(err, &unique)
+}
+
+// IsStaleRecordError returns a boolean indicating that the passed error (can be
+// nil) is of type *StaleRecordError
+func IsStaleRecordError(err error) bool {
+	var dst *StaleRecordError
+	return errors.As(err, &dst)
+}
+
 // ConnectionError  is returned when unable to connect to database
 type ConnectionError struct {
 	err   error
@@ -71,16 +114,43 @@ func NewDatabaseConnectionError(err error) errors.TracerError {
 }
 
 // TranslateError converts a mysql or other obtuse errors into discrete explicit errors
+// using the translators registered for each dialect. Use TranslateErrorFor when
+// the dialect of the error is known.
 func TranslateError(err error, action SQLQueryType, stmt string) errors.TracerError {
+	return TranslateErrorFor(nil, err, action, stmt)
+}
+
+// TranslateErrorFor converts an error returned by the driver of the passed
+// dialect using the translator registered for it. The translators of every
+// dialect are tried when the dialect is nil or has no translator registered.
+func TranslateErrorFor(dialect qb.Dialect, err error, action SQLQueryType, stmt string) errors.TracerError {
 	if nil == err {
 		return nil
 	}
 	if sql.ErrNoRows == err {
 		return NewNotFoundError()
 	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return NewCanceledError(action, stmt, err)
+	}
+	translators := registeredTranslators()
+	if nil != dialect {
+		if translator, ok := GetTranslator(dialect); ok {
+			translators = []Translator{translator}
+		}
+	}
+	for _, translator := range translators {
+		if translated := translator(err, action, stmt); nil != translated {
+			return translated
+		}
+	}
+	return NewSystemError(action, stmt, err)
+}
+
+func translateMySQL(err error, action SQLQueryType, stmt string) errors.TracerError {
 	driverErr, ok := err.(*mysql.MySQLError)
 	if !ok {
-		return NewSystemError(action, stmt, err)
+		return nil
 	}
 	switch driverErr.Number {
 	// Duplicate primary key
@@ -95,6 +165,18 @@ func TranslateError(err error, action SQLQueryType, stmt string) errors.TracerEr
 	// Invalid foreign key
 	case mysqlInvalidForeignKey:
 		return NewInvalidForeignKeyError(action, stmt, err)
+	// Column cannot be null
+	case mysqlNotNull:
+		return NewNotNullError(action, stmt, err)
+	// Check constraint is violated
+	case mysqlCheckConstraint:
+		return NewCheckConstraintError(action, stmt, err)
+	// Deadlock found when trying to get lock
+	case mysqlDeadlock:
+		return NewDeadlockError(action, stmt, err)
+	// Lock wait timeout exceeded
+	case mysqlLockWaitTimeout:
+		return NewLockWaitTimeoutError(action, stmt, err)
 	default:
 		return NewExecutionError(action, stmt, err)
 	}
@@ -292,6 +374,136 @@ func NewInvalidForeignKeyError(action SQLQueryType, stmt string, err error) erro
 	}
 }
 
+// CheckConstraintError is returned when a row violates a check constraint
+type CheckConstraintError struct {
+	SQLExecutionError
+}
+
+// NewCheckConstraintError wrapping the passed error with references to the passed
+// sql and action.
+func NewCheckConstraintError(action SQLQueryType, stmt string, err error) errors.TracerError {
+	return &CheckConstraintError{
+		SQLExecutionError{ErrMsg: err.Error(),
+			ReferenceID: generator.ID(dbErrPrefix),
+			Action:      action,
+			message:     checkConstraintMsg,
+			Stmt:        stmt,
+			trace:       errors.GetStackTrace(),
+		},
+	}
+}
+
+// NotNullError is returned when a NULL is written to a NOT NULL column
+type NotNullError struct {
+	SQLExecutionError
+}
+
+// NewNotNullError wrapping the passed error with references to the passed
+// sql and action.
+func NewNotNullError(action SQLQueryType, stmt string, err error) errors.TracerError {
+	return &NotNullError{
+		SQLExecutionError{ErrMsg: err.Error(),
+			ReferenceID: generator.ID(dbErrPrefix),
+			Action:      action,
+			message:     notNullMsg,
+			Stmt:        stmt,
+			trace:       errors.GetStackTrace(),
+		},
+	}
+}
+
+// DeadlockError is returned when the database aborts the transaction to resolve
+// a deadlock, the transaction can be retried.
+type DeadlockError struct {
+	SQLExecutionError
+}
+
+// NewDeadlockError wrapping the passed error with references to the passed
+// sql and action.
+func NewDeadlockError(action SQLQueryType, stmt string, err error) errors.TracerError {
+	return &DeadlockError{
+		SQLExecutionError{ErrMsg: err.Error(),
+			ReferenceID: generator.ID(dbErrPrefix),
+			Action:      action,
+			message:     deadlockMsg,
+			Stmt:        stmt,
+			trace:       errors.GetStackTrace(),
+		},
+	}
+}
+
+// LockWaitTimeoutError is returned when a statement times out waiting on a row
+// or table lock held by another transaction.
+type LockWaitTimeoutError struct {
+	SQLExecutionError
+}
+
+// NewLockWaitTimeoutError wrapping the passed error with references to the passed
+// sql and action.
+func NewLockWaitTimeoutError(action SQLQueryType, stmt string, err error) errors.TracerError {
+	return &LockWaitTimeoutError{
+		SQLExecutionError{ErrMsg: err.Error(),
+			ReferenceID: generator.ID(dbErrPrefix),
+			Action:      action,
+			message:     lockWaitTimeoutMsg,
+			Stmt:        stmt,
+			trace:       errors.GetStackTrace(),
+		},
+	}
+}
+
+// StaleRecordError is returned when an update to a versioned record affects no
+// rows because the version read is no longer current.
+type StaleRecordError struct {
+	SQLExecutionError
+}
+
+// NewStaleRecordError with references to the passed sql and action.
+func NewStaleRecordError(action SQLQueryType, stmt string) errors.TracerError {
+	return &StaleRecordError{
+		SQLExecutionError{ErrMsg: staleRecordCause,
+			ReferenceID: generator.ID(dbErrPrefix),
+			Action:      action,
+			message:     staleRecordMsg,
+			Stmt:        stmt,
+			trace:       errors.GetStackTrace(),
+		},
+	}
+}
+
+// CanceledError is returned when a statement is abandoned because the context
+// it was executing under was canceled or its deadline was exceeded.
+type CanceledError struct {
+	SQLExecutionError
+	cause error
+}
+
+// NewCanceledError wrapping the passed context error with references to the
+// passed sql and action.
+func NewCanceledError(action SQLQueryType, stmt string, err error) errors.TracerError {
+	return &CanceledError{
+		SQLExecutionError: SQLExecutionError{ErrMsg: err.Error(),
+			ReferenceID: generator.ID(dbErrPrefix),
+			Action:      action,
+			message:     canceledMsg,
+			Stmt:        stmt,
+			trace:       errors.GetStackTrace(),
+		},
+		cause: err,
+	}
+}
+
+// Unwrap returns the context error that caused the cancellation
+func (e *CanceledError) Unwrap() error {
+	return e.cause
+}
+
+// DeadlineExceeded indicates the statement was canceled because the deadline
+// on its context passed.
+func (e *CanceledError) DeadlineExceeded() bool {
+	return errors.Is(e.cause, context.DeadlineExceeded)
+}
+
 // DatabaseToStatus translates the passed db error into a grpc Status with appropriate
 // status code
 func DatabaseToStatus(primary qb.Table, dbError error) *status.Status {
@@ -321,6 +533,28 @@ func databaseToStatus(primary qb.Table, dbError error) *status.Status {
 	case *InvalidForeignKeyError:
 		grpcStatus = status.Newf(codes.InvalidArgument, "%s %s foreign key violation: %s",
 			prefix, primary.GetName(), dbError)
+	case *CheckConstraintError:
+		grpcStatus = status.Newf(codes.InvalidArgument, "%s %s check constraint violation: %s",
+			prefix, primary.GetName(), dbError)
+	case *NotNullError:
+		grpcStatus = status.Newf(codes.InvalidArgument, "%s %s required field missing: %s",
+			prefix, primary.GetName(), dbError)
+	case *DeadlockError:
+		grpcStatus = status.Newf(codes.Aborted, "%s %s deadlock encountered: %s",
+			prefix, primary.GetName(), dbError)
+	case *StaleRecordError:
+		grpcStatus = status.Newf(codes.Aborted, "%s %s record was modified concurrently: %s",
+			prefix, primary.GetName(), dbError)
+	case *LockWaitTimeoutError:
+		grpcStatus = status.Newf(codes.Unavailable, "%s %s lock wait timeout: %s",
+			prefix, primary.GetName(), dbError)
+	case *CanceledError:
+		code := codes.Canceled
+		if dbError.(*CanceledError).DeadlineExceeded() {
+			code = codes.DeadlineExceeded
+		}
+		grpcStatus = status.Newf(code, "%s %s query canceled: %s",
+			prefix, primary.GetName(), dbError)
 	case *ValidationError:
 		grpcStatus = status.Newf(codes.InvalidArgument, "%s operation on %s had a validation error: %s",
 			prefix, primary.GetName(), dbError)
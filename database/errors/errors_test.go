@@ -1,7 +1,9 @@
 package errors
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -83,6 +85,8 @@ func TestTranslateError(t *testing.T) {
 		{err: &mysql.MySQLError{Number: mysqlInvalidForeignKey}, expected: &InvalidForeignKeyError{}},
 		{err: &mysql.MySQLError{}, expected: &SQLExecutionError{}},
 		{err: errors.New("foo"), expected: &SQLSystemError{}},
+		{err: context.Canceled, expected: &CanceledError{}},
+		{err: fmt.Errorf("read: %w", context.DeadlineExceeded), expected: &CanceledError{}},
 	}
 	for _, data := range testData {
 		assert.IsType(data.expected, TranslateError(data.err, Select, generator.String(5)))
@@ -91,7 +95,7 @@ func TestTranslateError(t *testing.T) {
 
 func Test_getLogPrefix(t *testing.T) {
 	assert := assert1.New(t)
-	expected := "[DAT.ERR.95]"
+	expected := "[DAT.ERR.99]"
 	actual := getLogPrefix(1)
 	assert.Equal(expected, actual)
 }
@@ -264,6 +268,48 @@ func TestDatabaseToApiError(t *testing.T) {
 			err:      &InvalidForeignKeyError{},
 			expected: "rpc error: code = InvalidArgument desc = [DAT.ERR.262] action foreign key violation: :  [Ref:]",
 		},
+		{
+			name:     "check constraint",
+			primary:  Action,
+			err:      &CheckConstraintError{},
+			expected: "rpc error: code = InvalidArgument desc = [DAT.ERR.262] action check constraint violation: :  [Ref:]",
+		},
+		{
+			name:     "not null",
+			primary:  Action,
+			err:      &NotNullError{},
+			expected: "rpc error: code = InvalidArgument desc = [DAT.ERR.262] action required field missing: :  [Ref:]",
+		},
+		{
+			name:     "deadlock",
+			primary:  Action,
+			err:      &DeadlockError{},
+			expected: "rpc error: code = Aborted desc = [DAT.ERR.262] action deadlock encountered: :  [Ref:]",
+		},
+		{
+			name:     "stale record",
+			primary:  Action,
+			err:      &StaleRecordError{},
+			expected: "rpc error: code = Aborted desc = [DAT.ERR.262] action record was modified concurrently: :  [Ref:]",
+		},
+		{
+			name:     "lock wait timeout",
+			primary:  Action,
+			err:      &LockWaitTimeoutError{},
+			expected: "rpc error: code = Unavailable desc = [DAT.ERR.262] action lock wait timeout: :  [Ref:]",
+		},
+		{
+			name:     "canceled",
+			primary:  Action,
+			err:      &CanceledError{cause: context.Canceled},
+			expected: "rpc error: code = Canceled desc = [DAT.ERR.262] action query canceled: :  [Ref:]",
+		},
+		{
+			name:     "deadline exceeded",
+			primary:  Action,
+			err:      &CanceledError{cause: context.DeadlineExceeded},
+			expected: "rpc error: code = DeadlineExceeded desc = [DAT.ERR.262] action query canceled: :  [Ref:]",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -412,3 +458,43 @@ func TestEqualLogError(t *testing.T) {
 		})
 	}
 }
+
+func TestNewCanceledError(t *testing.T) {
+	assert := assert1.New(t)
+	err := NewCanceledError(Select, "bar", context.DeadlineExceeded).(*CanceledError)
+	assert.True(strings.HasPrefix(err.ReferenceID, dbErrPrefix))
+	assert.Contains(err.Error(), err.message)
+	assert.True(err.DeadlineExceeded())
+	assert.True(errors.Is(err, context.DeadlineExceeded))
+	err = NewCanceledError(Select, "bar", context.Canceled).(*CanceledError)
+	assert.False(err.DeadlineExceeded())
+	assert.True(errors.Is(err, context.Canceled))
+}
+
+func TestIsRetryableError(t *testing.T) {
+	assert := assert1.New(t)
+	assert.True(IsRetryableError(NewDeadlockError(Update, "", errors.New("foo"))))
+	assert.True(IsRetryableError(NewLockWaitTimeoutError(Update, "", errors.New("foo"))))
+	assert.False(IsRetryableError(NewExecutionError(Update, "", errors.New("foo"))))
+	assert.False(IsRetryableError(nil))
+}
+
+func TestIsDuplicateError(t *testing.T) {
+	assert := assert1.New(t)
+	assert.True(IsDuplicateError(NewDuplicateRecordError(Insert, "", errors.New("foo"))))
+	assert.True(IsDuplicateError(errors.Wrap(NewUniqueConstraintError(Insert, "", errors.New("foo")))))
+	assert.False(IsDuplicateError(NewExecutionError(Insert, "", errors.New("foo"))))
+	assert.False(IsDuplicateError(nil))
+}
+
+func TestNewStaleRecordError(t *testing.T) {
+	assert := assert1.New(t)
+	err := NewStaleRecordError(Update, "bar").(*StaleRecordError)
+	assert.True(strings.HasPrefix(err.ReferenceID, dbErrPrefix))
+	assert.Equal("bar", err.Stmt)
+	assert.Contains(err.Error(), "stale record: record was modified or deleted since it was read")
+	assert.True(IsStaleRecordError(err))
+	assert.True(IsStaleRecordError(errors.Wrap(err)))
+	assert.False(IsStaleRecordError(NewNotFoundError()))
+	assert.False(IsStaleRecordError(nil))
+}
@@ -0,0 +1,183 @@
+package errors
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+)
+
+const (
+	// PostgreSQL SQLSTATE codes
+	// See: https://www.postgresql.org/docs/current/errcodes-appendix.html
+	postgresUniqueViolation      = "23505"
+	postgresForeignKeyViolation  = "23503"
+	postgresNotNullViolation     = "23502"
+	postgresCheckViolation       = "23514"
+	postgresStringDataTruncation = "22001"
+	postgresDeadlockDetected     = "40P01"
+	postgresSerializationFailure = "40001"
+	postgresLockNotAvailable     = "55P03"
+	postgresPrimaryKeySuffix     = "_pkey\""
+
+	// SQLite result codes, extended codes are checked before primary codes
+	// See: https://www.sqlite.org/rescode.html
+	sqliteBusy                  = 5
+	sqliteLocked                = 6
+	sqliteTooBig                = 18
+	sqliteConstraintCheck       = 275
+	sqliteConstraintForeignKey  = 787
+	sqliteConstraintNotNull     = 1299
+	sqliteConstraintPrimaryKey  = 1555
+	sqliteConstraintUnique      = 2067
+	sqliteConstraintRowID       = 2579
+	sqlitePrimaryResultCodeMask = 0xff
+)
+
+// Translator converts a driver error into one of the typed errors in this
+// package. Translators must return nil for errors they do not recognize so
+// that other translators have a chance to handle them.
+type Translator func(err error, action SQLQueryType, stmt string) errors.TracerError
+
+type registry struct {
+	mutex       sync.RWMutex
+	names       []string
+	translators map[string]Translator
+}
+
+var translators = &registry{
+	names: []string{qb.MySQL.Name(), qb.PostgreSQL.Name(), qb.SQLite.Name()},
+	translators: map[string]Translator{
+		qb.MySQL.Name():      translateMySQL,
+		qb.PostgreSQL.Name(): translatePostgreSQL,
+		qb.SQLite.Name():     translateSQLite,
+	},
+}
+
+// RegisterTranslator for the passed dialect, replacing any translator already
+// registered for it. Translators are consulted in the order their dialects were
+// first registered.
+func RegisterTranslator(dialect qb.Dialect, translator Translator) {
+	translators.mutex.Lock()
+	defer translators.mutex.Unlock()
+	if _, ok := translators.translators[dialect.Name()]; !ok {
+		translators.names = append(translators.names, dialect.Name())
+	}
+	translators.translators[dialect.Name()] = translator
+}
+
+// GetTranslator registered for the passed dialect, returns false if there is none
+func GetTranslator(dialect qb.Dialect) (Translator, bool) {
+	translators.mutex.RLock()
+	defer translators.mutex.RUnlock()
+	translator, ok := translators.translators[dialect.Name()]
+	return translator, ok
+}
+
+func registeredTranslators() []Translator {
+	translators.mutex.RLock()
+	defer translators.mutex.RUnlock()
+	registered := make([]Translator, len(translators.names))
+	for i, name := range translators.names {
+		registered[i] = translators.translators[name]
+	}
+	return registered
+}
+
+// sqlStateError is implemented by both pgx (*pgconn.PgError) and
+// lib/pq (*pq.Error)
+type sqlStateError interface {
+	error
+	SQLState() string
+}
+
+func translatePostgreSQL(err error, action SQLQueryType, stmt string) errors.TracerError {
+	var driverErr sqlStateError
+	if !errors.As(err, &driverErr) {
+		return nil
+	}
+	switch driverErr.SQLState() {
+	case postgresUniqueViolation:
+		if strings.Contains(err.Error(), postgresPrimaryKeySuffix) {
+			return NewDuplicateRecordError(action, stmt, err)
+		}
+		return NewUniqueConstraintError(action, stmt, err)
+	case postgresStringDataTruncation:
+		return NewDataTooLongError(action, stmt, err)
+	case postgresForeignKeyViolation:
+		return NewInvalidForeignKeyError(action, stmt, err)
+	case postgresNotNullViolation:
+		return NewNotNullError(action, stmt, err)
+	case postgresCheckViolation:
+		return NewCheckConstraintError(action, stmt, err)
+	// serialization failures are resolved the same way as a deadlock, by
+	// retrying the transaction
+	case postgresDeadlockDetected, postgresSerializationFailure:
+		return NewDeadlockError(action, stmt, err)
+	case postgresLockNotAvailable:
+		return NewLockWaitTimeoutError(action, stmt, err)
+	default:
+		return NewExecutionError(action, stmt, err)
+	}
+}
+
+// sqliteCoder is implemented by modernc.org/sqlite (*sqlite.Error)
+type sqliteCoder interface {
+	Code() int
+}
+
+// sqliteResultCode extracts the extended result code from a SQLite driver
+// error. mattn/go-sqlite3 exposes the codes as the struct fields 'Code' and
+// 'ExtendedCode' rather than methods so reflection is used to avoid a
+// dependency on the driver.
+func sqliteResultCode(err error) (int, bool) {
+	for ; nil != err; err = errors.Unwrap(err) {
+		v := reflect.Indirect(reflect.ValueOf(err))
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+		if coder, ok := err.(sqliteCoder); ok && strings.Contains(v.Type().PkgPath(), "sqlite") {
+			return coder.Code(), true
+		}
+		code, extended := v.FieldByName("Code"), v.FieldByName("ExtendedCode")
+		if !code.IsValid() || !code.CanInt() || !extended.IsValid() || !extended.CanInt() {
+			continue
+		}
+		if extended.Int() != 0 {
+			return int(extended.Int()), true
+		}
+		return int(code.Int()), true
+	}
+	return 0, false
+}
+
+func translateSQLite(err error, action SQLQueryType, stmt string) errors.TracerError {
+	code, ok := sqliteResultCode(err)
+	if !ok {
+		return nil
+	}
+	switch code {
+	case sqliteConstraintPrimaryKey, sqliteConstraintRowID:
+		return NewDuplicateRecordError(action, stmt, err)
+	case sqliteConstraintUnique:
+		return NewUniqueConstraintError(action, stmt, err)
+	case sqliteConstraintForeignKey:
+		return NewInvalidForeignKeyError(action, stmt, err)
+	case sqliteConstraintNotNull:
+		return NewNotNullError(action, stmt, err)
+	case sqliteConstraintCheck:
+		return NewCheckConstraintError(action, stmt, err)
+	}
+	switch code & sqlitePrimaryResultCodeMask {
+	case sqliteTooBig:
+		return NewDataTooLongError(action, stmt, err)
+	case sqliteLocked:
+		return NewDeadlockError(action, stmt, err)
+	case sqliteBusy:
+		return NewLockWaitTimeoutError(action, stmt, err)
+	default:
+		return NewExecutionError(action, stmt, err)
+	}
+}
@@ -0,0 +1,146 @@
+package errors
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	assert1 "github.com/stretchr/testify/assert"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/generator"
+)
+
+type postgresError struct {
+	code    string
+	message string
+}
+
+func (e *postgresError) Error() string {
+	return e.message
+}
+
+func (e *postgresError) SQLState() string {
+	return e.code
+}
+
+// sqliteError mirrors the shape of mattn/go-sqlite3 Error
+type sqliteError struct {
+	Code         int
+	ExtendedCode int
+}
+
+func (e sqliteError) Error() string {
+	return fmt.Sprintf("sqlite error %d", e.ExtendedCode)
+}
+
+func TestTranslateError_MySQL(t *testing.T) {
+	assert := assert1.New(t)
+	testData := []struct {
+		err      error
+		expected error
+	}{
+		{err: &mysql.MySQLError{Number: mysqlNotNull}, expected: &NotNullError{}},
+		{err: &mysql.MySQLError{Number: mysqlCheckConstraint}, expected: &CheckConstraintError{}},
+		{err: &mysql.MySQLError{Number: mysqlDeadlock}, expected: &DeadlockError{}},
+		{err: &mysql.MySQLError{Number: mysqlLockWaitTimeout}, expected: &LockWaitTimeoutError{}},
+	}
+	for _, data := range testData {
+		assert.IsType(data.expected, TranslateError(data.err, Select, generator.String(5)))
+	}
+}
+
+func TestTranslateError_PostgreSQL(t *testing.T) {
+	assert := assert1.New(t)
+	testData := []struct {
+		err      error
+		expected error
+	}{
+		{
+			err: &postgresError{code: postgresUniqueViolation,
+				message: `duplicate key value violates unique constraint "person_pkey"`},
+			expected: &DuplicateRecordError{},
+		},
+		{
+			err: &postgresError{code: postgresUniqueViolation,
+				message: `duplicate key value violates unique constraint "person_name_key"`},
+			expected: &UniqueConstraintError{},
+		},
+		{err: &postgresError{code: postgresStringDataTruncation}, expected: &DataTooLongError{}},
+		{err: &postgresError{code: postgresForeignKeyViolation}, expected: &InvalidForeignKeyError{}},
+		{err: &postgresError{code: postgresNotNullViolation}, expected: &NotNullError{}},
+		{err: &postgresError{code: postgresCheckViolation}, expected: &CheckConstraintError{}},
+		{err: &postgresError{code: postgresDeadlockDetected}, expected: &DeadlockError{}},
+		{err: &postgresError{code: postgresSerializationFailure}, expected: &DeadlockError{}},
+		{err: &postgresError{code: postgresLockNotAvailable}, expected: &LockWaitTimeoutError{}},
+		{err: &postgresError{code: "42601"}, expected: &SQLExecutionError{}},
+	}
+	for _, data := range testData {
+		assert.IsType(data.expected, TranslateError(data.err, Select, generator.String(5)))
+	}
+}
+
+func TestTranslateError_SQLite(t *testing.T) {
+	assert := assert1.New(t)
+	testData := []struct {
+		err      error
+		expected error
+	}{
+		{err: sqliteError{Code: 19, ExtendedCode: sqliteConstraintPrimaryKey}, expected: &DuplicateRecordError{}},
+		{err: sqliteError{Code: 19, ExtendedCode: sqliteConstraintUnique}, expected: &UniqueConstraintError{}},
+		{err: sqliteError{Code: 19, ExtendedCode: sqliteConstraintForeignKey}, expected: &InvalidForeignKeyError{}},
+		{err: sqliteError{Code: 19, ExtendedCode: sqliteConstraintNotNull}, expected: &NotNullError{}},
+		{err: sqliteError{Code: 19, ExtendedCode: sqliteConstraintCheck}, expected: &CheckConstraintError{}},
+		{err: sqliteError{Code: sqliteTooBig}, expected: &DataTooLongError{}},
+		{err: sqliteError{Code: sqliteBusy}, expected: &LockWaitTimeoutError{}},
+		{err: sqliteError{Code: sqliteLocked, ExtendedCode: 262}, expected: &DeadlockError{}},
+		{err: sqliteError{Code: 1}, expected: &SQLExecutionError{}},
+	}
+	for _, data := range testData {
+		assert.IsType(data.expected, TranslateError(data.err, Select, generator.String(5)))
+	}
+}
+
+func TestRegisterTranslator(t *testing.T) {
+	assert := assert1.New(t)
+	original, ok := GetTranslator(qb.SQLite)
+	assert.True(ok)
+	defer RegisterTranslator(qb.SQLite, original)
+
+	expected := errors.New(generator.String(5))
+	RegisterTranslator(qb.SQLite, func(err error, action SQLQueryType, stmt string) errors.TracerError {
+		if err == expected {
+			return NewCheckConstraintError(action, stmt, err)
+		}
+		return nil
+	})
+	assert.IsType(&CheckConstraintError{}, TranslateError(expected, Insert, generator.String(5)))
+	assert.IsType(&SQLSystemError{}, TranslateError(errors.New("foo"), Insert, generator.String(5)))
+}
+
+// unregisteredDialect has no translator registered
+type unregisteredDialect struct {
+	qb.Dialect
+}
+
+func (unregisteredDialect) Name() string {
+	return "unregistered"
+}
+
+func TestTranslateErrorFor(t *testing.T) {
+	assert := assert1.New(t)
+	err := sqliteError{Code: 19, ExtendedCode: sqliteConstraintNotNull}
+	stmt := generator.String(5)
+	// only the translator of the dialect is used
+	assert.IsType(&NotNullError{}, TranslateErrorFor(qb.SQLite, err, Insert, stmt))
+	assert.IsType(&SQLSystemError{}, TranslateErrorFor(qb.MySQL, err, Insert, stmt))
+	assert.IsType(&SQLSystemError{}, TranslateErrorFor(qb.PostgreSQL, err, Insert, stmt))
+	assert.IsType(&DeadlockError{}, TranslateErrorFor(qb.MySQL,
+		&mysql.MySQLError{Number: mysqlDeadlock}, Insert, stmt))
+	// every translator is tried without a translator for the dialect
+	assert.IsType(&NotNullError{}, TranslateErrorFor(nil, err, Insert, stmt))
+	assert.IsType(&NotNullError{}, TranslateErrorFor(unregisteredDialect{qb.MySQL}, err, Insert, stmt))
+	assert.IsType(&NotFoundError{}, TranslateErrorFor(qb.MySQL, sql.ErrNoRows, Select, stmt))
+}
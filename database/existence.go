@@ -1,6 +1,9 @@
 package database
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 const (
 	// TableExistenceQueryFormat returns a single row and column indicating that the table
@@ -13,6 +16,14 @@ const (
 		`FROM information_schema.tables` +
 		`	WHERE table_schema = '%s'` +
 		`	AND table_name = '%s' LIMIT 1;`
+	// ColumnExistenceQueryFormat returns a single row and column indicating that
+	// the column exists on the table. Takes format vars 'table_schema',
+	// 'table_name' and 'column_name'
+	ColumnExistenceQueryFormat = `SELECT COLUMN_NAME as "column_name" ` +
+		`FROM information_schema.columns` +
+		`	WHERE table_schema = '%s'` +
+		`	AND table_name = '%s'` +
+		`	AND column_name = '%s' LIMIT 1;`
 )
 
 // TableNameResult is for holding the result row from the existence query
@@ -21,14 +32,37 @@ type TableNameResult struct {
 	TableName string `db:"table_name"`
 }
 
+// ColumnNameResult is for holding the result row from the column existence query
+type ColumnNameResult struct {
+	// ColumnName of the column
+	ColumnName string `db:"column_name"`
+}
+
 // TableExists for the passed schema and table name on the passed database
 func TableExists(db Client, schema, name string) (bool, error) {
+	return TableExistsContext(context.Background(), db, schema, name)
+}
+
+// TableExistsContext for the passed schema and table name on the passed database
+func TableExistsContext(ctx context.Context, db Client, schema, name string) (bool, error) {
 	var exists bool
 	var err error
 	var target []*TableNameResult
-	err = db.Select(&target, fmt.Sprintf(TableExistenceQueryFormat, schema, name))
+	err = db.SelectContext(ctx, &target, fmt.Sprintf(TableExistenceQueryFormat, schema, name))
 	if len(target) == 1 {
 		exists = true
 	}
 	return exists, err
 }
+
+// ColumnExists on the passed schema and table on the passed database
+func ColumnExists(db Client, schema, table, name string) (bool, error) {
+	return ColumnExistsContext(context.Background(), db, schema, table, name)
+}
+
+// ColumnExistsContext on the passed schema and table on the passed database
+func ColumnExistsContext(ctx context.Context, db Client, schema, table, name string) (bool, error) {
+	var target []*ColumnNameResult
+	err := db.SelectContext(ctx, &target, fmt.Sprintf(ColumnExistenceQueryFormat, schema, table, name))
+	return len(target) == 1, err
+}
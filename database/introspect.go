@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/recordgen"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/stringutil"
+)
+
+const (
+	// SchemaColumnsQuery returns a row for every column of every table in the
+	// schema passed as the only parameter along with the table and column it
+	// references when it is a foreign key.
+	// NOTE: 'as' clauses MUST be there or MySQL will return upper case column
+	//  names and mapping will fail
+	SchemaColumnsQuery = `SELECT c.TABLE_NAME as "table_name", c.COLUMN_NAME as "column_name", ` +
+		`c.DATA_TYPE as "data_type", c.COLUMN_TYPE as "column_type", c.IS_NULLABLE as "is_nullable", ` +
+		`c.COLUMN_KEY as "column_key", k.REFERENCED_TABLE_NAME as "referenced_table", ` +
+		`k.REFERENCED_COLUMN_NAME as "referenced_column" ` +
+		`FROM information_schema.columns c ` +
+		`LEFT JOIN information_schema.key_column_usage k ON k.TABLE_SCHEMA = c.TABLE_SCHEMA ` +
+		`AND k.TABLE_NAME = c.TABLE_NAME AND k.COLUMN_NAME = c.COLUMN_NAME ` +
+		`AND k.REFERENCED_TABLE_NAME IS NOT NULL ` +
+		`WHERE c.TABLE_SCHEMA = ? ` +
+		`ORDER BY c.TABLE_NAME, c.ORDINAL_POSITION;`
+
+	primaryKeyColumn = "PRI"
+	nullableColumn   = "YES"
+	createdColumn    = "created"
+	modifiedColumn   = "modified"
+)
+
+// SchemaColumn is a result row from the schema columns query
+type SchemaColumn struct {
+	// TableName the column is on
+	TableName string `db:"table_name"`
+	// ColumnName of the column
+	ColumnName string `db:"column_name"`
+	// DataType of the column without size or modifiers (e.g. 'varchar')
+	DataType string `db:"data_type"`
+	// ColumnType is the full type of the column (e.g. 'tinyint(1) unsigned')
+	ColumnType string `db:"column_type"`
+	// IsNullable is 'YES' when the column may be NULL
+	IsNullable string `db:"is_nullable"`
+	// ColumnKey is 'PRI' for primary key columns
+	ColumnKey string `db:"column_key"`
+	// ReferencedTable of a foreign key column
+	ReferencedTable *string `db:"referenced_table"`
+	// ReferencedColumn of a foreign key column
+	ReferencedColumn *string `db:"referenced_column"`
+}
+
+// IntrospectSchema reads the tables in the passed schema from
+// information_schema and returns a model of each for generating record structs
+// and meta types in the passed package using recordgen.GenerateRecord.
+func IntrospectSchema(ctx context.Context, db Client, schema, pkg string) ([]*recordgen.Model, error) {
+	var columns []*SchemaColumn
+	if err := db.SelectContext(ctx, &columns, SchemaColumnsQuery, schema); nil != err {
+		return nil, errors.Wrap(err)
+	}
+	return SchemaModels(pkg, columns), nil
+}
+
+// SchemaModels builds a model for each table in the passed columns which must
+// be ordered by table. Primary keys, foreign keys and the read only 'created'
+// and 'modified' timestamps are detected from the columns.
+func SchemaModels(pkg string, columns []*SchemaColumn) []*recordgen.Model {
+	var (
+		models []*recordgen.Model
+		model  *recordgen.Model
+	)
+	for _, column := range columns {
+		if nil == model || model.Table != column.TableName {
+			model = &recordgen.Model{
+				Package: pkg,
+				Type:    stringutil.UpperCamelCase(column.TableName),
+				Table:   column.TableName,
+			}
+			models = append(models, model)
+		}
+		if _, ok := model.Field(column.ColumnName); ok {
+			// a column may be part of more than one foreign key
+			continue
+		}
+		field := recordgen.Field{
+			Name:   stringutil.UpperCamelCase(column.ColumnName),
+			Column: column.ColumnName,
+			Type:   goType(column),
+		}
+		if strings.HasSuffix(field.Type, "time.Time") &&
+			(column.ColumnName == createdColumn || column.ColumnName == modifiedColumn) {
+			field.ReadOnly = true
+		}
+		if nil != column.ReferencedTable && nil != column.ReferencedColumn {
+			field.References = *column.ReferencedTable + "." + *column.ReferencedColumn
+		}
+		if column.ColumnKey == primaryKeyColumn && model.PrimaryKey == "" {
+			model.PrimaryKey = column.ColumnName
+		}
+		model.Fields = append(model.Fields, field)
+	}
+	for _, model := range models {
+		if model.PrimaryKey == "" {
+			// tables without a primary key use the first column
+			model.PrimaryKey = model.Fields[0].Column
+		}
+		model.SortBy = model.PrimaryKey
+		if _, ok := model.Field(createdColumn); ok {
+			model.SortBy = createdColumn
+		}
+	}
+	return models
+}
+
+// goType for the passed column, nullable columns are pointers
+func goType(column *SchemaColumn) string {
+	var (
+		columnType = strings.ToLower(column.ColumnType)
+		name       string
+	)
+	switch strings.ToLower(column.DataType) {
+	case "tinyint":
+		name = "int"
+		if strings.HasPrefix(columnType, "tinyint(1)") {
+			name = "bool"
+		}
+	case "smallint", "mediumint", "int", "integer", "bigint", "year":
+		name = "int"
+	case "bool", "boolean":
+		name = "bool"
+	case "decimal", "numeric", "float", "double", "real":
+		name = "float64"
+	case "date", "datetime", "timestamp":
+		name = "time.Time"
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext", "enum", "set", "json", "time":
+		name = "string"
+	default:
+		// binary, blob and unknown types; nil represents NULL
+		return "[]byte"
+	}
+	if column.IsNullable == nullableColumn {
+		return "*" + name
+	}
+	return name
+}
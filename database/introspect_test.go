@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/recordgen"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	assert1 "github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+func schemaColumn(table, column, dataType, columnType, nullable, key string) *SchemaColumn {
+	return &SchemaColumn{TableName: table, ColumnName: column, DataType: dataType, ColumnType: columnType,
+		IsNullable: nullable, ColumnKey: key}
+}
+
+func Test_goType(t *testing.T) {
+	var tests = []struct {
+		dataType   string
+		columnType string
+		nullable   string
+		expected   string
+	}{
+		{"int", "int(11)", "NO", "int"},
+		{"bigint", "bigint(20) unsigned", "YES", "*int"},
+		{"tinyint", "tinyint(1)", "NO", "bool"},
+		{"tinyint", "tinyint(4)", "NO", "int"},
+		{"varchar", "varchar(255)", "NO", "string"},
+		{"text", "text", "YES", "*string"},
+		{"decimal", "decimal(10,2)", "NO", "float64"},
+		{"datetime", "datetime", "NO", "time.Time"},
+		{"timestamp", "timestamp", "YES", "*time.Time"},
+		{"blob", "blob", "YES", "[]byte"},
+		{"geometry", "geometry", "NO", "[]byte"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.columnType, func(t *testing.T) {
+			assert1.Equal(t, tc.expected, goType(schemaColumn("t", "c", tc.dataType, tc.columnType,
+				tc.nullable, "")))
+		})
+	}
+}
+
+func TestSchemaModels(t *testing.T) {
+	assert := assert1.New(t)
+	user, id := "user", "id"
+	fk := schemaColumn("address", "user_id", "varchar", "varchar(32)", "NO", "MUL")
+	fk.ReferencedTable, fk.ReferencedColumn = &user, &id
+	columns := []*SchemaColumn{
+		schemaColumn("address", "id", "int", "int(11)", "NO", "PRI"),
+		fk,
+		fk,
+		schemaColumn("address", "line_1", "varchar", "varchar(255)", "YES", ""),
+		schemaColumn("address", "created", "datetime", "datetime", "NO", ""),
+		schemaColumn("address", "modified", "datetime", "datetime", "NO", ""),
+		schemaColumn("audit_log", "message", "text", "text", "NO", ""),
+	}
+	models := SchemaModels("records", columns)
+	assert.Equal([]*recordgen.Model{
+		{
+			Package:    "records",
+			Type:       "Address",
+			Table:      "address",
+			PrimaryKey: "id",
+			SortBy:     "created",
+			Fields: []recordgen.Field{
+				{Name: "ID", Column: "id", Type: "int"},
+				{Name: "UserID", Column: "user_id", Type: "string", References: "user.id"},
+				{Name: "Line1", Column: "line_1", Type: "*string"},
+				{Name: "Created", Column: "created", Type: "time.Time", ReadOnly: true},
+				{Name: "Modified", Column: "modified", Type: "time.Time", ReadOnly: true},
+			},
+		},
+		{
+			Package:    "records",
+			Type:       "AuditLog",
+			Table:      "audit_log",
+			PrimaryKey: "message",
+			SortBy:     "message",
+			Fields: []recordgen.Field{
+				{Name: "Message", Column: "message", Type: "string"},
+			},
+		},
+	}, models)
+}
+
+func TestIntrospectSchema(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	client := NewMockClient(ctrl)
+	ctx := context.Background()
+
+	client.EXPECT().SelectContext(ctx, gomock.Any(), SchemaColumnsQuery, "legacy").
+		DoAndReturn(func(_ context.Context, dest any, _ string, _ ...any) error {
+			*(dest.(*[]*SchemaColumn)) = []*SchemaColumn{
+				schemaColumn("widget", "id", "varchar", "varchar(32)", "NO", "PRI"),
+			}
+			return nil
+		})
+	models, err := IntrospectSchema(ctx, client, "legacy", "records")
+	assert.NoError(err)
+	if assert.Len(models, 1) {
+		assert.Equal("Widget", models[0].Type)
+		assert.Equal("id", models[0].PrimaryKey)
+	}
+
+	client.EXPECT().SelectContext(ctx, gomock.Any(), SchemaColumnsQuery, "legacy").
+		Return(errors.New("denied"))
+	_, err = IntrospectSchema(ctx, client, "legacy", "records")
+	assert.EqualError(err, "denied")
+}
@@ -0,0 +1,69 @@
+package lock
+
+import (
+	"fmt"
+
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+)
+
+// NotAcquiredError is returned when the context passed to Lock is done before
+// the lock is acquired
+type NotAcquiredError struct {
+	Name  string
+	err   error
+	trace []string
+}
+
+// NewNotAcquiredError for the named lock and the error of the context
+func NewNotAcquiredError(name string, err error) errors.TracerError {
+	return &NotAcquiredError{Name: name, err: err, trace: errors.GetStackTrace()}
+}
+
+func (err *NotAcquiredError) Error() string {
+	return fmt.Sprintf("lock %s was not acquired: %s", err.Name, err.err)
+}
+
+// Unwrap the error of the context
+func (err *NotAcquiredError) Unwrap() error {
+	return err.err
+}
+
+// Trace returns the stack trace for the error
+func (err *NotAcquiredError) Trace() []string {
+	return err.trace
+}
+
+// IsNotAcquiredError indicates if the passed error (which may be wrapped) is a
+// NotAcquiredError
+func IsNotAcquiredError(err error) bool {
+	var dst *NotAcquiredError
+	return errors.As(err, &dst)
+}
+
+// LostError is returned when a lock is no longer held by the handle, because
+// it expired, was taken by another owner or its session ended
+type LostError struct {
+	Name  string
+	trace []string
+}
+
+// NewLostError for the named lock
+func NewLostError(name string) errors.TracerError {
+	return &LostError{Name: name, trace: errors.GetStackTrace()}
+}
+
+func (err *LostError) Error() string {
+	return fmt.Sprintf("lock %s is no longer held", err.Name)
+}
+
+// Trace returns the stack trace for the error
+func (err *LostError) Trace() []string {
+	return err.trace
+}
+
+// IsLostError indicates if the passed error (which may be wrapped) is a
+// LostError
+func IsLostError(err error) bool {
+	var dst *LostError
+	return errors.As(err, &dst)
+}
@@ -0,0 +1,87 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/generator"
+)
+
+// localLease of a lock held by a handle of a localLocker
+type localLease struct {
+	owner   string
+	expires time.Time
+}
+
+// localLocker holds locks in memory
+type localLocker struct {
+	mutex  sync.Mutex
+	leases map[string]localLease
+	now    func() time.Time
+}
+
+// NewLocalLocker holding locks in memory, locks are only exclusive within the
+// process. This is intended for tests and for running singleton jobs locally.
+func NewLocalLocker() Locker {
+	return &localLocker{leases: make(map[string]localLease), now: time.Now}
+}
+
+func (l *localLocker) Lock(ctx context.Context, name string, ttl time.Duration) (Handle, errors.TracerError) {
+	handle := &localHandle{locker: l, name: name, owner: generator.ID(leaseOwnerPrefix), ttl: leaseTTL(ttl)}
+	if err := poll(ctx, name, func() (bool, error) { return handle.acquire(), nil }); nil != err {
+		return nil, err
+	}
+	return handle, nil
+}
+
+type localHandle struct {
+	locker *localLocker
+	name   string
+	owner  string
+	ttl    time.Duration
+}
+
+// acquire the lock if it is not held or the lease expired
+func (h *localHandle) acquire() bool {
+	h.locker.mutex.Lock()
+	defer h.locker.mutex.Unlock()
+	now := h.locker.now()
+	if lease, ok := h.locker.leases[h.name]; ok && now.Before(lease.expires) {
+		return false
+	}
+	h.locker.leases[h.name] = localLease{owner: h.owner, expires: now.Add(h.ttl)}
+	return true
+}
+
+// held is true when the lease is owned by this handle and has not expired, the
+// locker must be locked
+func (h *localHandle) held() bool {
+	lease, ok := h.locker.leases[h.name]
+	return ok && lease.owner == h.owner && h.locker.now().Before(lease.expires)
+}
+
+func (h *localHandle) Name() string {
+	return h.name
+}
+
+func (h *localHandle) Refresh(context.Context) errors.TracerError {
+	h.locker.mutex.Lock()
+	defer h.locker.mutex.Unlock()
+	if !h.held() {
+		return NewLostError(h.name)
+	}
+	h.locker.leases[h.name] = localLease{owner: h.owner, expires: h.locker.now().Add(h.ttl)}
+	return nil
+}
+
+func (h *localHandle) Unlock(context.Context) errors.TracerError {
+	h.locker.mutex.Lock()
+	defer h.locker.mutex.Unlock()
+	if !h.held() {
+		return NewLostError(h.name)
+	}
+	delete(h.locker.leases, h.name)
+	return nil
+}
@@ -1,16 +1,29 @@
+//go:generate mockgen -source=$GOFILE -package $GOPACKAGE -destination lock.mock.gen.go
 package lock
 
 import (
-	"fmt"
+	"context"
+	"sync"
 	"time"
 
 	"github.com/beaconsoftwarellc/gadget/v2/database"
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
 	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/log"
+	"github.com/jmoiron/sqlx"
 )
 
 const (
-	acquireLockQueryFormat = "SELECT GET_LOCK('%s', %d) AS STATUS"
-	releaseLockQueryFormat = "SELECT RELEASE_LOCK('%s') AS STATUS"
+	acquireLockQuery = "SELECT GET_LOCK(?, ?) AS STATUS"
+	releaseLockQuery = "SELECT RELEASE_LOCK(?) AS STATUS"
+
+	// minimumPollInterval between attempts to acquire a lock held by another
+	// owner, the interval doubles after each attempt up to maximumPollInterval
+	minimumPollInterval = 50 * time.Millisecond
+	maximumPollInterval = 2 * time.Second
+
+	// DefaultTTL of locks that are requested with a ttl of 0
+	DefaultTTL = 30 * time.Second
 )
 
 // StatusResult is for capturing output from a function call on the database, you must use
@@ -23,11 +36,14 @@ type StatusResult struct {
 // Acquire with the specified name and timeout. Returns boolean indicating whether the
 // lock was acquired or error on failure to execute.
 // See: https://dev.mysql.com/doc/refman/5.7/en/locking-functions.html
+//
+// Deprecated: the lock is held by whichever connection of the pool executes
+// the query, use a Locker from NewMySQLLocker which holds it on a dedicated
+// connection.
 func Acquire(db database.Client, name string, timeout time.Duration) (bool, errors.TracerError) {
 	var err error
 	var target []*StatusResult
-	err = db.Select(&target,
-		fmt.Sprintf(acquireLockQueryFormat, name, int(timeout.Seconds())))
+	err = db.Select(&target, acquireLockQuery, name, int(timeout.Seconds()))
 	if nil != err {
 		return false, errors.Wrap(err)
 	}
@@ -39,7 +55,149 @@ func Acquire(db database.Client, name string, timeout time.Duration) (bool, erro
 
 // Release with the specified name
 // See: https://dev.mysql.com/doc/refman/5.7/en/locking-functions.html
+//
+// Deprecated: see Acquire.
 func Release(db database.Client, name string) errors.TracerError {
 	var target []*StatusResult
-	return errors.Wrap(db.Select(&target, fmt.Sprintf(releaseLockQueryFormat, name)))
+	return errors.Wrap(db.Select(&target, releaseLockQuery, name))
+}
+
+// Locker acquires named locks that are exclusive across every process using the
+// same backend
+type Locker interface {
+	// Lock with the passed name, waiting until it is acquired or ctx is done in
+	// which case a NotAcquiredError is returned. The lock expires if it is not
+	// refreshed within ttl, DefaultTTL when it is 0. Backends that hold the lock
+	// for the life of a database session do not expire it.
+	Lock(ctx context.Context, name string, ttl time.Duration) (Handle, errors.TracerError)
+}
+
+// Handle to an acquired lock
+type Handle interface {
+	// Name of the lock
+	Name() string
+	// Refresh extends the lock by its ttl, a LostError is returned when it is no
+	// longer held
+	Refresh(ctx context.Context) errors.TracerError
+	// Unlock releases the lock, a LostError may be returned when it was no
+	// longer held. The handle can not be used once it is unlocked.
+	Unlock(ctx context.Context) errors.TracerError
+}
+
+// Conner opens a dedicated connection from a pool, database.Client and
+// sqlx.DB satisfy this interface
+type Conner interface {
+	Connx(ctx context.Context) (*sqlx.Conn, error)
+}
+
+// NewLocker for the dialect of the passed connection, MySQL and PostgreSQL
+// use their named locks and other dialects use a lease table, see
+// NewTableLocker.
+func NewLocker(connection database.Connection) Locker {
+	switch qb.DialectFor(connection.GetConfiguration().DatabaseDialect()) {
+	case qb.MySQL:
+		return NewMySQLLocker(connection.Client())
+	case qb.PostgreSQL:
+		return NewPostgreSQLLocker(connection.Client())
+	default:
+		return NewTableLocker(connection.Database())
+	}
+}
+
+// WithLock calls fn while holding the named lock, see Hold. The lock is
+// waited for until ctx is done.
+func WithLock(ctx context.Context, locker Locker, name string, ttl time.Duration,
+	fn func(ctx context.Context) error) errors.TracerError {
+	handle, err := locker.Lock(ctx, name, ttl)
+	if nil != err {
+		return err
+	}
+	return Hold(ctx, handle, ttl, fn)
+}
+
+// Hold the lock of handle while calling fn, refreshing it every third of ttl
+// (DefaultTTL when it is 0) and unlocking it once fn returns. The context
+// passed to fn is cancelled if the lock is lost, in which case the LostError is
+// returned when fn does not return an error of its own.
+func Hold(ctx context.Context, handle Handle, ttl time.Duration,
+	fn func(ctx context.Context) error) errors.TracerError {
+	held, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		refresh(held, handle, leaseTTL(ttl)/3, done, cancel)
+	}()
+	err := fn(held)
+	close(done)
+	wg.Wait()
+	lost := context.Cause(held)
+	unlockErr := handle.Unlock(context.WithoutCancel(ctx))
+	switch {
+	case nil != err:
+		_ = log.Error(unlockErr)
+		return errors.Wrap(err)
+	case IsLostError(lost):
+		return errors.Wrap(lost)
+	default:
+		return unlockErr
+	}
+}
+
+// refresh handle every interval until done is closed, cancelling with the
+// error when a refresh fails
+func refresh(ctx context.Context, handle Handle, interval time.Duration, done <-chan struct{},
+	cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := handle.Refresh(ctx); nil != err {
+				log.Warnf("failed to refresh lock %s: %s", handle.Name(), err)
+				if !IsLostError(err) {
+					err = NewLostError(handle.Name())
+				}
+				cancel(err)
+				return
+			}
+		}
+	}
+}
+
+// leaseTTL is ttl or DefaultTTL when it is not positive
+func leaseTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return DefaultTTL
+	}
+	return ttl
+}
+
+// poll try until it acquires the named lock or ctx is done
+func poll(ctx context.Context, name string, try func() (bool, error)) errors.TracerError {
+	wait := minimumPollInterval
+	for {
+		acquired, err := try()
+		if nil != err {
+			if nil != ctx.Err() {
+				return NewNotAcquiredError(name, ctx.Err())
+			}
+			return errors.Wrap(err)
+		}
+		if acquired {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return NewNotAcquiredError(name, ctx.Err())
+		case <-time.After(wait):
+		}
+		wait = min(2*wait, maximumPollInterval)
+	}
 }
@@ -0,0 +1,164 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: lock.go
+//
+// Generated by this command:
+//
+//	mockgen -source=lock.go -package lock -destination lock.mock.gen.go
+//
+
+// Package lock is a generated GoMock package.
+package lock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	errors "github.com/beaconsoftwarellc/gadget/v2/errors"
+	sqlx "github.com/jmoiron/sqlx"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLocker is a mock of Locker interface.
+type MockLocker struct {
+	ctrl     *gomock.Controller
+	recorder *MockLockerMockRecorder
+	isgomock struct{}
+}
+
+// MockLockerMockRecorder is the mock recorder for MockLocker.
+type MockLockerMockRecorder struct {
+	mock *MockLocker
+}
+
+// NewMockLocker creates a new mock instance.
+func NewMockLocker(ctrl *gomock.Controller) *MockLocker {
+	mock := &MockLocker{ctrl: ctrl}
+	mock.recorder = &MockLockerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLocker) EXPECT() *MockLockerMockRecorder {
+	return m.recorder
+}
+
+// Lock mocks base method.
+func (m *MockLocker) Lock(ctx context.Context, name string, ttl time.Duration) (Handle, errors.TracerError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, name, ttl)
+	ret0, _ := ret[0].(Handle)
+	ret1, _ := ret[1].(errors.TracerError)
+	return ret0, ret1
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockLockerMockRecorder) Lock(ctx, name, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockLocker)(nil).Lock), ctx, name, ttl)
+}
+
+// MockHandle is a mock of Handle interface.
+type MockHandle struct {
+	ctrl     *gomock.Controller
+	recorder *MockHandleMockRecorder
+	isgomock struct{}
+}
+
+// MockHandleMockRecorder is the mock recorder for MockHandle.
+type MockHandleMockRecorder struct {
+	mock *MockHandle
+}
+
+// NewMockHandle creates a new mock instance.
+func NewMockHandle(ctrl *gomock.Controller) *MockHandle {
+	mock := &MockHandle{ctrl: ctrl}
+	mock.recorder = &MockHandleMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHandle) EXPECT() *MockHandleMockRecorder {
+	return m.recorder
+}
+
+// Name mocks base method.
+func (m *MockHandle) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockHandleMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockHandle)(nil).Name))
+}
+
+// Refresh mocks base method.
+func (m *MockHandle) Refresh(ctx context.Context) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockHandleMockRecorder) Refresh(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockHandle)(nil).Refresh), ctx)
+}
+
+// Unlock mocks base method.
+func (m *MockHandle) Unlock(ctx context.Context) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unlock", ctx)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// Unlock indicates an expected call of Unlock.
+func (mr *MockHandleMockRecorder) Unlock(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlock", reflect.TypeOf((*MockHandle)(nil).Unlock), ctx)
+}
+
+// MockConner is a mock of Conner interface.
+type MockConner struct {
+	ctrl     *gomock.Controller
+	recorder *MockConnerMockRecorder
+	isgomock struct{}
+}
+
+// MockConnerMockRecorder is the mock recorder for MockConner.
+type MockConnerMockRecorder struct {
+	mock *MockConner
+}
+
+// NewMockConner creates a new mock instance.
+func NewMockConner(ctrl *gomock.Controller) *MockConner {
+	mock := &MockConner{ctrl: ctrl}
+	mock.recorder = &MockConnerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConner) EXPECT() *MockConnerMockRecorder {
+	return m.recorder
+}
+
+// Connx mocks base method.
+func (m *MockConner) Connx(ctx context.Context) (*sqlx.Conn, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Connx", ctx)
+	ret0, _ := ret[0].(*sqlx.Conn)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Connx indicates an expected call of Connx.
+func (mr *MockConnerMockRecorder) Connx(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Connx", reflect.TypeOf((*MockConner)(nil).Connx), ctx)
+}
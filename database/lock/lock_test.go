@@ -0,0 +1,91 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	assert1 "github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLocalLocker(t *testing.T) {
+	assert := assert1.New(t)
+	now := time.Now()
+	locker := &localLocker{leases: make(map[string]localLease), now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	first, err := locker.Lock(ctx, "job", time.Minute)
+	assert.NoError(err)
+	assert.Equal("job", first.Name())
+
+	// held locks are waited for until the context is done
+	expired, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = locker.Lock(expired, "job", time.Minute)
+	assert.True(IsNotAcquiredError(err))
+	assert.ErrorIs(err, context.DeadlineExceeded)
+
+	// other names are not blocked
+	other, err := locker.Lock(ctx, "other", 0)
+	assert.NoError(err)
+	assert.NoError(other.Unlock(ctx))
+
+	// refreshing extends the lease
+	now = now.Add(50 * time.Second)
+	assert.NoError(first.Refresh(ctx))
+	now = now.Add(50 * time.Second)
+	assert.NoError(first.Refresh(ctx))
+
+	// expired leases are taken by the next owner
+	now = now.Add(2 * time.Minute)
+	second, err := locker.Lock(ctx, "job", time.Minute)
+	assert.NoError(err)
+	assert.True(IsLostError(first.Refresh(ctx)))
+	assert.True(IsLostError(first.Unlock(ctx)))
+	assert.NoError(second.Unlock(ctx))
+	assert.Empty(locker.leases)
+}
+
+func TestWithLock(t *testing.T) {
+	assert := assert1.New(t)
+	locker := NewLocalLocker()
+	ctx := context.Background()
+	var called bool
+	assert.NoError(WithLock(ctx, locker, "job", time.Minute, func(ctx context.Context) error {
+		called = true
+		// the lock is held while fn is called
+		blocked, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err := locker.Lock(blocked, "job", time.Minute)
+		assert.True(IsNotAcquiredError(err))
+		return nil
+	}))
+	assert.True(called)
+
+	// the lock is released after fn returns an error
+	expected := errors.New("failed")
+	assert.Equal(expected, WithLock(ctx, locker, "job", time.Minute, func(context.Context) error {
+		return expected
+	}))
+	handle, err := locker.Lock(ctx, "job", time.Minute)
+	assert.NoError(err)
+	assert.NoError(handle.Unlock(ctx))
+}
+
+func TestHold_Lost(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	handle := NewMockHandle(ctrl)
+	handle.EXPECT().Name().Return("job").AnyTimes()
+	handle.EXPECT().Refresh(gomock.Any()).Return(NewLostError("job"))
+	handle.EXPECT().Unlock(gomock.Any()).Return(NewLostError("job"))
+
+	err := Hold(context.Background(), handle, 30*time.Millisecond, func(ctx context.Context) error {
+		// fn is cancelled when the lock is lost
+		<-ctx.Done()
+		return nil
+	})
+	assert.True(IsLostError(err))
+}
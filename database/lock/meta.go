@@ -0,0 +1,58 @@
+package lock
+
+import "github.com/beaconsoftwarellc/gadget/v2/database/qb"
+
+type leaseMeta struct {
+	alias   string
+	Name    qb.TableField
+	Owner   qb.TableField
+	Expires qb.TableField
+
+	allColumns qb.TableField
+}
+
+func (p *leaseMeta) AllColumns() qb.TableField {
+	return p.allColumns
+}
+
+func (p *leaseMeta) GetName() string {
+	return LeaseTableName
+}
+
+func (p *leaseMeta) GetAlias() string {
+	return p.alias
+}
+
+func (p *leaseMeta) PrimaryKey() qb.TableField {
+	return p.Name
+}
+
+func (p *leaseMeta) SortBy() (qb.TableField, qb.OrderDirection) {
+	return p.Name, qb.Ascending
+}
+
+func (p *leaseMeta) ReadColumns() []qb.TableField {
+	return []qb.TableField{
+		p.Name,
+		p.Owner,
+		p.Expires,
+	}
+}
+
+func (p *leaseMeta) WriteColumns() []qb.TableField {
+	return p.ReadColumns()
+}
+
+func (p *leaseMeta) Alias(alias string) *leaseMeta {
+	return &leaseMeta{
+		alias:   alias,
+		Name:    qb.TableField{Name: "name", Table: alias},
+		Owner:   qb.TableField{Name: "owner", Table: alias},
+		Expires: qb.TableField{Name: "expires", Table: alias},
+
+		allColumns: qb.TableField{Name: "*", Table: alias},
+	}
+}
+
+// LeaseMeta is a meta representation of the lease table for building ad hoc queries
+var LeaseMeta = (&leaseMeta{}).Alias(LeaseTableName)
@@ -0,0 +1,161 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	mysqlAcquireQuery = "SELECT GET_LOCK(?, 0)"
+	mysqlHeldQuery    = "SELECT IS_USED_LOCK(?) = CONNECTION_ID()"
+	mysqlReleaseQuery = "SELECT RELEASE_LOCK(?)"
+
+	postgresAcquireQuery = "SELECT pg_try_advisory_lock($1)"
+	postgresHeldQuery    = "SELECT COUNT(*) > 0 FROM pg_locks WHERE locktype = 'advisory' " +
+		"AND pid = pg_backend_pid() AND classid = $1 AND objid = $2 AND objsubid = 1 AND granted"
+	postgresReleaseQuery = "SELECT pg_advisory_unlock($1)"
+)
+
+// sessionLocker acquires locks that are held by a database session until they
+// are released or the session ends, each lock is held on a dedicated connection
+// from the pool
+type sessionLocker struct {
+	db Conner
+	// args of the queries for the named lock
+	args func(name string) (acquire, held []any)
+	// acquire, held and release queries returning a single boolean or integer
+	// column that is true or 1 on success
+	acquire string
+	held    string
+	release string
+}
+
+// NewMySQLLocker using GET_LOCK on db, the ttl of locks is ignored as they are
+// held until they are unlocked or the connection is lost. Names are limited to
+// 64 characters.
+// See: https://dev.mysql.com/doc/refman/8.0/en/locking-functions.html
+func NewMySQLLocker(db Conner) Locker {
+	return &sessionLocker{
+		db: db,
+		args: func(name string) ([]any, []any) {
+			return []any{name}, []any{name}
+		},
+		acquire: mysqlAcquireQuery,
+		held:    mysqlHeldQuery,
+		release: mysqlReleaseQuery,
+	}
+}
+
+// NewPostgreSQLLocker using session level advisory locks on db keyed by a hash
+// of the name, the ttl of locks is ignored as they are held until they are
+// unlocked or the connection is lost.
+// See: https://www.postgresql.org/docs/current/explicit-locking.html#ADVISORY-LOCKS
+func NewPostgreSQLLocker(db Conner) Locker {
+	return &sessionLocker{
+		db: db,
+		args: func(name string) ([]any, []any) {
+			key := advisoryKey(name)
+			// pg_locks splits a bigint key into the high and low 32 bits
+			return []any{key}, []any{int64(uint32(key >> 32)), int64(uint32(key))}
+		},
+		acquire: postgresAcquireQuery,
+		held:    postgresHeldQuery,
+		release: postgresReleaseQuery,
+	}
+}
+
+// advisoryKey for the named lock
+func advisoryKey(name string) int64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(name))
+	return int64(hash.Sum64())
+}
+
+func (l *sessionLocker) Lock(ctx context.Context, name string, _ time.Duration) (Handle, errors.TracerError) {
+	conn, err := l.db.Connx(ctx)
+	if nil != err {
+		return nil, errors.Wrap(err)
+	}
+	acquireArgs, heldArgs := l.args(name)
+	if err := poll(ctx, name, func() (bool, error) {
+		return queryBool(ctx, conn, l.acquire, acquireArgs...)
+	}); nil != err {
+		discard(conn)
+		return nil, err
+	}
+	return &sessionHandle{locker: l, name: name, conn: conn, acquireArgs: acquireArgs,
+		heldArgs: heldArgs}, nil
+}
+
+// queryBool executes a query returning a single boolean or integer column,
+// NULL is false
+func queryBool(ctx context.Context, conn *sqlx.Conn, query string, args ...any) (bool, error) {
+	var result sql.NullBool
+	if err := conn.QueryRowxContext(ctx, query, args...).Scan(&result); nil != err {
+		return false, err
+	}
+	return result.Bool, nil
+}
+
+// discard conn rather than returning it to the pool so that the session, and
+// any lock it may hold, ends
+func discard(conn *sqlx.Conn) {
+	_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	_ = conn.Close()
+}
+
+type sessionHandle struct {
+	locker      *sessionLocker
+	name        string
+	mutex       sync.Mutex
+	conn        *sqlx.Conn
+	acquireArgs []any
+	heldArgs    []any
+}
+
+func (h *sessionHandle) Name() string {
+	return h.name
+}
+
+func (h *sessionHandle) Refresh(ctx context.Context) errors.TracerError {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if nil == h.conn {
+		return NewLostError(h.name)
+	}
+	held, err := queryBool(ctx, h.conn, h.locker.held, h.heldArgs...)
+	if nil != err {
+		return errors.Wrap(err)
+	}
+	if !held {
+		return NewLostError(h.name)
+	}
+	return nil
+}
+
+func (h *sessionHandle) Unlock(ctx context.Context) errors.TracerError {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if nil == h.conn {
+		return NewLostError(h.name)
+	}
+	released, err := queryBool(ctx, h.conn, h.locker.release, h.acquireArgs...)
+	conn := h.conn
+	h.conn = nil
+	if nil != err {
+		discard(conn)
+		return errors.Wrap(err)
+	}
+	_ = conn.Close()
+	if !released {
+		return NewLostError(h.name)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+// sessionConnector is a database/sql driver returning the queued result of
+// each query as a single row and column
+type sessionConnector struct {
+	mutex   sync.Mutex
+	results map[string][]driver.Value
+	queries []string
+	args    [][]driver.Value
+}
+
+func (c *sessionConnector) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *sessionConnector) Driver() driver.Driver                        { return nil }
+func (c *sessionConnector) Prepare(query string) (driver.Stmt, error) {
+	return &sessionStmt{connector: c, query: query}, nil
+}
+func (c *sessionConnector) Close() error              { return nil }
+func (c *sessionConnector) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type sessionStmt struct {
+	connector *sessionConnector
+	query     string
+}
+
+func (s *sessionStmt) Close() error  { return nil }
+func (s *sessionStmt) NumInput() int { return -1 }
+func (s *sessionStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+func (s *sessionStmt) Query(args []driver.Value) (driver.Rows, error) {
+	c := s.connector
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.queries = append(c.queries, s.query)
+	c.args = append(c.args, args)
+	results := c.results[s.query]
+	if len(results) == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	c.results[s.query] = results[1:]
+	return &sessionRows{value: results[0]}, nil
+}
+
+type sessionRows struct {
+	value any
+	read  bool
+}
+
+func (r *sessionRows) Columns() []string { return []string{"result"} }
+func (r *sessionRows) Close() error      { return nil }
+func (r *sessionRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	dest[0] = r.value
+	return nil
+}
+
+func newSessionDB(t *testing.T, connector *sessionConnector) *sqlx.DB {
+	db := sqlx.NewDb(sql.OpenDB(connector), "mysql")
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestMySQLLocker(t *testing.T) {
+	assert := assert1.New(t)
+	connector := &sessionConnector{results: map[string][]driver.Value{
+		mysqlAcquireQuery: {int64(0), int64(1)},
+		mysqlHeldQuery:    {int64(1), nil},
+		mysqlReleaseQuery: {int64(1)},
+	}}
+	locker := NewMySQLLocker(newSessionDB(t, connector))
+	ctx := context.Background()
+
+	// the lock is polled for until it is acquired
+	handle, err := locker.Lock(ctx, "job", time.Minute)
+	assert.NoError(err)
+	assert.NoError(handle.Refresh(ctx))
+	assert.True(IsLostError(handle.Refresh(ctx)))
+	assert.NoError(handle.Unlock(ctx))
+	assert.True(IsLostError(handle.Unlock(ctx)))
+	assert.Equal([]string{mysqlAcquireQuery, mysqlAcquireQuery, mysqlHeldQuery, mysqlHeldQuery,
+		mysqlReleaseQuery}, connector.queries)
+	for _, args := range connector.args {
+		assert.Equal([]driver.Value{"job"}, args)
+	}
+}
+
+func TestMySQLLocker_NotAcquired(t *testing.T) {
+	connector := &sessionConnector{results: map[string][]driver.Value{
+		mysqlAcquireQuery: {int64(0), int64(0), int64(0), int64(0), int64(0)},
+	}}
+	locker := NewMySQLLocker(newSessionDB(t, connector))
+	ctx, cancel := context.WithTimeout(context.Background(), 75*time.Millisecond)
+	defer cancel()
+	_, err := locker.Lock(ctx, "job", time.Minute)
+	assert1.True(t, IsNotAcquiredError(err))
+}
+
+func TestPostgreSQLLocker(t *testing.T) {
+	assert := assert1.New(t)
+	connector := &sessionConnector{results: map[string][]driver.Value{
+		postgresAcquireQuery: {true},
+		postgresHeldQuery:    {true},
+		postgresReleaseQuery: {false},
+	}}
+	locker := NewPostgreSQLLocker(newSessionDB(t, connector))
+	ctx := context.Background()
+
+	handle, err := locker.Lock(ctx, "job", time.Minute)
+	assert.NoError(err)
+	assert.NoError(handle.Refresh(ctx))
+	assert.True(IsLostError(handle.Unlock(ctx)))
+
+	key := advisoryKey("job")
+	assert.Equal([][]driver.Value{{key}, {int64(uint32(key >> 32)), int64(uint32(key))}, {key}},
+		connector.args)
+	assert.NotEqual(key, advisoryKey("other"))
+}
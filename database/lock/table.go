@@ -0,0 +1,135 @@
+package lock
+
+import (
+	"context"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database"
+	dberrors "github.com/beaconsoftwarellc/gadget/v2/database/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/database/record"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/generator"
+)
+
+const (
+	// LeaseTableName of the table used by NewTableLocker
+	LeaseTableName = "lock_lease"
+
+	// CreateLeaseTableSQL creates the lease table used by NewTableLocker, it
+	// should be created by a delta before the locker is used. Expiry is stored
+	// as nanoseconds since the epoch so that the table is the same for every
+	// dialect.
+	CreateLeaseTableSQL = "CREATE TABLE " + LeaseTableName + " (\n" +
+		"  name varchar(191) NOT NULL,\n" +
+		"  owner varchar(32) NOT NULL,\n" +
+		"  expires bigint NOT NULL,\n" +
+		"  PRIMARY KEY (name)\n" +
+		")"
+
+	leaseOwnerPrefix generator.IDPrefix = "LCK"
+)
+
+// LeaseRecord is a lock held in the lease table until it expires
+type LeaseRecord struct {
+	record.DefaultRecord
+	Name string `db:"name"`
+	// Owner is unique to the handle that holds the lock
+	Owner string `db:"owner"`
+	// Expires is when the lease ends in nanoseconds since the epoch
+	Expires int64 `db:"expires"`
+}
+
+// Initialize does nothing, the name is the primary key
+func (r *LeaseRecord) Initialize() {}
+
+// PrimaryKey of this record
+func (r *LeaseRecord) PrimaryKey() record.PrimaryKeyValue {
+	return record.NewPrimaryKey(r.Name)
+}
+
+// Key field name
+func (r *LeaseRecord) Key() string {
+	return "name"
+}
+
+// Meta object for this record
+func (r *LeaseRecord) Meta() qb.Table {
+	return LeaseMeta
+}
+
+// tableLocker holds leases on rows of the lease table
+type tableLocker struct {
+	db  database.API
+	now func() time.Time
+}
+
+// NewTableLocker using leases on rows of the lease table through db, this works
+// on every dialect. Locks expire once their ttl passes without a Refresh, which
+// is measured using the clocks of the processes holding the locks so they must
+// be synchronized. See CreateLeaseTableSQL.
+func NewTableLocker(db database.API) Locker {
+	return &tableLocker{db: db, now: time.Now}
+}
+
+func (l *tableLocker) Lock(ctx context.Context, name string, ttl time.Duration) (Handle, errors.TracerError) {
+	handle := &tableHandle{locker: l, name: name, owner: generator.ID(leaseOwnerPrefix),
+		ttl: leaseTTL(ttl)}
+	if err := poll(ctx, name, func() (bool, error) { return handle.acquire(ctx) }); nil != err {
+		return nil, err
+	}
+	return handle, nil
+}
+
+type tableHandle struct {
+	locker *tableLocker
+	name   string
+	owner  string
+	ttl    time.Duration
+}
+
+// expires is when a lease taken now ends
+func (h *tableHandle) expires() int64 {
+	return h.locker.now().Add(h.ttl).UnixNano()
+}
+
+// acquire the lock by taking over an expired lease or creating a new one
+func (h *tableHandle) acquire(ctx context.Context) (bool, error) {
+	taken, err := h.locker.db.UpdateWhereContext(ctx, &LeaseRecord{},
+		LeaseMeta.Name.Equal(h.name).And(LeaseMeta.Expires.LessThan(h.locker.now().UnixNano())),
+		qb.FieldValue{Field: LeaseMeta.Owner, Value: h.owner},
+		qb.FieldValue{Field: LeaseMeta.Expires, Value: h.expires()})
+	if nil != err || taken == 1 {
+		return nil == err, err
+	}
+	err = h.locker.db.CreateContext(ctx, &LeaseRecord{Name: h.name, Owner: h.owner, Expires: h.expires()})
+	if dberrors.IsDuplicateError(err) {
+		return false, nil
+	}
+	return nil == err, err
+}
+
+func (h *tableHandle) Name() string {
+	return h.name
+}
+
+func (h *tableHandle) Refresh(ctx context.Context) errors.TracerError {
+	extended, err := h.locker.db.UpdateWhereContext(ctx, &LeaseRecord{}, h.owned(),
+		qb.FieldValue{Field: LeaseMeta.Expires, Value: h.expires()})
+	if nil != err {
+		return err
+	}
+	if extended == 0 {
+		return NewLostError(h.name)
+	}
+	return nil
+}
+
+func (h *tableHandle) Unlock(ctx context.Context) errors.TracerError {
+	return h.locker.db.DeleteWhereContext(ctx, &LeaseRecord{}, h.owned())
+}
+
+// owned is the condition matching the lease while it is held by this handle
+func (h *tableHandle) owned() *qb.ConditionExpression {
+	return LeaseMeta.Name.Equal(h.name).And(LeaseMeta.Owner.Equal(h.owner))
+}
@@ -0,0 +1,77 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database"
+	dberrors "github.com/beaconsoftwarellc/gadget/v2/database/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	assert1 "github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTableLocker(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	api := database.NewMockAPI(ctrl)
+	now := time.Now()
+	locker := &tableLocker{db: api, now: func() time.Time { return now }}
+	ctx := context.Background()
+	expires := now.Add(time.Minute).UnixNano()
+
+	var owner string
+	gomock.InOrder(
+		// held by another owner
+		api.EXPECT().UpdateWhereContext(gomock.Any(), &LeaseRecord{},
+			LeaseMeta.Name.Equal("job").And(LeaseMeta.Expires.LessThan(now.UnixNano())),
+			gomock.Any(), qb.FieldValue{Field: LeaseMeta.Expires, Value: expires}).Return(int64(0), nil),
+		api.EXPECT().CreateContext(gomock.Any(), gomock.Any()).
+			Return(dberrors.NewDuplicateRecordError(dberrors.Insert, "", errors.New("duplicate"))),
+		// the expired lease is taken over
+		api.EXPECT().UpdateWhereContext(gomock.Any(), &LeaseRecord{}, gomock.Any(), gomock.Any(),
+			gomock.Any()).DoAndReturn(func(_ context.Context, _ *LeaseRecord, _ *qb.ConditionExpression,
+			fields ...qb.FieldValue) (int64, errors.TracerError) {
+			owner = fields[0].Value.(string)
+			return 1, nil
+		}),
+	)
+	handle, err := locker.Lock(ctx, "job", time.Minute)
+	assert.NoError(err)
+	assert.NotEmpty(owner)
+	owned := LeaseMeta.Name.Equal("job").And(LeaseMeta.Owner.Equal(owner))
+
+	api.EXPECT().UpdateWhereContext(gomock.Any(), &LeaseRecord{}, owned,
+		qb.FieldValue{Field: LeaseMeta.Expires, Value: expires}).Return(int64(1), nil)
+	assert.NoError(handle.Refresh(ctx))
+	api.EXPECT().UpdateWhereContext(gomock.Any(), &LeaseRecord{}, owned, gomock.Any()).Return(int64(0), nil)
+	assert.True(IsLostError(handle.Refresh(ctx)))
+
+	api.EXPECT().DeleteWhereContext(gomock.Any(), &LeaseRecord{}, owned).Return(nil)
+	assert.NoError(handle.Unlock(ctx))
+}
+
+func TestTableLocker_Create(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	api := database.NewMockAPI(ctrl)
+	locker := NewTableLocker(api)
+
+	api.EXPECT().UpdateWhereContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(int64(0), nil).Times(2)
+	api.EXPECT().CreateContext(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, lease *LeaseRecord) errors.TracerError {
+			assert.Equal("job", lease.Name)
+			assert.Greater(lease.Expires, time.Now().UnixNano())
+			return nil
+		})
+	_, err := locker.Lock(context.Background(), "job", 0)
+	assert.NoError(err)
+
+	expected := errors.New("connection lost")
+	api.EXPECT().CreateContext(gomock.Any(), gomock.Any()).Return(expected)
+	_, err = locker.Lock(context.Background(), "job", 0)
+	assert.EqualError(err, "connection lost")
+}
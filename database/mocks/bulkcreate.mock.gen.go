@@ -10,9 +10,11 @@
 package mocks
 
 import (
+	context "context"
 	sql "database/sql"
 	reflect "reflect"
 
+	database "github.com/beaconsoftwarellc/gadget/v2/database"
 	record "github.com/beaconsoftwarellc/gadget/v2/database/record"
 	errors "github.com/beaconsoftwarellc/gadget/v2/errors"
 	gomock "go.uber.org/mock/gomock"
@@ -57,6 +59,21 @@ func (mr *MockBulkCreateMockRecorder[T]) Commit() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockBulkCreate[T])(nil).Commit))
 }
 
+// CommitContext mocks base method.
+func (m *MockBulkCreate[T]) CommitContext(ctx context.Context) (sql.Result, errors.TracerError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitContext", ctx)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(errors.TracerError)
+	return ret0, ret1
+}
+
+// CommitContext indicates an expected call of CommitContext.
+func (mr *MockBulkCreateMockRecorder[T]) CommitContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitContext", reflect.TypeOf((*MockBulkCreate[T])(nil).CommitContext), ctx)
+}
+
 // Create mocks base method.
 func (m *MockBulkCreate[T]) Create(objs ...T) {
 	m.ctrl.T.Helper()
@@ -73,6 +90,37 @@ func (mr *MockBulkCreateMockRecorder[T]) Create(objs ...any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockBulkCreate[T])(nil).Create), objs...)
 }
 
+// CreateContext mocks base method.
+func (m *MockBulkCreate[T]) CreateContext(ctx context.Context, objs ...T) errors.TracerError {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range objs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateContext", varargs...)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// CreateContext indicates an expected call of CreateContext.
+func (mr *MockBulkCreateMockRecorder[T]) CreateContext(ctx any, objs ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, objs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateContext", reflect.TypeOf((*MockBulkCreate[T])(nil).CreateContext), varargs...)
+}
+
+// OnProgress mocks base method.
+func (m *MockBulkCreate[T]) OnProgress(fn func(database.BulkProgress)) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnProgress", fn)
+}
+
+// OnProgress indicates an expected call of OnProgress.
+func (mr *MockBulkCreateMockRecorder[T]) OnProgress(fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnProgress", reflect.TypeOf((*MockBulkCreate[T])(nil).OnProgress), fn)
+}
+
 // Reset mocks base method.
 func (m *MockBulkCreate[T]) Reset() errors.TracerError {
 	m.ctrl.T.Helper()
@@ -87,6 +135,20 @@ func (mr *MockBulkCreateMockRecorder[T]) Reset() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockBulkCreate[T])(nil).Reset))
 }
 
+// ResetContext mocks base method.
+func (m *MockBulkCreate[T]) ResetContext(ctx context.Context) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetContext", ctx)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// ResetContext indicates an expected call of ResetContext.
+func (mr *MockBulkCreateMockRecorder[T]) ResetContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetContext", reflect.TypeOf((*MockBulkCreate[T])(nil).ResetContext), ctx)
+}
+
 // Rollback mocks base method.
 func (m *MockBulkCreate[T]) Rollback() errors.TracerError {
 	m.ctrl.T.Helper()
@@ -10,9 +10,11 @@
 package mocks
 
 import (
+	context "context"
 	sql "database/sql"
 	reflect "reflect"
 
+	database "github.com/beaconsoftwarellc/gadget/v2/database"
 	record "github.com/beaconsoftwarellc/gadget/v2/database/record"
 	errors "github.com/beaconsoftwarellc/gadget/v2/errors"
 	gomock "go.uber.org/mock/gomock"
@@ -57,6 +59,33 @@ func (mr *MockBulkUpdateMockRecorder[T]) Commit() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockBulkUpdate[T])(nil).Commit))
 }
 
+// CommitContext mocks base method.
+func (m *MockBulkUpdate[T]) CommitContext(ctx context.Context) (sql.Result, errors.TracerError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitContext", ctx)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(errors.TracerError)
+	return ret0, ret1
+}
+
+// CommitContext indicates an expected call of CommitContext.
+func (mr *MockBulkUpdateMockRecorder[T]) CommitContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitContext", reflect.TypeOf((*MockBulkUpdate[T])(nil).CommitContext), ctx)
+}
+
+// OnProgress mocks base method.
+func (m *MockBulkUpdate[T]) OnProgress(fn func(database.BulkProgress)) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnProgress", fn)
+}
+
+// OnProgress indicates an expected call of OnProgress.
+func (mr *MockBulkUpdateMockRecorder[T]) OnProgress(fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnProgress", reflect.TypeOf((*MockBulkUpdate[T])(nil).OnProgress), fn)
+}
+
 // Reset mocks base method.
 func (m *MockBulkUpdate[T]) Reset() errors.TracerError {
 	m.ctrl.T.Helper()
@@ -71,6 +100,20 @@ func (mr *MockBulkUpdateMockRecorder[T]) Reset() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockBulkUpdate[T])(nil).Reset))
 }
 
+// ResetContext mocks base method.
+func (m *MockBulkUpdate[T]) ResetContext(ctx context.Context) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetContext", ctx)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// ResetContext indicates an expected call of ResetContext.
+func (mr *MockBulkUpdateMockRecorder[T]) ResetContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetContext", reflect.TypeOf((*MockBulkUpdate[T])(nil).ResetContext), ctx)
+}
+
 // Rollback mocks base method.
 func (m *MockBulkUpdate[T]) Rollback() errors.TracerError {
 	m.ctrl.T.Helper()
@@ -100,3 +143,22 @@ func (mr *MockBulkUpdateMockRecorder[T]) Update(objs ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockBulkUpdate[T])(nil).Update), objs...)
 }
+
+// UpdateContext mocks base method.
+func (m *MockBulkUpdate[T]) UpdateContext(ctx context.Context, objs ...T) errors.TracerError {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range objs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateContext", varargs...)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// UpdateContext indicates an expected call of UpdateContext.
+func (mr *MockBulkUpdateMockRecorder[T]) UpdateContext(ctx any, objs ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, objs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateContext", reflect.TypeOf((*MockBulkUpdate[T])(nil).UpdateContext), varargs...)
+}
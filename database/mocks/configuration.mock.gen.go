@@ -13,6 +13,8 @@ import (
 	reflect "reflect"
 	time "time"
 
+	database "github.com/beaconsoftwarellc/gadget/v2/database"
+	transaction "github.com/beaconsoftwarellc/gadget/v2/database/transaction"
 	log "github.com/beaconsoftwarellc/gadget/v2/log"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,6 +43,90 @@ func (m *MockConfiguration) EXPECT() *MockConfigurationMockRecorder {
 	return m.recorder
 }
 
+// AuditChanges mocks base method.
+func (m *MockConfiguration) AuditChanges() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditChanges")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// AuditChanges indicates an expected call of AuditChanges.
+func (mr *MockConfigurationMockRecorder) AuditChanges() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditChanges", reflect.TypeOf((*MockConfiguration)(nil).AuditChanges))
+}
+
+// BulkChunkBytes mocks base method.
+func (m *MockConfiguration) BulkChunkBytes() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkChunkBytes")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// BulkChunkBytes indicates an expected call of BulkChunkBytes.
+func (mr *MockConfigurationMockRecorder) BulkChunkBytes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkChunkBytes", reflect.TypeOf((*MockConfiguration)(nil).BulkChunkBytes))
+}
+
+// BulkChunkRows mocks base method.
+func (m *MockConfiguration) BulkChunkRows() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkChunkRows")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// BulkChunkRows indicates an expected call of BulkChunkRows.
+func (mr *MockConfigurationMockRecorder) BulkChunkRows() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkChunkRows", reflect.TypeOf((*MockConfiguration)(nil).BulkChunkRows))
+}
+
+// ConnectionMaxIdleTime mocks base method.
+func (m *MockConfiguration) ConnectionMaxIdleTime() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConnectionMaxIdleTime")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// ConnectionMaxIdleTime indicates an expected call of ConnectionMaxIdleTime.
+func (mr *MockConfigurationMockRecorder) ConnectionMaxIdleTime() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectionMaxIdleTime", reflect.TypeOf((*MockConfiguration)(nil).ConnectionMaxIdleTime))
+}
+
+// ConnectionMaxLifetime mocks base method.
+func (m *MockConfiguration) ConnectionMaxLifetime() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConnectionMaxLifetime")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// ConnectionMaxLifetime indicates an expected call of ConnectionMaxLifetime.
+func (mr *MockConfigurationMockRecorder) ConnectionMaxLifetime() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectionMaxLifetime", reflect.TypeOf((*MockConfiguration)(nil).ConnectionMaxLifetime))
+}
+
+// CursorKey mocks base method.
+func (m *MockConfiguration) CursorKey() []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CursorKey")
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// CursorKey indicates an expected call of CursorKey.
+func (mr *MockConfigurationMockRecorder) CursorKey() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CursorKey", reflect.TypeOf((*MockConfiguration)(nil).CursorKey))
+}
+
 // DatabaseConnection mocks base method.
 func (m *MockConfiguration) DatabaseConnection() string {
 	m.ctrl.T.Helper()
@@ -69,6 +155,20 @@ func (mr *MockConfigurationMockRecorder) DatabaseDialect() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DatabaseDialect", reflect.TypeOf((*MockConfiguration)(nil).DatabaseDialect))
 }
 
+// ExplainSlowQueries mocks base method.
+func (m *MockConfiguration) ExplainSlowQueries() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExplainSlowQueries")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ExplainSlowQueries indicates an expected call of ExplainSlowQueries.
+func (mr *MockConfigurationMockRecorder) ExplainSlowQueries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExplainSlowQueries", reflect.TypeOf((*MockConfiguration)(nil).ExplainSlowQueries))
+}
+
 // LoggedSlowQueries mocks base method.
 func (m *MockConfiguration) LoggedSlowQueries() map[string]time.Duration {
 	m.ctrl.T.Helper()
@@ -97,6 +197,34 @@ func (mr *MockConfigurationMockRecorder) Logger() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logger", reflect.TypeOf((*MockConfiguration)(nil).Logger))
 }
 
+// MaxIdleConnections mocks base method.
+func (m *MockConfiguration) MaxIdleConnections() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxIdleConnections")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxIdleConnections indicates an expected call of MaxIdleConnections.
+func (mr *MockConfigurationMockRecorder) MaxIdleConnections() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxIdleConnections", reflect.TypeOf((*MockConfiguration)(nil).MaxIdleConnections))
+}
+
+// MaxOpenConnections mocks base method.
+func (m *MockConfiguration) MaxOpenConnections() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxOpenConnections")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxOpenConnections indicates an expected call of MaxOpenConnections.
+func (mr *MockConfigurationMockRecorder) MaxOpenConnections() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxOpenConnections", reflect.TypeOf((*MockConfiguration)(nil).MaxOpenConnections))
+}
+
 // MaxQueryLimit mocks base method.
 func (m *MockConfiguration) MaxQueryLimit() uint {
 	m.ctrl.T.Helper()
@@ -111,6 +239,48 @@ func (mr *MockConfigurationMockRecorder) MaxQueryLimit() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxQueryLimit", reflect.TypeOf((*MockConfiguration)(nil).MaxQueryLimit))
 }
 
+// MaxTransactionAttempts mocks base method.
+func (m *MockConfiguration) MaxTransactionAttempts() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxTransactionAttempts")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxTransactionAttempts indicates an expected call of MaxTransactionAttempts.
+func (mr *MockConfigurationMockRecorder) MaxTransactionAttempts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxTransactionAttempts", reflect.TypeOf((*MockConfiguration)(nil).MaxTransactionAttempts))
+}
+
+// MaxWaitBetweenTransactionRetries mocks base method.
+func (m *MockConfiguration) MaxWaitBetweenTransactionRetries() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxWaitBetweenTransactionRetries")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// MaxWaitBetweenTransactionRetries indicates an expected call of MaxWaitBetweenTransactionRetries.
+func (mr *MockConfigurationMockRecorder) MaxWaitBetweenTransactionRetries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxWaitBetweenTransactionRetries", reflect.TypeOf((*MockConfiguration)(nil).MaxWaitBetweenTransactionRetries))
+}
+
+// MinimumWaitBetweenTransactionRetries mocks base method.
+func (m *MockConfiguration) MinimumWaitBetweenTransactionRetries() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MinimumWaitBetweenTransactionRetries")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// MinimumWaitBetweenTransactionRetries indicates an expected call of MinimumWaitBetweenTransactionRetries.
+func (mr *MockConfigurationMockRecorder) MinimumWaitBetweenTransactionRetries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MinimumWaitBetweenTransactionRetries", reflect.TypeOf((*MockConfiguration)(nil).MinimumWaitBetweenTransactionRetries))
+}
+
 // NumberOfRetries mocks base method.
 func (m *MockConfiguration) NumberOfRetries() int {
 	m.ctrl.T.Helper()
@@ -125,6 +295,90 @@ func (mr *MockConfigurationMockRecorder) NumberOfRetries() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NumberOfRetries", reflect.TypeOf((*MockConfiguration)(nil).NumberOfRetries))
 }
 
+// PoolStatsInterval mocks base method.
+func (m *MockConfiguration) PoolStatsInterval() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PoolStatsInterval")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// PoolStatsInterval indicates an expected call of PoolStatsInterval.
+func (mr *MockConfigurationMockRecorder) PoolStatsInterval() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PoolStatsInterval", reflect.TypeOf((*MockConfiguration)(nil).PoolStatsInterval))
+}
+
+// QueryInterceptors mocks base method.
+func (m *MockConfiguration) QueryInterceptors() []transaction.QueryInterceptor {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryInterceptors")
+	ret0, _ := ret[0].([]transaction.QueryInterceptor)
+	return ret0
+}
+
+// QueryInterceptors indicates an expected call of QueryInterceptors.
+func (mr *MockConfigurationMockRecorder) QueryInterceptors() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryInterceptors", reflect.TypeOf((*MockConfiguration)(nil).QueryInterceptors))
+}
+
+// ReadYourWritesWindow mocks base method.
+func (m *MockConfiguration) ReadYourWritesWindow() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadYourWritesWindow")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// ReadYourWritesWindow indicates an expected call of ReadYourWritesWindow.
+func (mr *MockConfigurationMockRecorder) ReadYourWritesWindow() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadYourWritesWindow", reflect.TypeOf((*MockConfiguration)(nil).ReadYourWritesWindow))
+}
+
+// ReplicaConnections mocks base method.
+func (m *MockConfiguration) ReplicaConnections() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplicaConnections")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// ReplicaConnections indicates an expected call of ReplicaConnections.
+func (mr *MockConfigurationMockRecorder) ReplicaConnections() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplicaConnections", reflect.TypeOf((*MockConfiguration)(nil).ReplicaConnections))
+}
+
+// ReplicaHealthCheckInterval mocks base method.
+func (m *MockConfiguration) ReplicaHealthCheckInterval() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplicaHealthCheckInterval")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// ReplicaHealthCheckInterval indicates an expected call of ReplicaHealthCheckInterval.
+func (mr *MockConfigurationMockRecorder) ReplicaHealthCheckInterval() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplicaHealthCheckInterval", reflect.TypeOf((*MockConfiguration)(nil).ReplicaHealthCheckInterval))
+}
+
+// ReplicaSelection mocks base method.
+func (m *MockConfiguration) ReplicaSelection() database.ReplicaSelection {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplicaSelection")
+	ret0, _ := ret[0].(database.ReplicaSelection)
+	return ret0
+}
+
+// ReplicaSelection indicates an expected call of ReplicaSelection.
+func (mr *MockConfigurationMockRecorder) ReplicaSelection() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplicaSelection", reflect.TypeOf((*MockConfiguration)(nil).ReplicaSelection))
+}
+
 // SlowQueryThreshold mocks base method.
 func (m *MockConfiguration) SlowQueryThreshold() time.Duration {
 	m.ctrl.T.Helper()
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"reflect"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+var mapper = reflectx.NewMapperFunc("db", sqlx.NameMapper)
+
+// lastRowSortValues returns the values of the passed sort fields on the last row
+// of target, which must be a pointer to a slice of structs or struct pointers.
+// False is returned when there is no limit, 0 or qb.NoLimit, or target holds
+// fewer rows than the limit, indicating that there is no next page.
+func lastRowSortValues(target any, fields []qb.TableField, limit uint) ([]any, bool, error) {
+	rows := reflect.Indirect(reflect.ValueOf(target))
+	if rows.Kind() != reflect.Slice {
+		return nil, false, errors.Newf("target must be a pointer to a slice, got %T", target)
+	}
+	if limit == 0 || limit == qb.NoLimit || rows.Len() == 0 || uint(rows.Len()) < limit {
+		return nil, false, nil
+	}
+	row := reflect.Indirect(rows.Index(rows.Len() - 1))
+	if row.Kind() != reflect.Struct {
+		return nil, false, errors.Newf("target must be a slice of structs, got %T", target)
+	}
+	names := mapper.TypeMap(row.Type()).Names
+	values := make([]any, len(fields))
+	for i, field := range fields {
+		info, ok := names[field.Name]
+		if !ok {
+			return nil, false, errors.Newf("%s has no field for sort column '%s'",
+				row.Type(), field.Name)
+		}
+		values[i] = reflectx.FieldByIndexesReadOnly(row, info.Index).Interface()
+	}
+	return values, true, nil
+}
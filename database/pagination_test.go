@@ -0,0 +1,121 @@
+package database
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/database/transaction"
+	"github.com/beaconsoftwarellc/gadget/v2/log"
+	assert1 "github.com/stretchr/testify/assert"
+	gomock "go.uber.org/mock/gomock"
+)
+
+type pageRow struct {
+	ID   string `db:"id"`
+	Name string `db:"name"`
+}
+
+func Test_lastRowSortValues(t *testing.T) {
+	assert := assert1.New(t)
+	fields := []qb.TableField{MetaTestRecord.Name, MetaTestRecord.ID}
+	rows := []*pageRow{{ID: "1", Name: "a"}, {ID: "2", Name: "b"}}
+
+	values, ok, err := lastRowSortValues(&rows, fields, 2)
+	assert.NoError(err)
+	assert.True(ok)
+	assert.Equal([]any{"b", "2"}, values)
+
+	_, ok, err = lastRowSortValues(&rows, fields, 3)
+	assert.NoError(err)
+	assert.False(ok)
+
+	// every row was returned without a limit
+	for _, limit := range []uint{0, qb.NoLimit} {
+		_, ok, err = lastRowSortValues(&rows, fields, limit)
+		assert.NoError(err)
+		assert.False(ok)
+	}
+
+	_, _, err = lastRowSortValues(rows[0], fields, 1)
+	assert.EqualError(err, "target must be a pointer to a slice, got *database.pageRow")
+
+	_, _, err = lastRowSortValues(&rows, []qb.TableField{{Name: "missing"}}, 1)
+	assert.EqualError(err, "database.pageRow has no field for sort column 'missing'")
+}
+
+func Test_api_NextPage(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	tx := transaction.NewMockTransaction(ctrl)
+	database := &api{
+		tx:            tx,
+		configuration: &InstanceConfig{CursorSecret: []byte("secret")},
+	}
+	query := qb.Select(MetaTestRecord.ID, MetaTestRecord.Name).From(MetaTestRecord).
+		OrderBy(MetaTestRecord.ID, qb.Ascending)
+	page := []*pageRow{{ID: "1"}, {ID: "2"}}
+	tx.EXPECT().SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_, target any, _ *qb.SelectQuery, _ qb.LimitOffset) error {
+			*(target.(*[]*pageRow)) = page
+			return nil
+		})
+	var target []*pageRow
+	cursor, err := database.NextPage(&target, query, "", 2)
+	assert.NoError(err)
+	assert.NotEmpty(cursor)
+
+	tx.EXPECT().SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_, target any, query *qb.SelectQuery, _ qb.LimitOffset) error {
+			sql, values, err := query.SQL(nil)
+			assert.NoError(err)
+			assert.Equal("SELECT `test_record`.`id`, `test_record`.`name` FROM `test_record` "+
+				"AS `test_record` WHERE `test_record`.`id` > ? ORDER BY `test_record`.`id` ASC", sql)
+			assert.Equal([]any{"2"}, values)
+			*(target.(*[]*pageRow)) = page[:1]
+			return nil
+		})
+	cursor, err = database.NextPage(&target, query, cursor, 2)
+	assert.NoError(err)
+	assert.Empty(cursor)
+
+	// there is no next page without a limit
+	tx.EXPECT().SelectContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_, target any, _ *qb.SelectQuery, _ qb.LimitOffset) error {
+			*(target.(*[]*pageRow)) = page
+			return nil
+		})
+	cursor, err = database.NextPage(&target, query, "", 0)
+	assert.NoError(err)
+	assert.Empty(cursor)
+
+	_, err = database.NextPage(&target, query, "invalid", 2)
+	assert.EqualError(err, "invalid cursor: malformed token")
+}
+
+func TestInstanceConfig_CursorKey(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	logger := log.NewMockLogger(ctrl)
+	// the missing secret is only reported when it is generated
+	logger.EXPECT().Warnf(gomock.Any()).Times(1)
+	config := &InstanceConfig{Log: logger}
+
+	keys := make([][]byte, 10)
+	var wg sync.WaitGroup
+	for i := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			keys[i] = config.CursorKey()
+		}()
+	}
+	wg.Wait()
+	assert.Len(keys[0], cursorKeyLength)
+	for _, key := range keys {
+		assert.Equal(keys[0], key)
+	}
+
+	secret := []byte("secret")
+	assert.Equal(secret, (&InstanceConfig{CursorSecret: secret, Log: logger}).CursorKey())
+}
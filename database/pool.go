@@ -0,0 +1,92 @@
+package database
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/log"
+)
+
+const poolSaturated = "database pool saturated, %d waits totalling %s in the last %s " +
+	"(%d of %d connections in use)"
+
+// Pool is implemented by the database handles that a connection pool can be
+// tuned on, such as sql.DB and sqlx.DB
+type Pool interface {
+	SetMaxOpenConns(n int)
+	SetMaxIdleConns(n int)
+	SetConnMaxLifetime(d time.Duration)
+	SetConnMaxIdleTime(d time.Duration)
+}
+
+// ConfigurePool applies the pool settings of cfg to pool, settings that are
+// zero are left at the database/sql defaults
+func ConfigurePool(pool Pool, cfg Configuration) {
+	if n := cfg.MaxOpenConnections(); n > 0 {
+		pool.SetMaxOpenConns(n)
+	}
+	if n := cfg.MaxIdleConnections(); n > 0 {
+		pool.SetMaxIdleConns(n)
+	}
+	if d := cfg.ConnectionMaxLifetime(); d > 0 {
+		pool.SetConnMaxLifetime(d)
+	}
+	if d := cfg.ConnectionMaxIdleTime(); d > 0 {
+		pool.SetConnMaxIdleTime(d)
+	}
+}
+
+// poolMonitor periodically compares the statistics of a pool and warns when
+// callers had to wait for a connection
+type poolMonitor struct {
+	stats    func() sql.DBStats
+	logger   log.Logger
+	interval time.Duration
+	last     sql.DBStats
+	stop     chan struct{}
+	stopped  sync.WaitGroup
+}
+
+func newPoolMonitor(stats func() sql.DBStats, interval time.Duration, logger log.Logger) *poolMonitor {
+	return &poolMonitor{stats: stats, logger: logger, interval: interval, last: stats(),
+		stop: make(chan struct{})}
+}
+
+// check the pool statistics, returning true if the wait count increased since
+// the last check
+func (m *poolMonitor) check() bool {
+	current := m.stats()
+	waits := current.WaitCount - m.last.WaitCount
+	waited := current.WaitDuration - m.last.WaitDuration
+	m.last = current
+	if waits <= 0 {
+		return false
+	}
+	m.logger.Warnf(poolSaturated, waits, waited, m.interval, current.InUse, current.MaxOpenConnections)
+	return true
+}
+
+// start checking the pool every interval until closed
+func (m *poolMonitor) start() {
+	m.stopped.Add(1)
+	go func() {
+		defer m.stopped.Done()
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				m.check()
+			}
+		}
+	}()
+}
+
+// Close stops the monitor
+func (m *poolMonitor) Close() {
+	close(m.stop)
+	m.stopped.Wait()
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/environment"
+	"github.com/beaconsoftwarellc/gadget/v2/log"
+	assert1 "github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type testPool struct {
+	maxOpen     int
+	maxIdle     int
+	maxLifetime time.Duration
+	maxIdleTime time.Duration
+}
+
+func (p *testPool) SetMaxOpenConns(n int)              { p.maxOpen = n }
+func (p *testPool) SetMaxIdleConns(n int)              { p.maxIdle = n }
+func (p *testPool) SetConnMaxLifetime(d time.Duration) { p.maxLifetime = d }
+func (p *testPool) SetConnMaxIdleTime(d time.Duration) { p.maxIdleTime = d }
+
+func TestConfigurePool(t *testing.T) {
+	assert := assert1.New(t)
+	// unset values are left at the database/sql defaults
+	pool := &testPool{maxIdle: 2}
+	ConfigurePool(pool, &InstanceConfig{})
+	assert.Equal(&testPool{maxIdle: 2}, pool)
+
+	ConfigurePool(pool, &InstanceConfig{MaxOpenConns: 20, MaxIdleConns: 5,
+		ConnMaxLifetime: time.Hour, ConnMaxIdleTime: time.Minute})
+	assert.Equal(&testPool{maxOpen: 20, maxIdle: 5, maxLifetime: time.Hour,
+		maxIdleTime: time.Minute}, pool)
+}
+
+func TestInstanceConfig_Environment(t *testing.T) {
+	assert := assert1.New(t)
+	config := &InstanceConfig{}
+	assert.NoError(environment.ProcessMap(config, map[string]string{
+		environment.NoS3EnvVar:         "true",
+		environment.NoSSMEnvVar:        "true",
+		"DATABASE_MAX_OPEN_CONNS":      "20",
+		"DATABASE_CONN_MAX_LIFETIME":   "1h",
+		"DATABASE_POOL_STATS_INTERVAL": "30s",
+	}, log.Global()))
+	assert.Equal(20, config.MaxOpenConnections())
+	assert.Equal(0, config.MaxIdleConnections())
+	assert.Equal(time.Hour, config.ConnectionMaxLifetime())
+	assert.Equal(time.Duration(0), config.ConnectionMaxIdleTime())
+	assert.Equal(30*time.Second, config.PoolStatsInterval())
+}
+
+func Test_poolMonitor_check(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	logger := log.NewMockLogger(ctrl)
+	stats := sql.DBStats{MaxOpenConnections: 10, InUse: 10, WaitCount: 3}
+	monitor := newPoolMonitor(func() sql.DBStats { return stats }, time.Minute, logger)
+
+	assert.False(monitor.check())
+
+	stats.WaitCount = 8
+	stats.WaitDuration = time.Second
+	logger.EXPECT().Warnf(poolSaturated, int64(5), time.Second, time.Minute, 10, 10)
+	assert.True(monitor.check())
+
+	stats.InUse = 4
+	assert.False(monitor.check())
+	monitor.start()
+	monitor.Close()
+}
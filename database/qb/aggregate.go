@@ -0,0 +1,157 @@
+package qb
+
+import (
+	"fmt"
+
+	"github.com/beaconsoftwarellc/gadget/v2/stringutil"
+)
+
+// AggregateFunction computes a single value from the rows in a group
+type AggregateFunction string
+
+const (
+	// CountFunction counts the non null values
+	CountFunction AggregateFunction = "COUNT"
+	// SumFunction totals the values
+	SumFunction AggregateFunction = "SUM"
+	// MinFunction returns the smallest value
+	MinFunction AggregateFunction = "MIN"
+	// MaxFunction returns the largest value
+	MaxFunction AggregateFunction = "MAX"
+	// AvgFunction returns the average of the values
+	AvgFunction AggregateFunction = "AVG"
+)
+
+// DefaultGroupConcatSeparator is the separator used by GroupConcat in MySQL
+// and SQLite when none is specified
+const DefaultGroupConcatSeparator = ","
+
+// dialectExpression is implemented by select expressions whose SQL differs
+// between dialects
+type dialectExpression interface {
+	dialectSQL(dialect Dialect) (string, []any, error)
+}
+
+// expressionSQL renders the passed select expression for the passed dialect
+func expressionSQL(dialect Dialect, expression SelectExpression) (string, []any, error) {
+	if dialectExp, ok := expression.(dialectExpression); ok {
+		return dialectExp.dialectSQL(dialect)
+	}
+	sql, values := expression.ParameterizedSQL()
+	return sql, values, nil
+}
+
+func withAlias(sql, alias string) string {
+	if stringutil.IsWhiteSpace(alias) {
+		return sql
+	}
+	return fmt.Sprintf("%s AS `%s`", sql, alias)
+}
+
+type aggregate struct {
+	function   AggregateFunction
+	expression SelectExpression
+	distinct   bool
+	alias      string
+}
+
+func (a aggregate) GetName() string {
+	return a.alias
+}
+
+func (a aggregate) GetTables() []string {
+	return a.expression.GetTables()
+}
+
+func (a aggregate) ParameterizedSQL() (string, []any) {
+	sql, values, _ := a.dialectSQL(MySQL)
+	return sql, values
+}
+
+func (a aggregate) dialectSQL(dialect Dialect) (string, []any, error) {
+	expSQL, values, err := expressionSQL(dialect, a.expression)
+	if nil != err {
+		return "", nil, err
+	}
+	sql := fmt.Sprintf("%s(%s%s)", a.function, distinctSQL(a.distinct), expSQL)
+	return withAlias(sql, a.alias), values, nil
+}
+
+// Aggregate the passed expression with the passed function. Pass an empty
+// aliasName to embed the result inside another SelectExpression, or a
+// non-empty aliasName when using as a top-level column.
+func Aggregate(function AggregateFunction, expression SelectExpression, aliasName string) SelectExpression {
+	return aggregate{function: function, expression: expression, alias: aliasName}
+}
+
+// AggregateDistinct aggregates the distinct values of the passed expression
+// with the passed function.
+func AggregateDistinct(function AggregateFunction, expression SelectExpression,
+	aliasName string) SelectExpression {
+	return aggregate{function: function, expression: expression, distinct: true, alias: aliasName}
+}
+
+// Min of the passed expression for use in or as a SelectExpression
+func Min(expression SelectExpression, aliasName string) SelectExpression {
+	return Aggregate(MinFunction, expression, aliasName)
+}
+
+// Max of the passed expression for use in or as a SelectExpression
+func Max(expression SelectExpression, aliasName string) SelectExpression {
+	return Aggregate(MaxFunction, expression, aliasName)
+}
+
+// Avg of the passed expression for use in or as a SelectExpression
+func Avg(expression SelectExpression, aliasName string) SelectExpression {
+	return Aggregate(AvgFunction, expression, aliasName)
+}
+
+type groupConcat struct {
+	expression SelectExpression
+	distinct   bool
+	separator  string
+	alias      string
+}
+
+func (gc groupConcat) GetName() string {
+	return gc.alias
+}
+
+func (gc groupConcat) GetTables() []string {
+	return gc.expression.GetTables()
+}
+
+func (gc groupConcat) ParameterizedSQL() (string, []any) {
+	sql, values, _ := gc.dialectSQL(MySQL)
+	return sql, values
+}
+
+func (gc groupConcat) dialectSQL(dialect Dialect) (string, []any, error) {
+	expSQL, values, err := expressionSQL(dialect, gc.expression)
+	if nil != err {
+		return "", nil, err
+	}
+	sql, err := dialect.GroupConcat(expSQL, gc.distinct, gc.separator)
+	if nil != err {
+		return "", nil, err
+	}
+	return withAlias(sql, gc.alias), values, nil
+}
+
+// GroupConcat joins the values of the passed expression in each group with the
+// passed separator. This is rendered as STRING_AGG for PostgreSQL.
+func GroupConcat(expression SelectExpression, separator, aliasName string) SelectExpression {
+	return groupConcat{expression: expression, separator: separator, alias: aliasName}
+}
+
+// GroupConcatDistinct joins the distinct values of the passed expression in
+// each group with the passed separator.
+func GroupConcatDistinct(expression SelectExpression, separator, aliasName string) SelectExpression {
+	return groupConcat{expression: expression, distinct: true, separator: separator, alias: aliasName}
+}
+
+// AliasField references a select expression by its alias for use in Having or
+// OrderBy. PostgreSQL does not allow aliases to be referenced in HAVING.
+func AliasField(aliasName string) TableField {
+	return TableField{Name: aliasName}
+}
@@ -0,0 +1,80 @@
+package qb
+
+import (
+	"testing"
+
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+func TestAggregate(t *testing.T) {
+	assert := assert1.New(t)
+	query := Select(Person.AddressID, Min(Person.Age, "youngest"), Max(Person.Age, "oldest"),
+		Avg(Person.Age, "average"), AggregateDistinct(CountFunction, Person.Name, "names")).
+		From(Person).
+		GroupBy(Person.AddressID)
+	sql, values, err := query.SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `person`.`address_id`, MIN(`person`.`age`) AS `youngest`, MAX(`person`.`age`) AS `oldest`, "+
+		"AVG(`person`.`age`) AS `average`, COUNT(DISTINCT `person`.`name`) AS `names` FROM `person` AS `person` "+
+		"GROUP BY `person`.`address_id`", sql)
+	assert.Empty(values)
+
+	// embedded in another expression
+	sql, _, err = Select(Coalesce(Max(Person.Age, ""), 0, "oldest")).From(Person).SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT COALESCE(MAX(`person`.`age`), ?) AS `oldest` FROM `person` AS `person`", sql)
+}
+
+func TestGroupConcat(t *testing.T) {
+	assert := assert1.New(t)
+	query := Select(Person.AddressID, GroupConcat(Person.Name, ", ", "names")).
+		From(Person).GroupBy(Person.AddressID)
+	sql, _, err := query.SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `person`.`address_id`, GROUP_CONCAT(`person`.`name` SEPARATOR ', ') AS `names` "+
+		"FROM `person` AS `person` GROUP BY `person`.`address_id`", sql)
+
+	sql, _, err = query.WithDialect(PostgreSQL).SQL(nil)
+	assert.NoError(err)
+	assert.Equal(`SELECT "person"."address_id", STRING_AGG(CAST("person"."name" AS TEXT), ', ') AS "names" `+
+		`FROM "person" AS "person" GROUP BY "person"."address_id"`, sql)
+
+	sql, _, err = query.WithDialect(SQLite).SQL(nil)
+	assert.NoError(err)
+	assert.Equal(`SELECT "person"."address_id", GROUP_CONCAT("person"."name", ', ') AS "names" `+
+		`FROM "person" AS "person" GROUP BY "person"."address_id"`, sql)
+
+	distinct := Select(GroupConcatDistinct(Person.Name, "'", "names")).From(Person)
+	sql, _, err = distinct.SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT GROUP_CONCAT(DISTINCT `person`.`name` SEPARATOR '''') AS `names` "+
+		"FROM `person` AS `person`", sql)
+	_, _, err = distinct.WithDialect(SQLite).SQL(nil)
+	assert.EqualError(err, "GROUP_CONCAT DISTINCT with a separator is not supported by the sqlite3 dialect")
+	sql, _, err = Select(GroupConcatDistinct(Person.Name, DefaultGroupConcatSeparator, "names")).From(Person).
+		WithDialect(SQLite).SQL(nil)
+	assert.NoError(err)
+	assert.Equal(`SELECT GROUP_CONCAT(DISTINCT "person"."name") AS "names" FROM "person" AS "person"`, sql)
+}
+
+func TestSelectQuery_Having(t *testing.T) {
+	assert := assert1.New(t)
+	query := Select(Person.AddressID, Count(Person.ID, "total")).
+		From(Person).
+		Where(Person.Age.GreaterThan(18)).
+		GroupBy(Person.AddressID).
+		Having(AliasField("total").GreaterThan(5).And(Person.AddressID.NotEqual(3))).
+		OrderBy(AliasField("total"), Descending)
+	sql, values, err := query.SQL(NewLimitOffset[int]().SetLimit(10))
+	assert.NoError(err)
+	assert.Equal("SELECT `person`.`address_id`, COUNT(`person`.`id`) AS `total` FROM `person` AS `person` "+
+		"WHERE `person`.`age` > ? GROUP BY `person`.`address_id` HAVING (`total` > ? AND "+
+		"`person`.`address_id` != ?) ORDER BY `total` DESC LIMIT 10", sql)
+	assert.Equal([]any{18, 5, 3}, values)
+
+	// tables in the having must be part of the query
+	_, _, err = Select(Count(Person.ID, "total")).From(Person).
+		Having(Address.Country.Equal("US")).SQL(nil)
+	assert.EqualError(err, "validation: the following tables are required but were not "+
+		"included in a join or from: [address]")
+}
@@ -59,10 +59,18 @@ func (exp *CaseExpression) GetName() string {
 
 // ParameterizedSQL that represents this case expression
 func (exp *CaseExpression) ParameterizedSQL() (string, []any) {
+	sql, values, _ := exp.dialectSQL(MySQL)
+	return sql, values
+}
+
+func (exp *CaseExpression) dialectSQL(dialect Dialect) (string, []any, error) {
 	sql := "CASE"
 	values := []any{}
 	for _, when := range exp.whens {
-		conditionSQL, conditionValues := when.condition.SQL()
+		conditionSQL, conditionValues, err := when.condition.sql(dialect)
+		if nil != err {
+			return "", nil, err
+		}
 		values = append(values, conditionValues...)
 		sql += fmt.Sprintf(" WHEN %s THEN ?", conditionSQL)
 		values = append(values, when.value)
@@ -75,5 +83,5 @@ func (exp *CaseExpression) ParameterizedSQL() (string, []any) {
 	if !stringutil.IsWhiteSpace(exp.alias) {
 		sql += fmt.Sprintf(" AS `%s`", exp.alias)
 	}
-	return sql, values
+	return sql, values, nil
 }
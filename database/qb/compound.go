@@ -0,0 +1,142 @@
+package qb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+)
+
+// CompoundOperator combines the rows returned by multiple select queries
+type CompoundOperator string
+
+const (
+	// UnionOperator returns the distinct rows from all queries
+	UnionOperator CompoundOperator = "UNION"
+	// UnionAllOperator returns all rows from all queries including duplicates
+	UnionAllOperator CompoundOperator = "UNION ALL"
+	// IntersectOperator returns the distinct rows returned by every query
+	IntersectOperator CompoundOperator = "INTERSECT"
+)
+
+// DefaultCompoundAlias is the alias used for a compound query when none is set
+const DefaultCompoundAlias = "compound"
+
+// CompoundQueryError is returned when a compound query can not be executed
+type CompoundQueryError struct {
+	reason string
+	trace  []string
+}
+
+func (err *CompoundQueryError) Error() string {
+	return fmt.Sprintf("validation: %s", err.reason)
+}
+
+// Trace returns the stack trace for the error
+func (err *CompoundQueryError) Trace() []string {
+	return err.trace
+}
+
+// NewCompoundQueryError instantiates a CompoundQueryError with a stack trace
+func NewCompoundQueryError(reason string, subs ...any) errors.TracerError {
+	return &CompoundQueryError{
+		reason: fmt.Sprintf(reason, subs...),
+		trace:  errors.GetStackTrace(),
+	}
+}
+
+// CompoundQuery combines multiple select queries using UNION, UNION ALL or
+// INTERSECT. It is used as a table so that the combined rows can be filtered,
+// sorted and limited by an outer query. Columns are named after the select
+// expressions of the first query.
+type CompoundQuery struct {
+	DerivedTable
+	operator CompoundOperator
+	queries  []*SelectQuery
+}
+
+// Union of the rows returned by the passed queries with duplicates removed
+func Union(queries ...*SelectQuery) *CompoundQuery {
+	return Compound(UnionOperator, queries...)
+}
+
+// UnionAll of the rows returned by the passed queries including duplicates
+func UnionAll(queries ...*SelectQuery) *CompoundQuery {
+	return Compound(UnionAllOperator, queries...)
+}
+
+// Intersect of the rows returned by the passed queries
+func Intersect(queries ...*SelectQuery) *CompoundQuery {
+	return Compound(IntersectOperator, queries...)
+}
+
+// Compound query combining the rows of the passed queries with the passed
+// operator.
+func Compound(operator CompoundOperator, queries ...*SelectQuery) *CompoundQuery {
+	cq := &CompoundQuery{
+		DerivedTable: DerivedTable{alias: DefaultCompoundAlias},
+		operator:     operator,
+		queries:      queries,
+	}
+	if len(queries) > 0 {
+		cq.query = queries[0]
+	}
+	return cq
+}
+
+// As sets the alias used to reference the columns of this compound query
+func (cq *CompoundQuery) As(alias string) *CompoundQuery {
+	cq.alias = alias
+	return cq
+}
+
+// Select from this compound query, when no select expressions are passed all
+// columns are selected. OrderBy, GroupBy, Where and LimitOffset on the returned
+// query apply to the combined rows.
+func (cq *CompoundQuery) Select(selectExpressions ...SelectExpression) *SelectQuery {
+	if len(selectExpressions) == 0 {
+		selectExpressions = []SelectExpression{cq.AllColumns()}
+	}
+	return Select(selectExpressions...).From(cq)
+}
+
+// Validate that each query in this compound query can be executed and that they
+// all select the same number of columns.
+func (cq *CompoundQuery) Validate() error {
+	return cq.validateDerived()
+}
+
+func (cq *CompoundQuery) validateDerived() error {
+	if len(cq.queries) < 2 {
+		return NewCompoundQueryError("%s requires at least 2 queries", cq.operator)
+	}
+	columns := len(cq.queries[0].selectExps)
+	for i, query := range cq.queries {
+		if !query.Validate() {
+			return query.err
+		}
+		if len(query.orderBy.expressions) > 0 {
+			return NewCompoundQueryError("%s query %d has an order by, order the combined rows instead",
+				cq.operator, i)
+		}
+		if len(query.selectExps) != columns {
+			return NewCompoundQueryError("%s query %d selects %d columns but expected %d",
+				cq.operator, i, len(query.selectExps), columns)
+		}
+	}
+	return nil
+}
+
+func (cq *CompoundQuery) derivedSQL(dialect Dialect) (string, []any, error) {
+	parts := make([]string, len(cq.queries))
+	values := []any{}
+	for i, query := range cq.queries {
+		sql, queryValues, err := query.render(dialect, nil)
+		if nil != err {
+			return "", nil, err
+		}
+		parts[i] = sql
+		values = append(values, queryValues...)
+	}
+	return "(" + strings.Join(parts, fmt.Sprintf(" %s ", cq.operator)) + ")", values, nil
+}
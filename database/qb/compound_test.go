@@ -0,0 +1,85 @@
+package qb
+
+import (
+	"testing"
+
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+func TestCompoundQuery_Union(t *testing.T) {
+	assert := assert1.New(t)
+	adults := Select(Person.ID, Person.Name).From(Person).Where(Person.Age.GreaterThan(18))
+	named := Select(Person.ID, Person.Name).From(Person).Where(Person.Name.Equal("bob"))
+	union := Union(adults, named).As("people")
+	query := union.Select().OrderBy(union.Field("name"), Ascending)
+	sql, values, err := query.SQL(NewLimitOffset[int]().SetLimit(10).SetOffset(20))
+	assert.NoError(err)
+	assert.Equal("SELECT `people`.* FROM (SELECT `person`.`id`, `person`.`name` FROM `person` AS `person` "+
+		"WHERE `person`.`age` > ? UNION SELECT `person`.`id`, `person`.`name` FROM `person` AS `person` "+
+		"WHERE `person`.`name` = ?) AS `people` ORDER BY `people`.`name` ASC LIMIT 10 OFFSET 20", sql)
+	assert.Equal([]any{18, "bob"}, values)
+	assert.Equal([]TableField{union.Field("id"), union.Field("name")}, union.ReadColumns())
+
+	// the outer query can filter the combined rows and values follow the union
+	sql, values, err = UnionAll(adults, named).Select().
+		Where(TableField{Table: DefaultCompoundAlias, Name: "id"}.NotEqual(3)).
+		WithDialect(PostgreSQL).SQL(nil)
+	assert.NoError(err)
+	assert.Equal(`SELECT "compound".* FROM (SELECT "person"."id", "person"."name" FROM "person" AS "person" `+
+		`WHERE "person"."age" > $1 UNION ALL SELECT "person"."id", "person"."name" FROM "person" AS "person" `+
+		`WHERE "person"."name" = $2) AS "compound" WHERE "compound"."id" != $3`, sql)
+	assert.Equal([]any{18, "bob", 3}, values)
+
+	// select expressions must reference the compound query
+	_, _, err = UnionAll(adults, named).Select(Person.ID).SQL(nil)
+	assert.EqualError(err, "validation: the following tables are required but were not "+
+		"included in a join or from: [person]")
+}
+
+func TestCompoundQuery_Intersect(t *testing.T) {
+	assert := assert1.New(t)
+	intersect := Intersect(
+		Select(Address.ID).From(Address).Where(Address.Country.Equal("US")),
+		Select(Person.AddressID).From(Person),
+	)
+	sql, values, err := intersect.Select(intersect.Field("id")).SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `compound`.`id` FROM (SELECT `address`.`id` FROM `address` AS `address` "+
+		"WHERE `address`.`country` = ? INTERSECT SELECT `person`.`address_id` FROM `person` AS `person`) "+
+		"AS `compound`", sql)
+	assert.Equal([]any{"US"}, values)
+}
+
+func TestCompoundQuery_Validate(t *testing.T) {
+	assert := assert1.New(t)
+	one := Select(Person.ID).From(Person)
+	two := Select(Person.ID, Person.Name).From(Person)
+
+	assert.EqualError(Union(one).Validate(), "validation: UNION requires at least 2 queries")
+	assert.EqualError(Union().Validate(), "validation: UNION requires at least 2 queries")
+	assert.EqualError(UnionAll(one, two).Validate(),
+		"validation: UNION ALL query 1 selects 2 columns but expected 1")
+	assert.EqualError(Union(one, Select(Person.ID).From(Person).OrderBy(Person.ID, Ascending)).Validate(),
+		"validation: UNION query 1 has an order by, order the combined rows instead")
+	assert.EqualError(Union(one, Select(Person.ID)).Validate(), "validation: from table must be set")
+	assert.NoError(Union(one, one).Validate())
+
+	// member queries can not be correlated
+	_, _, err := Union(one, Select(Person.ID).From(Person).Where(Address.ID.Equal(1))).Select().SQL(nil)
+	assert.EqualError(err, "validation: the following tables are required but were not "+
+		"included in a join or from: [address]")
+
+	// errors are surfaced through the outer query
+	_, _, err = Union(one, two).Select().SQL(nil)
+	assert.EqualError(err, "validation: UNION query 1 selects 2 columns but expected 1")
+}
+
+func TestCompoundQuery_Count(t *testing.T) {
+	assert := assert1.New(t)
+	union := Union(Select(Person.ID).From(Person), Select(Address.ID).From(Address))
+	query := union.Select().OrderBy(union.Field("id"), Descending)
+	sql, _, err := query.SelectFrom(NewCountExpression(union.GetName())).SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT COUNT(*) as count FROM (SELECT `person`.`id` FROM `person` AS `person` UNION "+
+		"SELECT `address`.`id` FROM `address` AS `address`) AS `compound` ORDER BY `compound`.`id` DESC", sql)
+}
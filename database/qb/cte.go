@@ -0,0 +1,244 @@
+package qb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+)
+
+// CommonTableExpressionError is returned when the WITH clause of a query is
+// invalid
+type CommonTableExpressionError struct {
+	reason string
+	trace  []string
+}
+
+func (err *CommonTableExpressionError) Error() string {
+	return fmt.Sprintf("validation: common table expression %s", err.reason)
+}
+
+// Trace returns the stack trace for the error
+func (err *CommonTableExpressionError) Trace() []string {
+	return err.trace
+}
+
+// NewCommonTableExpressionError instantiates a CommonTableExpressionError with
+// a stack trace
+func NewCommonTableExpressionError(reason string, subs ...any) errors.TracerError {
+	return &CommonTableExpressionError{
+		reason: fmt.Sprintf(reason, subs...),
+		trace:  errors.GetStackTrace(),
+	}
+}
+
+type cteDefinition struct {
+	name      string
+	recursive bool
+	operator  CompoundOperator
+	queries   []*SelectQuery
+}
+
+// CommonTableExpression is a named query declared in the WITH clause of a
+// query. It is referenced as a Table by the query it is declared on using From,
+// a Join or a subquery.
+type CommonTableExpression struct {
+	definition *cteDefinition
+	alias      string
+}
+
+// With declares a common table expression with the passed name and query. The
+// expression must be added to the query that uses it with With.
+func With(name string, query *SelectQuery) *CommonTableExpression {
+	return &CommonTableExpression{
+		definition: &cteDefinition{name: name, queries: []*SelectQuery{query}},
+		alias:      name,
+	}
+}
+
+// WithRecursive declares a recursive common table expression with the passed
+// name and anchor query. The recursive member that references this expression
+// is added with UnionAll or Union.
+func WithRecursive(name string, anchor *SelectQuery) *CommonTableExpression {
+	cte := With(name, anchor)
+	cte.definition.recursive = true
+	return cte
+}
+
+// UnionAll adds the recursive member of a recursive common table expression,
+// the passed query is repeated against the rows of the previous iteration until
+// it returns no rows.
+func (cte *CommonTableExpression) UnionAll(query *SelectQuery) *CommonTableExpression {
+	return cte.recurse(UnionAllOperator, query)
+}
+
+// Union adds the recursive member of a recursive common table expression
+// discarding duplicate rows, which stops cycles in the traversed data.
+func (cte *CommonTableExpression) Union(query *SelectQuery) *CommonTableExpression {
+	return cte.recurse(UnionOperator, query)
+}
+
+func (cte *CommonTableExpression) recurse(operator CompoundOperator,
+	query *SelectQuery) *CommonTableExpression {
+	cte.definition.operator = operator
+	cte.definition.queries = append(cte.definition.queries, query)
+	return cte
+}
+
+// Alias returns a reference to this common table expression with the passed
+// alias so that it can be used more than once in the same query.
+func (cte *CommonTableExpression) Alias(alias string) *CommonTableExpression {
+	return &CommonTableExpression{definition: cte.definition, alias: alias}
+}
+
+// Field on this common table expression with the passed name, this is the name
+// of the select expression in the anchor query.
+func (cte *CommonTableExpression) Field(name string) TableField {
+	return TableField{Name: name, Table: cte.alias}
+}
+
+// GetName of this common table expression
+func (cte *CommonTableExpression) GetName() string {
+	return cte.definition.name
+}
+
+// GetAlias of this reference to the common table expression
+func (cte *CommonTableExpression) GetAlias() string {
+	return cte.alias
+}
+
+// PrimaryKey of a common table expression is the first column
+func (cte *CommonTableExpression) PrimaryKey() TableField {
+	columns := cte.ReadColumns()
+	if len(columns) == 0 {
+		return cte.AllColumns()
+	}
+	return columns[0]
+}
+
+// AllColumns of this common table expression
+func (cte *CommonTableExpression) AllColumns() TableField {
+	return cte.Field("*")
+}
+
+// ReadColumns of this common table expression, one for each select expression
+// in the anchor query
+func (cte *CommonTableExpression) ReadColumns() []TableField {
+	anchor := cte.definition.queries[0]
+	if nil == anchor {
+		return []TableField{}
+	}
+	columns := make([]TableField, len(anchor.selectExps))
+	for i, exp := range anchor.selectExps {
+		columns[i] = cte.Field(exp.GetName())
+	}
+	return columns
+}
+
+// WriteColumns of a common table expression, they can not be written to
+func (cte *CommonTableExpression) WriteColumns() []TableField {
+	return []TableField{}
+}
+
+// SortBy the primary key of this common table expression
+func (cte *CommonTableExpression) SortBy() (TableField, OrderDirection) {
+	return cte.PrimaryKey(), Ascending
+}
+
+// source of the rows for this common table expression
+func (cte *CommonTableExpression) source() derivedSource {
+	def := cte.definition
+	if len(def.queries) == 1 {
+		return Derived(def.queries[0], def.name)
+	}
+	return Compound(def.operator, def.queries...)
+}
+
+// references returns true if the passed query reads from this common table
+// expression in its from or a join
+func (cte *CommonTableExpression) references(query *SelectQuery) bool {
+	tables := []Table{query.from}
+	for _, join := range query.joins {
+		tables = append(tables, join.table)
+	}
+	for _, table := range tables {
+		if ref, ok := table.(*CommonTableExpression); ok && ref.definition == cte.definition {
+			return true
+		}
+	}
+	return false
+}
+
+// withClause is the list of common table expressions declared on a query
+type withClause []*CommonTableExpression
+
+func (w withClause) add(ctes []*CommonTableExpression) withClause {
+	// copy so that queries sharing a with clause are not modified
+	w = append(withClause{}, w...)
+	for _, cte := range ctes {
+		if !w.contains(cte) {
+			w = append(w, cte)
+		}
+	}
+	return w
+}
+
+func (w withClause) contains(cte *CommonTableExpression) bool {
+	for _, existing := range w {
+		if existing.definition == cte.definition {
+			return true
+		}
+	}
+	return false
+}
+
+// validate the common table expressions, names must be unique and only
+// recursive expressions may reference themselves
+func (w withClause) validate() error {
+	names := make(map[string]bool, len(w))
+	for _, cte := range w {
+		def := cte.definition
+		if names[def.name] {
+			return NewCommonTableExpressionError("'%s' is declared more than once", def.name)
+		}
+		names[def.name] = true
+		if nil == def.queries[0] {
+			return NewCommonTableExpressionError("'%s' has no query", def.name)
+		}
+		if cte.references(def.queries[0]) {
+			if def.recursive {
+				return NewCommonTableExpressionError("the anchor of '%s' can not reference itself",
+					def.name)
+			}
+			return NewCommonTableExpressionError("'%s' references itself but is not recursive",
+				def.name)
+		}
+		if err := cte.source().validateDerived(); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// sql for the WITH clause in the passed dialect, false if there are no common
+// table expressions
+func (w withClause) sql(dialect Dialect) (string, []any, bool, error) {
+	if len(w) == 0 {
+		return "", nil, false, nil
+	}
+	keyword := "WITH"
+	expressions := make([]string, len(w))
+	values := []any{}
+	for i, cte := range w {
+		if cte.definition.recursive {
+			keyword = "WITH RECURSIVE"
+		}
+		sql, cteValues, err := cte.source().derivedSQL(dialect)
+		if nil != err {
+			return "", nil, false, err
+		}
+		expressions[i] = fmt.Sprintf("`%s` AS %s", cte.definition.name, sql)
+		values = append(values, cteValues...)
+	}
+	return keyword + " " + strings.Join(expressions, ", "), values, true, nil
+}
@@ -0,0 +1,112 @@
+package qb
+
+import (
+	"testing"
+
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+func TestCommonTableExpression_Select(t *testing.T) {
+	assert := assert1.New(t)
+	adults := With("adults", Select(Person.ID, Person.AddressID).From(Person).Where(Person.Age.GreaterThan(18)))
+	query := Select(adults.Field("id"), Address.Line).From(adults).With(adults).
+		Where(Address.Country.Equal("US"))
+	query.InnerJoin(Address).On(Address.ID, Equal, adults.Field("address_id"))
+	sql, values, err := query.SQL(nil)
+	assert.NoError(err)
+	assert.Equal("WITH `adults` AS (SELECT `person`.`id`, `person`.`address_id` FROM `person` AS `person` "+
+		"WHERE `person`.`age` > ?) SELECT `adults`.`id`, `address`.`line` FROM `adults` AS `adults` "+
+		"INNER JOIN `address` AS `address` ON `address`.`id` = `adults`.`address_id` "+
+		"WHERE `address`.`country` = ?", sql)
+	assert.Equal([]any{18, "US"}, values)
+	assert.Equal([]TableField{adults.Field("id"), adults.Field("address_id")}, adults.ReadColumns())
+
+	// referenced from a subquery and declared only once
+	query = Select(Person.Name).From(Person).With(adults, adults).
+		Where(Person.ID.In(Select(adults.Field("id")).From(adults)))
+	sql, values, err = query.WithDialect(PostgreSQL).SQL(nil)
+	assert.NoError(err)
+	assert.Equal(`WITH "adults" AS (SELECT "person"."id", "person"."address_id" FROM "person" AS "person" `+
+		`WHERE "person"."age" > $1) SELECT "person"."name" FROM "person" AS "person" `+
+		`WHERE "person"."id" IN (SELECT "adults"."id" FROM "adults" AS "adults")`, sql)
+	assert.Equal([]any{18}, values)
+}
+
+func TestCommonTableExpression_Recursive(t *testing.T) {
+	assert := assert1.New(t)
+	tree := WithRecursive("tree", Select(Person.ID, Person.AddressID).From(Person).Where(Person.ID.Equal(1)))
+	step := Select(Person.ID, Person.AddressID).From(Person)
+	step.InnerJoin(tree).On(tree.Field("id"), Equal, Person.AddressID)
+	tree.UnionAll(step)
+
+	query := Select(tree.AllColumns()).From(tree).With(tree).OrderBy(tree.Field("id"), Ascending)
+	sql, values, err := query.SQL(NewLimitOffset[int]().SetLimit(5))
+	assert.NoError(err)
+	assert.Equal("WITH RECURSIVE `tree` AS (SELECT `person`.`id`, `person`.`address_id` FROM `person` AS `person` "+
+		"WHERE `person`.`id` = ? UNION ALL SELECT `person`.`id`, `person`.`address_id` FROM `person` AS `person` "+
+		"INNER JOIN `tree` AS `tree` ON `tree`.`id` = `person`.`address_id`) "+
+		"SELECT `tree`.* FROM `tree` AS `tree` ORDER BY `tree`.`id` ASC LIMIT 5", sql)
+	assert.Equal([]any{1}, values)
+
+	// the anchor can not reference the expression
+	anchor := Select(Person.ID).From(Person)
+	loop := WithRecursive("loop", anchor)
+	anchor.InnerJoin(loop).On(loop.Field("id"), Equal, Person.ID)
+	_, _, err = Select(loop.AllColumns()).From(loop).With(loop).SQL(nil)
+	assert.EqualError(err, "validation: common table expression the anchor of 'loop' can not reference itself")
+
+	// only recursive expressions may reference themselves
+	self := Select(Person.ID).From(Person)
+	cte := With("self", self)
+	self.InnerJoin(cte).On(cte.Field("id"), Equal, Person.ID)
+	_, _, err = Select(cte.AllColumns()).From(cte).With(cte).SQL(nil)
+	assert.EqualError(err, "validation: common table expression 'self' references itself but is not recursive")
+
+	// the recursive member must select the same columns
+	bad := WithRecursive("bad", Select(Person.ID).From(Person)).
+		UnionAll(Select(Person.ID, Person.Name).From(Person))
+	_, _, err = Select(bad.AllColumns()).From(bad).With(bad).SQL(nil)
+	assert.EqualError(err, "validation: UNION ALL query 1 selects 2 columns but expected 1")
+}
+
+func TestCommonTableExpression_Validate(t *testing.T) {
+	assert := assert1.New(t)
+	one := With("one", Select(Person.ID).From(Person))
+	other := With("one", Select(Address.ID).From(Address))
+	_, _, err := Select(one.AllColumns()).From(one).With(one, other).SQL(nil)
+	assert.EqualError(err, "validation: common table expression 'one' is declared more than once")
+
+	invalid := With("invalid", Select(Person.ID))
+	_, _, err = Select(invalid.AllColumns()).From(invalid).With(invalid).SQL(nil)
+	assert.EqualError(err, "validation: from table must be set")
+
+	// aliased references to the expression
+	aliased := one.Alias("a")
+	sql, _, err := Select(aliased.Field("id")).From(aliased).With(one).SQL(nil)
+	assert.NoError(err)
+	assert.Equal("WITH `one` AS (SELECT `person`.`id` FROM `person` AS `person`) "+
+		"SELECT `a`.`id` FROM `one` AS `a`", sql)
+}
+
+func TestCommonTableExpression_UpdateDelete(t *testing.T) {
+	assert := assert1.New(t)
+	us := With("us", Select(Address.ID).From(Address).Where(Address.Country.Equal("US")))
+
+	sql, values, err := Update(Person).With(us).Set(Person.Name, "bob").
+		Where(Person.AddressID.In(Select(us.Field("id")).From(us))).SQL(NoLimit)
+	assert.NoError(err)
+	assert.Equal("WITH `us` AS (SELECT `address`.`id` FROM `address` AS `address` WHERE `address`.`country` = ?) "+
+		"UPDATE `person` SET  `person`.`name` = ? WHERE `person`.`address_id` IN (SELECT `us`.`id` FROM `us` AS `us`)",
+		sql)
+	assert.Equal([]any{"US", "bob"}, values)
+
+	sql, values, err = Delete(Person).With(us).
+		Where(Person.AddressID.In(Select(us.Field("id")).From(us))).SQL()
+	assert.NoError(err)
+	assert.Equal("WITH `us` AS (SELECT `address`.`id` FROM `address` AS `address` WHERE `address`.`country` = ?) "+
+		"DELETE FROM `person` WHERE `person`.`address_id` IN (SELECT `us`.`id` FROM `us` AS `us`)", sql)
+	assert.Equal([]any{"US"}, values)
+
+	_, _, err = Delete(Person).With(With("x", Select(Person.ID))).Where(Person.ID.Equal(1)).SQL()
+	assert.EqualError(err, "validation: from table must be set")
+}
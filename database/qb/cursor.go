@@ -0,0 +1,304 @@
+package qb
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+)
+
+const (
+	cursorSeparator = "."
+	cursorNull      = "n"
+	cursorInt       = "i"
+	cursorFloat     = "f"
+	cursorBool      = "b"
+	cursorString    = "s"
+	cursorBytes     = "y"
+	cursorTime      = "t"
+)
+
+// InvalidCursorError is returned when a cursor token is malformed, has been
+// tampered with or was issued for a query with a different sort.
+type InvalidCursorError struct {
+	reason string
+	trace  []string
+}
+
+func (err *InvalidCursorError) Error() string {
+	return fmt.Sprintf("invalid cursor: %s", err.reason)
+}
+
+// Trace returns the stack trace for the error
+func (err *InvalidCursorError) Trace() []string {
+	return err.trace
+}
+
+// NewInvalidCursorError instantiates an InvalidCursorError with a stack trace
+func NewInvalidCursorError(reason string, subs ...any) errors.TracerError {
+	return &InvalidCursorError{
+		reason: fmt.Sprintf(reason, subs...),
+		trace:  errors.GetStackTrace(),
+	}
+}
+
+// CursorEncoder creates and verifies the opaque cursor tokens used for keyset
+// pagination. Tokens are signed with HMAC-SHA256 so that a client cannot alter
+// the sort key values they contain.
+type CursorEncoder struct {
+	key []byte
+}
+
+// NewCursorEncoder that signs tokens with the passed secret key
+func NewCursorEncoder(key []byte) *CursorEncoder {
+	return &CursorEncoder{key: key}
+}
+
+type cursorValue struct {
+	Type  string          `json:"t"`
+	Value json.RawMessage `json:"v,omitempty"`
+}
+
+type cursorPayload struct {
+	Sort   string        `json:"s"`
+	Values []cursorValue `json:"v"`
+}
+
+// Encode the sort key values of the last row of a page of the passed query into
+// a cursor token. Values must be in the same order as the OrderBy fields on the
+// query.
+func (e *CursorEncoder) Encode(query *SelectQuery, values ...any) (string, error) {
+	sort := query.orderBy.signature()
+	if len(sort) == 0 {
+		return "", NewInvalidCursorError("query has no order by")
+	}
+	if len(values) != len(query.orderBy.expressions) {
+		return "", NewInvalidCursorError("expected %d values but got %d",
+			len(query.orderBy.expressions), len(values))
+	}
+	payload := cursorPayload{Sort: sort, Values: make([]cursorValue, len(values))}
+	for i, value := range values {
+		encoded, err := encodeCursorValue(value)
+		if nil != err {
+			return "", err
+		}
+		payload.Values[i] = encoded
+	}
+	raw, err := json.Marshal(payload)
+	if nil != err {
+		return "", errors.Wrap(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw) + cursorSeparator +
+		base64.RawURLEncoding.EncodeToString(e.sign(raw)), nil
+}
+
+// Decode the passed cursor token into the sort key values it contains,
+// verifying the signature and that it was issued for the sort on the passed
+// query.
+func (e *CursorEncoder) Decode(query *SelectQuery, token string) ([]any, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(token, cursorSeparator)
+	if !ok {
+		return nil, NewInvalidCursorError("malformed token")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if nil != err {
+		return nil, NewInvalidCursorError("malformed token")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if nil != err || !hmac.Equal(signature, e.sign(raw)) {
+		return nil, NewInvalidCursorError("signature mismatch")
+	}
+	payload := cursorPayload{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if err = decoder.Decode(&payload); nil != err {
+		return nil, NewInvalidCursorError("malformed token")
+	}
+	if payload.Sort != query.orderBy.signature() ||
+		len(payload.Values) != len(query.orderBy.expressions) {
+		return nil, NewInvalidCursorError("token was issued for a different sort")
+	}
+	values := make([]any, len(payload.Values))
+	for i, value := range payload.Values {
+		if values[i], err = decodeCursorValue(value); nil != err {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func (e *CursorEncoder) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, e.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func encodeCursorValue(value any) (cursorValue, error) {
+	var (
+		encoded = cursorValue{}
+		err     error
+	)
+	converted, err := driver.DefaultParameterConverter.ConvertValue(value)
+	if nil != err {
+		return encoded, NewInvalidCursorError("unsupported sort value %T: %s", value, err)
+	}
+	switch v := converted.(type) {
+	case nil:
+		encoded.Type = cursorNull
+	case int64:
+		// integers are encoded as strings so precision is not lost to float64
+		encoded.Type = cursorInt
+		encoded.Value, err = json.Marshal(strconv.FormatInt(v, 10))
+	case float64:
+		encoded.Type = cursorFloat
+		encoded.Value, err = json.Marshal(v)
+	case bool:
+		encoded.Type = cursorBool
+		encoded.Value, err = json.Marshal(v)
+	case string:
+		encoded.Type = cursorString
+		encoded.Value, err = json.Marshal(v)
+	case []byte:
+		encoded.Type = cursorBytes
+		encoded.Value, err = json.Marshal(v)
+	case time.Time:
+		encoded.Type = cursorTime
+		encoded.Value, err = json.Marshal(v.Format(time.RFC3339Nano))
+	default:
+		return encoded, NewInvalidCursorError("unsupported sort value %T", value)
+	}
+	return encoded, errors.Wrap(err)
+}
+
+func decodeCursorValue(value cursorValue) (any, error) {
+	var (
+		decoded any
+		err     error
+		s       string
+	)
+	switch value.Type {
+	case cursorNull:
+		return nil, nil
+	case cursorInt:
+		if err = json.Unmarshal(value.Value, &s); nil == err {
+			decoded, err = strconv.ParseInt(s, 10, 64)
+		}
+	case cursorFloat:
+		var f float64
+		err = json.Unmarshal(value.Value, &f)
+		decoded = f
+	case cursorBool:
+		var b bool
+		err = json.Unmarshal(value.Value, &b)
+		decoded = b
+	case cursorString:
+		err = json.Unmarshal(value.Value, &s)
+		decoded = s
+	case cursorBytes:
+		var b []byte
+		err = json.Unmarshal(value.Value, &b)
+		decoded = b
+	case cursorTime:
+		if err = json.Unmarshal(value.Value, &s); nil == err {
+			decoded, err = time.Parse(time.RFC3339Nano, s)
+		}
+	default:
+		return nil, NewInvalidCursorError("unknown value type '%s'", value.Type)
+	}
+	if nil != err {
+		return nil, NewInvalidCursorError("malformed value: %s", err)
+	}
+	return decoded, nil
+}
+
+// keysetCondition selects the rows that sort after the passed values. For the
+// sort (a ASC, b DESC) this is '(a > ?) OR (a = ? AND b < ?)'.
+func (ob *orderBy) keysetCondition(values []any) *ConditionExpression {
+	var condition *ConditionExpression
+	for i, exp := range ob.expressions {
+		comparison := GreaterThan
+		if exp.direction == Descending {
+			comparison = LessThan
+		}
+		term := FieldComparison(exp.field, comparison, values[i])
+		for j := i - 1; j >= 0; j-- {
+			term = &ConditionExpression{
+				left:     FieldComparison(ob.expressions[j].field, Equal, values[j]),
+				operator: And,
+				right:    term,
+			}
+		}
+		if nil == condition {
+			condition = term
+		} else {
+			condition = &ConditionExpression{left: condition, operator: Or, right: term}
+		}
+	}
+	return condition
+}
+
+// signature of this order by that identifies the sort a cursor was issued for
+func (ob *orderBy) signature() string {
+	parts := make([]string, len(ob.expressions))
+	for i, exp := range ob.expressions {
+		parts[i] = fmt.Sprintf("%s.%s %s", exp.field.Table, exp.field.Name, exp.direction)
+	}
+	return strings.Join(parts, ",")
+}
+
+// GetOrderBy fields on this query in the order they are sorted
+func (q *SelectQuery) GetOrderBy() []TableField {
+	fields := make([]TableField, len(q.orderBy.expressions))
+	for i, exp := range q.orderBy.expressions {
+		fields[i] = exp.field
+	}
+	return fields
+}
+
+// After returns a copy of this query that only selects rows that sort after
+// the row with the passed sort key values using the OrderBy fields on this
+// query (keyset pagination). Values must be in the same order as the OrderBy
+// fields. The OrderBy fields must not be nullable and should end with a unique
+// field (such as the primary key) so that the sort is stable.
+func (q *SelectQuery) After(values ...any) (*SelectQuery, error) {
+	if len(q.orderBy.expressions) == 0 {
+		return nil, NewInvalidCursorError("query has no order by")
+	}
+	if len(values) != len(q.orderBy.expressions) {
+		return nil, NewInvalidCursorError("expected %d values but got %d",
+			len(q.orderBy.expressions), len(values))
+	}
+	query := q.SelectFrom(q.selectExps...)
+	query.outfile = q.outfile
+	query.outfileOptions = q.outfileOptions
+	condition := q.orderBy.keysetCondition(values)
+	if nil != q.where.expression {
+		condition = &ConditionExpression{left: q.where.expression, operator: And, right: condition}
+	}
+	query.where = &whereCondition{expression: condition}
+	return query, nil
+}
+
+// NextPage returns a copy of this query that selects the page of rows after the
+// passed cursor token. An empty cursor returns a copy of this query that selects
+// the first page. See After.
+func (q *SelectQuery) NextPage(encoder *CursorEncoder, cursor string) (*SelectQuery, error) {
+	if cursor == "" {
+		query := q.SelectFrom(q.selectExps...)
+		query.outfile = q.outfile
+		query.outfileOptions = q.outfileOptions
+		return query, nil
+	}
+	values, err := encoder.Decode(q, cursor)
+	if nil != err {
+		return nil, err
+	}
+	return q.After(values...)
+}
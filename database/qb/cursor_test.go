@@ -0,0 +1,109 @@
+package qb
+
+import (
+	"testing"
+	"time"
+
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+func TestCursorEncoder(t *testing.T) {
+	assert := assert1.New(t)
+	encoder := NewCursorEncoder([]byte("secret"))
+	query := Select(Person.ID, Person.Name).From(Person).
+		OrderBy(Person.Name, Descending).
+		OrderBy(Person.ID, Ascending)
+
+	created := time.Date(2024, 2, 3, 4, 5, 6, 7, time.UTC)
+	token, err := encoder.Encode(query, "bob", int64(9007199254740993))
+	assert.NoError(err)
+	values, err := encoder.Decode(query, token)
+	assert.NoError(err)
+	assert.Equal([]any{"bob", int64(9007199254740993)}, values)
+
+	timeQuery := Select(Person.ID).From(Person).OrderBy(Person.Age, Ascending)
+	token, err = encoder.Encode(timeQuery, created)
+	assert.NoError(err)
+	values, err = encoder.Decode(timeQuery, token)
+	assert.NoError(err)
+	assert.True(created.Equal(values[0].(time.Time)))
+
+	_, err = encoder.Encode(query, "bob")
+	assert.EqualError(err, "invalid cursor: expected 2 values but got 1")
+	_, err = encoder.Encode(Select(Person.ID).From(Person), 1)
+	assert.EqualError(err, "invalid cursor: query has no order by")
+}
+
+func TestCursorEncoder_Decode_Invalid(t *testing.T) {
+	assert := assert1.New(t)
+	encoder := NewCursorEncoder([]byte("secret"))
+	query := Select(Person.ID).From(Person).OrderBy(Person.ID, Ascending)
+	token, err := encoder.Encode(query, 5)
+	assert.NoError(err)
+
+	_, err = NewCursorEncoder([]byte("other")).Decode(query, token)
+	assert.EqualError(err, "invalid cursor: signature mismatch")
+
+	tampered := []byte(token)
+	tampered[2] ^= 1
+	_, err = encoder.Decode(query, string(tampered))
+	assert.EqualError(err, "invalid cursor: signature mismatch")
+
+	_, err = encoder.Decode(query, "garbage")
+	assert.EqualError(err, "invalid cursor: malformed token")
+
+	_, err = encoder.Decode(Select(Person.ID).From(Person).OrderBy(Person.ID, Descending), token)
+	assert.EqualError(err, "invalid cursor: token was issued for a different sort")
+}
+
+func TestSelectQuery_After(t *testing.T) {
+	assert := assert1.New(t)
+	query := Select(Person.ID, Person.Name).From(Person).
+		Where(Person.Age.GreaterThan(18)).
+		OrderBy(Person.Name, Descending).
+		OrderBy(Person.ID, Ascending)
+	page, err := query.After("bob", 5)
+	assert.NoError(err)
+	sql, values, err := page.SQL(NewLimitOffset[int]().SetLimit(10))
+	assert.NoError(err)
+	assert.Equal("SELECT `person`.`id`, `person`.`name` FROM `person` AS `person` "+
+		"WHERE (`person`.`age` > ? AND (`person`.`name` < ? OR "+
+		"(`person`.`name` = ? AND `person`.`id` > ?))) "+
+		"ORDER BY `person`.`name` DESC, `person`.`id` ASC LIMIT 10", sql)
+	assert.Equal([]any{18, "bob", "bob", 5}, values)
+
+	// the original query is not modified
+	sql, values, err = query.SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `person`.`id`, `person`.`name` FROM `person` AS `person` "+
+		"WHERE `person`.`age` > ? ORDER BY `person`.`name` DESC, `person`.`id` ASC", sql)
+	assert.Equal([]any{18}, values)
+
+	_, err = query.After("bob")
+	assert.EqualError(err, "invalid cursor: expected 2 values but got 1")
+}
+
+func TestSelectQuery_NextPage(t *testing.T) {
+	assert := assert1.New(t)
+	encoder := NewCursorEncoder([]byte("secret"))
+	query := Select(Person.ID).From(Person).OrderBy(Person.ID, Ascending)
+
+	page, err := query.NextPage(encoder, "")
+	assert.NoError(err)
+	sql, _, err := page.SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `person`.`id` FROM `person` AS `person` ORDER BY `person`.`id` ASC", sql)
+
+	token, err := encoder.Encode(query, 5)
+	assert.NoError(err)
+	page, err = query.NextPage(encoder, token)
+	assert.NoError(err)
+	sql, values, err := page.SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `person`.`id` FROM `person` AS `person` "+
+		"WHERE `person`.`id` > ? ORDER BY `person`.`id` ASC", sql)
+	assert.Equal([]any{int64(5)}, values)
+
+	_, err = query.NextPage(encoder, token+"x")
+	assert.Error(err)
+}
@@ -16,12 +16,14 @@ import (
 
 // DeleteQuery for removing rows from a database
 type DeleteQuery struct {
+	with   withClause
 	tables []Table
 	from   Table
 	joins  []*Join
 	where  *whereCondition
 	// NICE TO HAVE: Add orderby and limit logic, order by and limit only apply to single table case
-	err error
+	dialect Dialect
+	err     error
 }
 
 // GetAlias of the passed table name in this query
@@ -29,6 +31,13 @@ func (q *DeleteQuery) GetAlias(tableName string) string {
 	return tableName
 }
 
+// With declares the passed common table expressions on this query so that
+// they can be referenced by subqueries in the where clause.
+func (q *DeleteQuery) With(ctes ...*CommonTableExpression) *DeleteQuery {
+	q.with = q.with.add(ctes)
+	return q
+}
+
 // From sets the primary table the query will find rows in.
 func (q *DeleteQuery) From(table Table) *DeleteQuery {
 	q.from = table
@@ -49,6 +58,17 @@ func (q *DeleteQuery) OuterJoin(direction JoinDirection, table Table) *Join {
 	return join
 }
 
+// WithDialect sets the dialect this query is rendered in, defaults to MySQL.
+func (q *DeleteQuery) WithDialect(dialect Dialect) *DeleteQuery {
+	q.dialect = dialect
+	return q
+}
+
+// GetDialect this query is rendered in.
+func (q *DeleteQuery) GetDialect() Dialect {
+	return dialectOrDefault(q.dialect)
+}
+
 // Where determines what rows to delete from.
 func (q *DeleteQuery) Where(condition *ConditionExpression) *DeleteQuery {
 	q.where.expression = condition
@@ -74,6 +94,17 @@ func (q *DeleteQuery) Validate() bool {
 		}
 	}
 
+	if err := q.with.validate(); nil != err {
+		q.err = err
+		return false
+	}
+
+	if !q.GetDialect().SupportsMultiTableDelete() &&
+		(len(q.joins) > 0 || len(q.tables) > 1 || (nil != q.from && len(q.tables) > 0)) {
+		q.err = NewUnsupportedError(q.GetDialect(), "multiple table DELETE")
+		return false
+	}
+
 	return true
 }
 
@@ -82,8 +113,20 @@ func (q *DeleteQuery) SQL() (string, []any, error) {
 	if !q.Validate() {
 		return "", nil, q.err
 	}
-	lines := []string{"DELETE"}
+	dialect := q.GetDialect()
+	lines := []string{}
 	values := []any{}
+
+	// WITH
+	with, withValues, ok, err := q.with.sql(dialect)
+	if nil != err {
+		return "", nil, err
+	}
+	if ok {
+		lines = append(lines, with)
+		values = append(values, withValues...)
+	}
+	lines = append(lines, "DELETE")
 	rowsInLines := make([]string, len(q.tables))
 
 	if len(q.tables) == 1 && nil == q.from {
@@ -100,16 +143,23 @@ func (q *DeleteQuery) SQL() (string, []any, error) {
 
 	// JOIN
 	for _, join := range q.joins {
-		joinSQL, joinValues := join.SQL()
+		joinSQL, joinValues, err := join.sql(dialect)
+		if nil != err {
+			return "", nil, err
+		}
 		lines = append(lines, joinSQL)
 		values = append(values, joinValues...)
 	}
 
 	// WHERE
-	if where, whereValues, ok := q.where.sql(); ok {
+	where, whereValues, ok, err := q.where.sql(dialect)
+	if nil != err {
+		return "", nil, err
+	}
+	if ok {
 		lines = append(lines, "WHERE", where)
 		values = append(values, whereValues...)
 	}
 
-	return strings.Join(lines, " "), values, q.err
+	return Render(dialect, strings.Join(lines, " ")), values, q.err
 }
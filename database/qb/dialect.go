@@ -0,0 +1,322 @@
+package qb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+)
+
+// Dialect of SQL that queries are rendered in. Queries are built using MySQL
+// syntax internally and translated to the dialect when SQL is generated.
+type Dialect interface {
+	// Name of this dialect, matches the name of the database driver
+	Name() string
+	// QuoteIdentifier for use as a table, alias or column name
+	QuoteIdentifier(identifier string) string
+	// Placeholder for the positional parameter at the passed (1 based) index
+	Placeholder(index int) string
+	// NullSafeEqual comparison operator for this dialect
+	NullSafeEqual() string
+	// QualifiedAssignments indicates whether the columns in an INSERT column
+	// list or UPDATE assignment may be qualified with the table name
+	QualifiedAssignments() bool
+	// Upsert clause that is appended to an insert statement so that the update
+	// fields are overwritten when the row conflicts on the conflict fields
+	Upsert(conflict []TableField, update []TableField) (string, error)
+	// UpdateIgnore returns the modifier that allows an update to continue on
+	// ignorable errors and whether it is supported
+	UpdateIgnore() (string, bool)
+	// SupportsUpdateLimit indicates whether ORDER BY and LIMIT may be used on an
+	// UPDATE statement
+	SupportsUpdateLimit() bool
+	// SupportsMultiTableDelete indicates whether a DELETE may reference more than
+	// one table through joins or multiple targets
+	SupportsMultiTableDelete() bool
+	// SupportsOutfile indicates whether SELECT ... INTO OUTFILE is supported
+	SupportsOutfile() bool
+	// GroupConcat aggregate of the passed expression SQL with the values joined
+	// by separator
+	GroupConcat(expression string, distinct bool, separator string) (string, error)
+}
+
+const (
+	// MySQLDriver is the driver name for MySQL
+	MySQLDriver = "mysql"
+	// PostgreSQLDriver is the driver name for PostgreSQL (lib/pq)
+	PostgreSQLDriver = "postgres"
+	// PGXDriver is the driver name for PostgreSQL (pgx)
+	PGXDriver = "pgx"
+	// SQLiteDriver is the driver name for SQLite (mattn/go-sqlite3)
+	SQLiteDriver = "sqlite3"
+	// SQLiteModernDriver is the driver name for SQLite (modernc.org/sqlite)
+	SQLiteModernDriver = "sqlite"
+)
+
+var (
+	// MySQL dialect, this is the default dialect for all queries
+	MySQL Dialect = mysqlDialect{}
+	// PostgreSQL dialect
+	PostgreSQL Dialect = postgresDialect{}
+	// SQLite dialect
+	SQLite Dialect = sqliteDialect{}
+)
+
+// DialectFor the passed driver name. Unknown driver names return the MySQL
+// dialect.
+func DialectFor(driverName string) Dialect {
+	switch strings.ToLower(strings.TrimSpace(driverName)) {
+	case PostgreSQLDriver, PGXDriver, "postgresql":
+		return PostgreSQL
+	case SQLiteDriver, SQLiteModernDriver:
+		return SQLite
+	default:
+		return MySQL
+	}
+}
+
+// NewUnsupportedError is returned when a query uses a feature the dialect
+// it is being rendered for does not support.
+func NewUnsupportedError(dialect Dialect, feature string) errors.TracerError {
+	return errors.Newf("%s is not supported by the %s dialect", feature, dialect.Name())
+}
+
+func dialectOrDefault(dialect Dialect) Dialect {
+	if nil == dialect {
+		return MySQL
+	}
+	return dialect
+}
+
+// Render a statement built with MySQL syntax in the passed dialect. Backtick
+// quoted identifiers are requoted, positional '?' placeholders are renumbered
+// and null safe comparisons are replaced. String literals are left untouched,
+// a backslash only escapes a quote in MySQL so it ends a literal otherwise.
+func Render(dialect Dialect, sql string) string {
+	dialect = dialectOrDefault(dialect)
+	if dialect == MySQL {
+		return sql
+	}
+	var (
+		builder    strings.Builder
+		runes      = []rune(sql)
+		index      = 0
+		identifier []rune
+		quoted     bool
+		literal    bool
+	)
+	builder.Grow(len(sql))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quoted:
+			if r == '`' {
+				builder.WriteString(dialect.QuoteIdentifier(string(identifier)))
+				identifier = identifier[:0]
+				quoted = false
+				continue
+			}
+			identifier = append(identifier, r)
+		case literal:
+			builder.WriteRune(r)
+			// a doubled quote ends and reopens the literal
+			literal = r != '\''
+		case r == '`':
+			quoted = true
+		case r == '\'':
+			literal = true
+			builder.WriteRune(r)
+		case r == '?':
+			index++
+			builder.WriteString(dialect.Placeholder(index))
+		case r == '<' && i+2 < len(runes) && runes[i+1] == '=' && runes[i+2] == '>':
+			builder.WriteString(dialect.NullSafeEqual())
+			i += 2
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string {
+	return MySQLDriver
+}
+
+func (mysqlDialect) QuoteIdentifier(identifier string) string {
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+func (mysqlDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (mysqlDialect) NullSafeEqual() string {
+	return string(NullSafeEqual)
+}
+
+func (mysqlDialect) QualifiedAssignments() bool {
+	return true
+}
+
+func (mysqlDialect) Upsert(_ []TableField, update []TableField) (string, error) {
+	updateFields := make([]string, len(update))
+	for i, col := range update {
+		updateFields[i] = fmt.Sprintf("%s = VALUES(%s)", col.SQL(), col.SQL())
+	}
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(updateFields, ", "), nil
+}
+
+func (mysqlDialect) UpdateIgnore() (string, bool) {
+	return "IGNORE", true
+}
+
+func (mysqlDialect) SupportsUpdateLimit() bool {
+	return true
+}
+
+func (mysqlDialect) SupportsMultiTableDelete() bool {
+	return true
+}
+
+func (mysqlDialect) SupportsOutfile() bool {
+	return true
+}
+
+func (mysqlDialect) GroupConcat(expression string, distinct bool, separator string) (string, error) {
+	separator = strings.ReplaceAll(separator, `\`, `\\`)
+	return fmt.Sprintf("GROUP_CONCAT(%s%s SEPARATOR %s)", distinctSQL(distinct), expression,
+		stringLiteral(separator)), nil
+}
+
+func distinctSQL(distinct bool) string {
+	if distinct {
+		return "DISTINCT "
+	}
+	return ""
+}
+
+// stringLiteral quotes the passed value for use as a string literal
+func stringLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// onConflict renders the standard 'ON CONFLICT ... DO UPDATE' clause shared by
+// PostgreSQL and SQLite
+func onConflict(dialect Dialect, conflict []TableField, update []TableField) (string, error) {
+	if len(conflict) == 0 {
+		return "", NewUnsupportedError(dialect, "upsert without a conflict target")
+	}
+	targets := make([]string, len(conflict))
+	for i, col := range conflict {
+		targets[i] = col.columnSQL()
+	}
+	updateFields := make([]string, len(update))
+	for i, col := range update {
+		updateFields[i] = fmt.Sprintf("%s = EXCLUDED.%s", col.columnSQL(), col.columnSQL())
+	}
+	return fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s",
+		strings.Join(targets, ", "), strings.Join(updateFields, ", ")), nil
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string {
+	return PostgreSQLDriver
+}
+
+func (postgresDialect) QuoteIdentifier(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func (postgresDialect) Placeholder(index int) string {
+	return fmt.Sprintf("$%d", index)
+}
+
+func (postgresDialect) NullSafeEqual() string {
+	return "IS NOT DISTINCT FROM"
+}
+
+func (postgresDialect) QualifiedAssignments() bool {
+	return false
+}
+
+func (d postgresDialect) Upsert(conflict []TableField, update []TableField) (string, error) {
+	return onConflict(d, conflict, update)
+}
+
+func (postgresDialect) UpdateIgnore() (string, bool) {
+	return "", false
+}
+
+func (postgresDialect) SupportsUpdateLimit() bool {
+	return false
+}
+
+func (postgresDialect) SupportsMultiTableDelete() bool {
+	return false
+}
+
+func (postgresDialect) SupportsOutfile() bool {
+	return false
+}
+
+func (postgresDialect) GroupConcat(expression string, distinct bool, separator string) (string, error) {
+	return fmt.Sprintf("STRING_AGG(%sCAST(%s AS TEXT), %s)", distinctSQL(distinct), expression,
+		stringLiteral(separator)), nil
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string {
+	return SQLiteDriver
+}
+
+func (sqliteDialect) QuoteIdentifier(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func (sqliteDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (sqliteDialect) NullSafeEqual() string {
+	return "IS"
+}
+
+func (sqliteDialect) QualifiedAssignments() bool {
+	return false
+}
+
+func (d sqliteDialect) Upsert(conflict []TableField, update []TableField) (string, error) {
+	return onConflict(d, conflict, update)
+}
+
+func (sqliteDialect) UpdateIgnore() (string, bool) {
+	return "OR IGNORE", true
+}
+
+func (sqliteDialect) SupportsUpdateLimit() bool {
+	return false
+}
+
+func (sqliteDialect) SupportsMultiTableDelete() bool {
+	return false
+}
+
+func (sqliteDialect) SupportsOutfile() bool {
+	return false
+}
+
+func (d sqliteDialect) GroupConcat(expression string, distinct bool, separator string) (string, error) {
+	if !distinct {
+		return fmt.Sprintf("GROUP_CONCAT(%s, %s)", expression, stringLiteral(separator)), nil
+	}
+	// distinct aggregates may only have a single argument in SQLite
+	if separator != DefaultGroupConcatSeparator {
+		return "", NewUnsupportedError(d, "GROUP_CONCAT DISTINCT with a separator")
+	}
+	return fmt.Sprintf("GROUP_CONCAT(DISTINCT %s)", expression), nil
+}
@@ -0,0 +1,202 @@
+package qb
+
+import (
+	"testing"
+
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+func TestDialectFor(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected Dialect
+	}{
+		{name: "mysql", expected: MySQL},
+		{name: "", expected: MySQL},
+		{name: "unknown", expected: MySQL},
+		{name: "postgres", expected: PostgreSQL},
+		{name: "pgx", expected: PostgreSQL},
+		{name: "sqlite3", expected: SQLite},
+		{name: "sqlite", expected: SQLite},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert1.Equal(t, tc.expected, DialectFor(tc.name))
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	var tests = []struct {
+		name     string
+		dialect  Dialect
+		sql      string
+		expected string
+	}{
+		{
+			name:     "mysql unchanged",
+			dialect:  MySQL,
+			sql:      "SELECT `a`.`b` FROM `a` AS `a` WHERE `a`.`b` = ? AND `a`.`c` <=> ?",
+			expected: "SELECT `a`.`b` FROM `a` AS `a` WHERE `a`.`b` = ? AND `a`.`c` <=> ?",
+		},
+		{
+			name:     "postgres",
+			dialect:  PostgreSQL,
+			sql:      "SELECT `a`.`b` FROM `a` AS `a` WHERE `a`.`b` = ? AND `a`.`c` <=> ?",
+			expected: `SELECT "a"."b" FROM "a" AS "a" WHERE "a"."b" = $1 AND "a"."c" IS NOT DISTINCT FROM $2`,
+		},
+		{
+			name:     "sqlite",
+			dialect:  SQLite,
+			sql:      "SELECT `a`.`b` FROM `a` AS `a` WHERE `a`.`b` = ? AND `a`.`c` <=> ?",
+			expected: `SELECT "a"."b" FROM "a" AS "a" WHERE "a"."b" = ? AND "a"."c" IS ?`,
+		},
+		{
+			name:     "literals untouched",
+			dialect:  PostgreSQL,
+			sql:      "SELECT 'it''s `a` ?' FROM `a` WHERE `a`.`b` = ?",
+			expected: `SELECT 'it''s ` + "`a`" + ` ?' FROM "a" WHERE "a"."b" = $1`,
+		},
+		{
+			name:     "backslash is not an escape",
+			dialect:  PostgreSQL,
+			sql:      "SELECT 'C:\\' FROM `a` WHERE `a`.`b` = ? AND `a`.`c` = '\\'",
+			expected: `SELECT 'C:\' FROM "a" WHERE "a"."b" = $1 AND "a"."c" = '\'`,
+		},
+		{
+			name:     "mysql backslash escape",
+			dialect:  MySQL,
+			sql:      "SELECT 'it\\'s ?' FROM `a` WHERE `a`.`b` = ?",
+			expected: "SELECT 'it\\'s ?' FROM `a` WHERE `a`.`b` = ?",
+		},
+		{
+			name:     "named parameters untouched",
+			dialect:  PostgreSQL,
+			sql:      "UPDATE `a` SET `b` = :b WHERE `a`.`id` = :id",
+			expected: `UPDATE "a" SET "b" = :b WHERE "a"."id" = :id`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert1.Equal(t, tc.expected, Render(tc.dialect, tc.sql))
+		})
+	}
+}
+
+func TestSelectQuery_Dialect(t *testing.T) {
+	assert := assert1.New(t)
+	sql, values, err := Select(Person.ID, Person.Name).From(Person).WithDialect(PostgreSQL).
+		Where(Person.Name.Equal("foo").And(Person.Age.GreaterThan(5))).
+		SQL(NewLimitOffset[int]().SetLimit(10).SetOffset(5))
+	assert.NoError(err)
+	assert.Equal(`SELECT "person"."id", "person"."name" FROM "person" AS "person" `+
+		`WHERE ("person"."name" = $1 AND "person"."age" > $2) LIMIT 10 OFFSET 5`, sql)
+	assert.Equal([]any{"foo", 5}, values)
+}
+
+func TestSelectQuery_SQLFor(t *testing.T) {
+	assert := assert1.New(t)
+	query := Select(Person.ID).From(Person).Where(Person.Name.Equal("foo"))
+	sql, values, err := query.SQLFor(PostgreSQL, nil)
+	assert.NoError(err)
+	assert.Equal(`SELECT "person"."id" FROM "person" AS "person" WHERE "person"."name" = $1`, sql)
+	assert.Equal([]any{"foo"}, values)
+	// the dialect of the query is unchanged
+	assert.Equal(MySQL, query.GetDialect())
+	sql, _, err = query.SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `person`.`id` FROM `person` AS `person` WHERE `person`.`name` = ?", sql)
+}
+
+func TestSelectQuery_Dialect_Outfile(t *testing.T) {
+	assert := assert1.New(t)
+	_, _, err := Select(Person.ID).From(Person).IntoOutfile("s3://foo", nil).
+		WithDialect(SQLite).SQL(nil)
+	assert.EqualError(err, "INTO OUTFILE is not supported by the sqlite3 dialect")
+}
+
+func TestInsertQuery_Dialect_Upsert(t *testing.T) {
+	assert := assert1.New(t)
+	query := Insert(Person.ID, Person.Name).
+		OnDuplicate([]TableField{Person.Name}).
+		ConflictOn(Person.ID)
+	sql, err := query.ParameterizedSQL()
+	assert.NoError(err)
+	assert.Equal("INSERT INTO `person` (`person`.`id`, `person`.`name`) VALUES (:id, :name) "+
+		"ON DUPLICATE KEY UPDATE `person`.`name` = VALUES(`person`.`name`)", sql)
+
+	sql, err = query.WithDialect(PostgreSQL).ParameterizedSQL()
+	assert.NoError(err)
+	assert.Equal(`INSERT INTO "person" ("id", "name") VALUES (:id, :name) `+
+		`ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"`, sql)
+
+	_, err = Insert(Person.ID, Person.Name).OnDuplicate([]TableField{Person.Name}).
+		WithDialect(SQLite).ParameterizedSQL()
+	assert.EqualError(err, "upsert without a conflict target is not supported by the sqlite3 dialect")
+}
+
+func TestUpdateQuery_Dialect(t *testing.T) {
+	assert := assert1.New(t)
+	sql, values, err := Update(Person).Set(Person.Name, "foo").
+		Where(Person.ID.Equal(1)).WithDialect(PostgreSQL).SQL(NoLimit)
+	assert.NoError(err)
+	assert.Equal(`UPDATE "person" SET  "name" = $1 WHERE "person"."id" = $2`, sql)
+	assert.Equal([]any{"foo", 1}, values)
+
+	_, _, err = Update(Person).Set(Person.Name, "foo").WithDialect(PostgreSQL).SQL(1)
+	assert.EqualError(err, "UPDATE with LIMIT is not supported by the postgres dialect")
+
+	query := Update(Person).Set(Person.Name, "foo").WithDialect(PostgreSQL)
+	query.SetIgnore(true)
+	_, _, err = query.SQL(NoLimit)
+	assert.EqualError(err, "UPDATE IGNORE is not supported by the postgres dialect")
+
+	query.WithDialect(SQLite)
+	sql, _, err = query.SQL(NoLimit)
+	assert.NoError(err)
+	assert.Equal(`UPDATE OR IGNORE "person" SET  "name" = ?`, sql)
+}
+
+func TestDeleteQuery_Dialect(t *testing.T) {
+	assert := assert1.New(t)
+	sql, values, err := Delete(Person).Where(Person.ID.Equal(1)).WithDialect(PostgreSQL).SQL()
+	assert.NoError(err)
+	assert.Equal(`DELETE FROM "person" WHERE "person"."id" = $1`, sql)
+	assert.Equal([]any{1}, values)
+
+	query := Delete(Person).From(Person).WithDialect(PostgreSQL)
+	query.InnerJoin(Address).On(Address.ID, Equal, Person.AddressID)
+	_, _, err = query.Where(Address.ID.Equal(1)).SQL()
+	assert.EqualError(err, "multiple table DELETE is not supported by the postgres dialect")
+}
+
+func TestSelectQuery_Dialect_Nested(t *testing.T) {
+	assert := assert1.New(t)
+	names := Select(Person.AddressID, GroupConcat(Person.Name, ", ", "names")).From(Person).
+		GroupBy(Person.AddressID)
+
+	// subquery
+	concat := Select(GroupConcat(Person.Name, ", ", "")).From(Person).Where(Person.AddressID.Equal(Address.ID))
+	query := Select(Address.ID).From(Address).Where(Address.Line.In(concat))
+	sql, _, err := query.WithDialect(PostgreSQL).SQL(nil)
+	assert.NoError(err)
+	assert.Equal(`SELECT "address"."id" FROM "address" AS "address" WHERE "address"."line" IN `+
+		`(SELECT STRING_AGG(CAST("person"."name" AS TEXT), ', ') FROM "person" AS "person" `+
+		`WHERE "person"."address_id" = "address"."id")`, sql)
+
+	// common table expression
+	cte := With("names", names)
+	sql, _, err = Select(cte.Field("names")).From(cte).With(cte).WithDialect(PostgreSQL).SQL(nil)
+	assert.NoError(err)
+	assert.Equal(`WITH "names" AS (SELECT "person"."address_id", STRING_AGG(CAST("person"."name" AS TEXT), ', ') `+
+		`AS "names" FROM "person" AS "person" GROUP BY "person"."address_id") `+
+		`SELECT "names"."names" FROM "names" AS "names"`, sql)
+
+	// compound query member
+	union := UnionAll(names, Select(Address.ID, Address.Line).From(Address))
+	sql, _, err = union.Select().WithDialect(PostgreSQL).SQL(nil)
+	assert.NoError(err)
+	assert.Equal(`SELECT "compound".* FROM (SELECT "person"."address_id", STRING_AGG(CAST("person"."name" AS TEXT), `+
+		`', ') AS "names" FROM "person" AS "person" GROUP BY "person"."address_id" UNION ALL `+
+		`SELECT "address"."id", "address"."line" FROM "address" AS "address") AS "compound"`, sql)
+}
@@ -14,10 +14,11 @@ const (
 )
 
 type expressionUnion struct {
-	value  any
-	field  *TableField
-	multi  []expressionUnion
-	binary *binaryExpression
+	value    any
+	field    *TableField
+	multi    []expressionUnion
+	binary   *binaryExpression
+	subquery *SelectQuery
 }
 
 func newUnion(values ...any) expressionUnion {
@@ -28,6 +29,8 @@ func newUnion(values ...any) expressionUnion {
 			return expressionUnion{field: &v}
 		case *binaryExpression:
 			return expressionUnion{binary: v}
+		case *SelectQuery:
+			return expressionUnion{subquery: v}
 		}
 
 		return expressionUnion{value: values[0]}
@@ -56,6 +59,21 @@ func (union expressionUnion) isBinary() bool {
 	return nil != union.binary
 }
 
+func (union expressionUnion) isSubquery() bool {
+	return nil != union.subquery
+}
+
+func (union expressionUnion) subqueries() []*SelectQuery {
+	if union.isSubquery() {
+		return []*SelectQuery{union.subquery}
+	}
+	var subqueries []*SelectQuery
+	for _, exp := range union.multi {
+		subqueries = append(subqueries, exp.subqueries()...)
+	}
+	return subqueries
+}
+
 func (union expressionUnion) getTables() []string {
 	if union.isField() {
 		return union.field.GetTables()
@@ -65,43 +83,58 @@ func (union expressionUnion) getTables() []string {
 			tables = append(tables, exp.getTables()...)
 		}
 		return tables
+	} else if union.isSubquery() {
+		return union.subquery.correlatedTables()
 	} else {
 		return []string{}
 	}
 }
 
-func (union expressionUnion) sql() (string, []any) {
+func (union expressionUnion) sql(dialect Dialect) (string, []any, error) {
 	var sql string
 	values := []any{}
 
 	switch {
 	case union.isMulti():
 		sa := make([]string, len(union.multi))
-		var subvalues []any
 		for i, exp := range union.multi {
-			sa[i], subvalues = exp.sql()
+			var (
+				subvalues []any
+				err       error
+			)
+			sa[i], subvalues, err = exp.sql(dialect)
+			if nil != err {
+				return "", nil, err
+			}
 			values = append(values, subvalues...)
 		}
 		sql = "(" + strings.Join(sa, ", ") + ")"
 	case union.isField():
 		sql = union.field.SQL()
+	case union.isSubquery():
+		return subquerySQL(dialect, union.subquery)
 	case SQLNow == union.value || SQLNull == union.value:
 		sql = fmt.Sprintf("%s", union.value)
 	case union.isString() && strings.HasPrefix(union.value.(string), ":"):
 		sql = fmt.Sprintf("%s", union.value)
 	case union.isBinary():
-		subsql, subvalues := union.binary.SQL()
-		values = append(values, subvalues...)
-		sql = subsql
+		return union.binary.sql(dialect)
 	default:
 		sql = "?"
 		values = append(values, union.value)
 	}
-	return sql, values
+	return sql, values, nil
 }
 
 type comparisonExpression interface {
-	SQL() (string, []any)
+	sql(dialect Dialect) (string, []any, error)
+}
+
+// assignmentExpression is a comparison that can also be rendered without
+// qualifying the left hand column with its table
+type assignmentExpression interface {
+	comparisonExpression
+	unqualifiedSQL(dialect Dialect) (string, []any, error)
 }
 
 type parameterExpression struct {
@@ -109,9 +142,28 @@ type parameterExpression struct {
 	comparison Comparison
 }
 
-func (be parameterExpression) SQL() (string, []any) {
+func (be parameterExpression) sql(Dialect) (string, []any, error) {
 	left := be.left.SQL()
-	return fmt.Sprintf("%s %s :%s", left, be.comparison, be.left.GetName()), make([]any, 0)
+	return fmt.Sprintf("%s %s :%s", left, be.comparison, be.left.GetName()), make([]any, 0), nil
+}
+
+func (be parameterExpression) unqualifiedSQL(Dialect) (string, []any, error) {
+	left := be.left.columnSQL()
+	return fmt.Sprintf("%s %s :%s", left, be.comparison, be.left.GetName()), make([]any, 0), nil
+}
+
+type incrementExpression struct {
+	field TableField
+}
+
+func (ie incrementExpression) sql(Dialect) (string, []any, error) {
+	left := ie.field.SQL()
+	return fmt.Sprintf("%s = %s + 1", left, left), make([]any, 0), nil
+}
+
+func (ie incrementExpression) unqualifiedSQL(Dialect) (string, []any, error) {
+	left := ie.field.columnSQL()
+	return fmt.Sprintf("%s = %s + 1", left, left), make([]any, 0), nil
 }
 
 type binaryExpression struct {
@@ -120,15 +172,28 @@ type binaryExpression struct {
 	right      expressionUnion
 }
 
-func (be binaryExpression) SQL() (string, []any) {
-	left := be.left.SQL()
-	right, values := be.right.sql()
-	return fmt.Sprintf("%s %s %s", left, be.comparison, right), values
+func (be binaryExpression) sql(dialect Dialect) (string, []any, error) {
+	return be.render(dialect, be.left.SQL())
+}
+
+func (be binaryExpression) unqualifiedSQL(dialect Dialect) (string, []any, error) {
+	return be.render(dialect, be.left.columnSQL())
+}
+
+// render this expression in the passed dialect with the passed SQL for the
+// left hand column
+func (be binaryExpression) render(dialect Dialect, left string) (string, []any, error) {
+	right, values, err := be.right.sql(dialect)
+	if nil != err {
+		return "", nil, err
+	}
+	return fmt.Sprintf("%s %s %s", left, be.comparison, right), values, nil
 }
 
 // ConditionExpression represents an expression that can be used as a condition in a where or join on.
 type ConditionExpression struct {
 	binary   *binaryExpression
+	exists   *existsExpression
 	left     *ConditionExpression
 	operator string
 	right    *ConditionExpression
@@ -140,6 +205,8 @@ func (exp *ConditionExpression) Tables() []string {
 	if nil != exp.binary {
 		tables = append(tables, exp.binary.left.GetTables()...)
 		tables = append(tables, exp.binary.right.getTables()...)
+	} else if nil != exp.exists {
+		tables = append(tables, exp.exists.query.correlatedTables()...)
 	} else {
 		tables = append(tables, exp.left.Tables()...)
 		tables = append(tables, exp.right.Tables()...)
@@ -166,6 +233,12 @@ func FieldNotIn(left TableField, notIn ...any) *ConditionExpression {
 }
 
 func fieldCollectionComparison(left TableField, comparison Comparison, collection ...any) *ConditionExpression {
+	if len(collection) == 1 {
+		if subquery, ok := collection[0].(*SelectQuery); ok {
+			return &ConditionExpression{binary: &binaryExpression{left: left, comparison: comparison,
+				right: newUnion(subquery)}}
+		}
+	}
 	// swap any 'nils' for sql null
 	rightValues := make([]any, len(collection))
 	for i, value := range collection {
@@ -219,13 +292,42 @@ func (exp *ConditionExpression) XOr(expression *ConditionExpression) *ConditionE
 	return exp
 }
 
-// SQL returns this condition expression as a SQL expression.
+// SQL returns this condition expression as a SQL expression. Subqueries that
+// can not be rendered are reported by the SQL of the enclosing query.
 func (exp *ConditionExpression) SQL() (string, []any) {
+	sql, values, _ := exp.sql(MySQL)
+	return sql, values
+}
+
+// sql of this condition expression in the passed dialect or the error
+// rendering a subquery
+func (exp *ConditionExpression) sql(dialect Dialect) (string, []any, error) {
 	if nil != exp.binary {
-		return exp.binary.SQL()
+		return exp.binary.sql(dialect)
+	}
+	if nil != exp.exists {
+		return exp.exists.sql(dialect)
+	}
+	lsql, values, err := exp.left.sql(dialect)
+	if nil != err {
+		return "", nil, err
+	}
+	rsql, rvalues, err := exp.right.sql(dialect)
+	if nil != err {
+		return "", nil, err
 	}
-	lsql, values := exp.left.SQL()
-	rsql, rvalues := exp.right.SQL()
 	values = append(values, rvalues...)
-	return fmt.Sprintf("(%s %s %s)", lsql, exp.operator, rsql), values
+	return fmt.Sprintf("(%s %s %s)", lsql, exp.operator, rsql), values, nil
+}
+
+// subqueries used in this expression or it's sub expressions.
+func (exp *ConditionExpression) subqueries() []*SelectQuery {
+	switch {
+	case nil != exp.binary:
+		return exp.binary.right.subqueries()
+	case nil != exp.exists:
+		return []*SelectQuery{exp.exists.query}
+	default:
+		return append(exp.left.subqueries(), exp.right.subqueries()...)
+	}
 }
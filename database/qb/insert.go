@@ -13,6 +13,8 @@ type InsertQuery struct {
 	values            [][]any
 	onDuplicate       []TableField
 	onDuplicateValues []any
+	conflict          []TableField
+	dialect           Dialect
 	err               error
 }
 
@@ -32,6 +34,25 @@ func (q *InsertQuery) OnDuplicate(fields []TableField) *InsertQuery {
 	return q
 }
 
+// ConflictOn sets the fields (usually the primary key) that identify a
+// duplicate row for OnDuplicate. Dialects other than MySQL require this to be
+// set in order to upsert.
+func (q *InsertQuery) ConflictOn(fields ...TableField) *InsertQuery {
+	q.conflict = append(q.conflict, fields...)
+	return q
+}
+
+// WithDialect sets the dialect this query is rendered in, defaults to MySQL.
+func (q *InsertQuery) WithDialect(dialect Dialect) *InsertQuery {
+	q.dialect = dialect
+	return q
+}
+
+// GetDialect this query is rendered in.
+func (q *InsertQuery) GetDialect() Dialect {
+	return dialectOrDefault(q.dialect)
+}
+
 // GetAlias of the passed table name in this query.
 func (q *InsertQuery) GetAlias(tableName string) string {
 	return tableName
@@ -59,10 +80,15 @@ func (q *InsertQuery) getSQL(parameterized bool) (string, error) {
 	if len(q.columns) == 0 {
 		return "", errors.New("no columns specified for insert")
 	}
+	dialect := q.GetDialect()
 	colExp := make([]string, len(q.columns))
 	valuePlaces := make([]string, len(q.columns))
 	for i, col := range q.columns {
-		colExp[i] = col.SQL()
+		if dialect.QualifiedAssignments() {
+			colExp[i] = col.SQL()
+		} else {
+			colExp[i] = col.columnSQL()
+		}
 		if col.Table != q.columns[0].Table {
 			return "", errors.New("insert columns must be from the same table")
 		}
@@ -82,17 +108,18 @@ func (q *InsertQuery) getSQL(parameterized bool) (string, error) {
 	}
 	onDuplicate := ""
 	if len(q.onDuplicate) > 0 {
-		updateFields := make([]string, len(q.onDuplicate))
-		for _, col := range q.onDuplicate {
-			if col.Table != q.columns[0].Table {
-				return "", errors.New("duplicate columns must be from the same table")
-			}
-			for i, col := range q.onDuplicate {
-				updateFields[i] = fmt.Sprintf("%s = VALUES(%s)", col.SQL(), col.SQL())
+		for _, fields := range [][]TableField{q.onDuplicate, q.conflict} {
+			for _, col := range fields {
+				if col.Table != q.columns[0].Table {
+					return "", errors.New("duplicate columns must be from the same table")
+				}
 			}
 		}
-		onDuplicate = " ON DUPLICATE KEY UPDATE " + strings.Join(updateFields, ", ")
+		var err error
+		if onDuplicate, err = dialect.Upsert(q.conflict, q.onDuplicate); nil != err {
+			return "", err
+		}
 	}
-	return fmt.Sprintf("INSERT INTO `%s` (%s) VALUES %s%s", q.columns[0].Table, strings.Join(colExp, ", "),
-		valExp, onDuplicate), q.err
+	return Render(dialect, fmt.Sprintf("INSERT INTO `%s` (%s) VALUES %s%s", q.columns[0].Table,
+		strings.Join(colExp, ", "), valExp, onDuplicate)), q.err
 }
@@ -35,10 +35,18 @@ func (a math) GetTables() []string {
 }
 
 func (a math) ParameterizedSQL() (string, []any) {
+	sql, values, _ := a.dialectSQL(MySQL)
+	return sql, values
+}
+
+func (a math) dialectSQL(dialect Dialect) (string, []any, error) {
 	parts := make([]string, len(a.expressions))
 	values := []any{}
 	for i, expression := range a.expressions {
-		sql, expValues := expression.ParameterizedSQL()
+		sql, expValues, err := expressionSQL(dialect, expression)
+		if nil != err {
+			return "", nil, err
+		}
 		parts[i] = sql
 		values = append(values, expValues...)
 	}
@@ -46,7 +54,7 @@ func (a math) ParameterizedSQL() (string, []any) {
 	if !stringutil.IsWhiteSpace(a.alias) {
 		sql = fmt.Sprintf("%s AS `%s`", sql, a.alias)
 	}
-	return sql, values
+	return sql, values, nil
 }
 
 // Add the passed expressions together. Pass an empty aliasName to embed the
@@ -169,17 +169,30 @@ func (tf TableField) GetName() string {
 
 // GetTables that are used in this expression
 func (tf TableField) GetTables() []string {
+	if tf.Table == "" {
+		// references a select expression by alias
+		return []string{}
+	}
 	return []string{tf.Table}
 }
 
 // SQL that represents this table field
 func (tf TableField) SQL() string {
+	if tf.Table == "" {
+		return tf.columnSQL()
+	}
 	if tf.Name == "*" {
 		return fmt.Sprintf("`%s`.%s", tf.Table, tf.Name)
 	}
 	return fmt.Sprintf("`%s`.`%s`", tf.Table, tf.Name)
 }
 
+// columnSQL is the unqualified column name for use where the table may not be
+// referenced (e.g. assignments in some dialects)
+func (tf TableField) columnSQL() string {
+	return fmt.Sprintf("`%s`", tf.Name)
+}
+
 // ParameterizedSQL that represents this table field
 func (tf TableField) ParameterizedSQL() (string, []any) {
 	return tf.SQL(), nil
@@ -265,9 +278,9 @@ func (ob *orderBy) addExpression(field TableField, direction OrderDirection) *or
 }
 
 func (ob *orderBy) getTables() []string {
-	tables := make([]string, len(ob.expressions))
-	for i, exp := range ob.expressions {
-		tables[i] = exp.field.Table
+	tables := make([]string, 0, len(ob.expressions))
+	for _, exp := range ob.expressions {
+		tables = append(tables, exp.field.GetTables()...)
 	}
 	return tables
 }
@@ -278,7 +291,7 @@ func (ob *orderBy) sql() (string, bool) {
 	}
 	orderByLines := []string{}
 	for _, orderBy := range ob.expressions {
-		orderByLines = append(orderByLines, fmt.Sprintf("`%s`.`%s` %s", orderBy.field.Table, orderBy.field.Name, orderBy.direction))
+		orderByLines = append(orderByLines, fmt.Sprintf("%s %s", orderBy.field.SQL(), orderBy.direction))
 	}
 	return "ORDER BY " + strings.Join(orderByLines, ", "), true
 }
@@ -295,15 +308,12 @@ func (wc *whereCondition) tables() []string {
 	return tables
 }
 
-func (wc *whereCondition) sql() (string, []any, bool) {
-	var sql string
-	var values []any
-	ok := false
-	if nil != wc.expression {
-		sql, values = wc.expression.SQL()
-		ok = true
+func (wc *whereCondition) sql(dialect Dialect) (string, []any, bool, error) {
+	if nil == wc.expression {
+		return "", nil, false, nil
 	}
-	return sql, values, ok
+	sql, values, err := wc.expression.sql(dialect)
+	return sql, values, nil == err, err
 }
 
 // Join on the tables inside the query.
@@ -347,21 +357,33 @@ func (join *Join) On(left TableField, comparison Comparison, right any) *Conditi
 
 // SQL that represents this join.
 func (join *Join) SQL() (string, []any) {
+	sql, values, _ := join.sql(MySQL)
+	return sql, values
+}
+
+// sql of this join in the passed dialect or the error rendering a derived
+// table or subquery
+func (join *Join) sql(dialect Dialect) (string, []any, error) {
 	if nil != join.err {
-		return "", []any{}
+		return "", []any{}, nil
 	}
 	var lines []string
+	table, values, err := tableSQL(dialect, join.table)
+	if nil != err {
+		return "", nil, err
+	}
 	if join.joinType == Inner || join.joinType == Cross {
-		lines = []string{fmt.Sprintf("%s JOIN `%s` AS `%s` ON", join.joinType,
-			join.table.GetName(), join.table.GetAlias())}
+		lines = []string{fmt.Sprintf("%s JOIN %s ON", join.joinType, table)}
 	} else {
-		lines = []string{fmt.Sprintf("%s %s JOIN `%s` AS `%s` ON", join.direction, join.joinType, join.table.GetName(),
-			join.table.GetAlias())}
+		lines = []string{fmt.Sprintf("%s %s JOIN %s ON", join.direction, join.joinType, table)}
+	}
+	expressionSQL, conditionValues, err := join.condition.sql(dialect)
+	if nil != err {
+		return "", nil, err
 	}
-	expressionSQL, values := join.condition.SQL()
 
 	lines = append(lines, expressionSQL)
-	return strings.Join(lines, " "), values
+	return strings.Join(lines, " "), append(values, conditionValues...), nil
 }
 
 // Select creates a new select query based on the passed expressions for the select clause.
@@ -371,6 +393,7 @@ func Select(selectExpressions ...SelectExpression) *SelectQuery {
 		orderBy:    &orderBy{},
 		groupBy:    []TableField{},
 		where:      &whereCondition{},
+		having:     &whereCondition{},
 		Seperator:  " ",
 	}
 	return query
@@ -398,7 +421,7 @@ func Insert(columns ...TableField) *InsertQuery {
 func Update(table Table) *UpdateQuery {
 	return &UpdateQuery{
 		tableReference: table,
-		assignments:    []comparisonExpression{},
+		assignments:    []assignmentExpression{},
 		orderBy:        &orderBy{},
 		where:          &whereCondition{},
 	}
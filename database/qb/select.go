@@ -60,16 +60,30 @@ func (i ifStatement) GetTables() []string {
 }
 
 func (i ifStatement) ParameterizedSQL() (string, []any) {
-	conditionSQL, values := i.condition.SQL()
-	trueSQL, trueValues := i.trueValue.ParameterizedSQL()
-	falseSQL, falseValues := i.falseValue.ParameterizedSQL()
+	sql, values, _ := i.dialectSQL(MySQL)
+	return sql, values
+}
+
+func (i ifStatement) dialectSQL(dialect Dialect) (string, []any, error) {
+	conditionSQL, values, err := i.condition.sql(dialect)
+	if nil != err {
+		return "", nil, err
+	}
+	trueSQL, trueValues, err := expressionSQL(dialect, i.trueValue)
+	if nil != err {
+		return "", nil, err
+	}
+	falseSQL, falseValues, err := expressionSQL(dialect, i.falseValue)
+	if nil != err {
+		return "", nil, err
+	}
 	values = append(values, trueValues...)
 	values = append(values, falseValues...)
 	sql := fmt.Sprintf("IF(%s, %s, %s)", conditionSQL, trueSQL, falseSQL)
 	if !stringutil.IsWhiteSpace(i.alias) {
 		sql += fmt.Sprintf(" AS `%s`", i.alias)
 	}
-	return sql, values
+	return sql, values, nil
 }
 
 // If creates a SQL IF statement that can be used as a [SelectExpression]. Pass
@@ -115,12 +129,20 @@ func (s sum) GetTables() []string {
 }
 
 func (s sum) ParameterizedSQL() (string, []any) {
-	expressionSQL, values := s.selectExpression.ParameterizedSQL()
+	sql, values, _ := s.dialectSQL(MySQL)
+	return sql, values
+}
+
+func (s sum) dialectSQL(dialect Dialect) (string, []any, error) {
+	expSQL, values, err := expressionSQL(dialect, s.selectExpression)
+	if nil != err {
+		return "", nil, err
+	}
 	sql := "SUM(%s)"
 	if !stringutil.IsWhiteSpace(s.alias) {
 		sql += fmt.Sprintf(" AS `%s`", s.alias)
 	}
-	return fmt.Sprintf(sql, expressionSQL), values
+	return fmt.Sprintf(sql, expSQL), values, nil
 }
 
 // Sum the passed table field for use in or as a SelectExpression
@@ -165,32 +187,46 @@ func (c coalesce) GetTables() []string {
 }
 
 func (c coalesce) ParameterizedSQL() (string, []any) {
+	sql, values, _ := c.dialectSQL(MySQL)
+	return sql, values
+}
+
+func (c coalesce) dialectSQL(dialect Dialect) (string, []any, error) {
 	switch c.value.(type) {
 	case SelectExpression:
-		return c.tableFieldSQL()
+		return c.tableFieldSQL(dialect)
 	default:
-		return c.parameterizedSQL()
+		return c.parameterizedSQL(dialect)
 	}
 }
 
-func (c coalesce) tableFieldSQL() (string, []any) {
-	expressionSQL, values := c.expression.ParameterizedSQL()
+func (c coalesce) tableFieldSQL(dialect Dialect) (string, []any, error) {
+	expSQL, values, err := expressionSQL(dialect, c.expression)
+	if nil != err {
+		return "", nil, err
+	}
 	defaultExpression, _ := c.value.(SelectExpression)
-	defaultExpressionSQL, defaultExpressionValues := defaultExpression.ParameterizedSQL()
+	defaultExpressionSQL, defaultExpressionValues, err := expressionSQL(dialect, defaultExpression)
+	if nil != err {
+		return "", nil, err
+	}
 	sql := "COALESCE(%s, %s)"
 	if !stringutil.IsWhiteSpace(c.name) {
 		sql += fmt.Sprintf(" AS `%s`", c.name)
 	}
-	return fmt.Sprintf(sql, expressionSQL, defaultExpressionSQL), append(values, defaultExpressionValues...)
+	return fmt.Sprintf(sql, expSQL, defaultExpressionSQL), append(values, defaultExpressionValues...), nil
 }
 
-func (c coalesce) parameterizedSQL() (string, []any) {
-	expressionSQL, values := c.expression.ParameterizedSQL()
+func (c coalesce) parameterizedSQL(dialect Dialect) (string, []any, error) {
+	expSQL, values, err := expressionSQL(dialect, c.expression)
+	if nil != err {
+		return "", nil, err
+	}
 	sql := "COALESCE(%s, ?)"
 	if !stringutil.IsWhiteSpace(c.name) {
 		sql += fmt.Sprintf(" AS `%s`", c.name)
 	}
-	return fmt.Sprintf(sql, expressionSQL), append(values, c.value)
+	return fmt.Sprintf(sql, expSQL), append(values, c.value), nil
 }
 
 // Coalesce creates a SQL coalesce that can be used as a SelectExpression
@@ -200,6 +236,7 @@ func Coalesce(expression SelectExpression, defaultValue any, alias string) Selec
 
 // SelectQuery for retrieving data from a database table.
 type SelectQuery struct {
+	with           withClause
 	distinct       bool
 	from           Table
 	selectExps     []SelectExpression
@@ -207,9 +244,11 @@ type SelectQuery struct {
 	orderBy        *orderBy
 	groupBy        []TableField
 	where          *whereCondition
+	having         *whereCondition
 	Seperator      string
 	outfile        string
 	outfileOptions *OutfileOptions
+	dialect        Dialect
 	err            error
 }
 
@@ -217,13 +256,16 @@ type SelectQuery struct {
 func (q *SelectQuery) SelectFrom(selectExpressions ...SelectExpression) *SelectQuery {
 	query := Select(selectExpressions...)
 
+	query.with = q.with
 	query.distinct = q.distinct
 	query.from = q.from
 	query.joins = q.joins
 	query.orderBy = q.orderBy
 	query.groupBy = q.groupBy
-	query.where = q.where
+	query.where = &whereCondition{expression: q.where.expression}
+	query.having = &whereCondition{expression: q.having.expression}
 	query.Seperator = q.Seperator
+	query.dialect = q.dialect
 
 	return query
 }
@@ -243,6 +285,34 @@ func (q *SelectQuery) GetDistinct() bool {
 	return q.distinct
 }
 
+// GetFrom table of this query, nil if there is none
+func (q *SelectQuery) GetFrom() Table {
+	return q.from
+}
+
+// GetWhere condition of this query, nil if there is none
+func (q *SelectQuery) GetWhere() *ConditionExpression {
+	return q.where.expression
+}
+
+// WithDialect sets the dialect this query is rendered in, defaults to MySQL.
+func (q *SelectQuery) WithDialect(dialect Dialect) *SelectQuery {
+	q.dialect = dialect
+	return q
+}
+
+// GetDialect this query is rendered in.
+func (q *SelectQuery) GetDialect() Dialect {
+	return dialectOrDefault(q.dialect)
+}
+
+// With declares the passed common table expressions on this query so that
+// they can be referenced as tables.
+func (q *SelectQuery) With(ctes ...*CommonTableExpression) *SelectQuery {
+	q.with = q.with.add(ctes)
+	return q
+}
+
 // From sets the primary table the query will get values from.
 func (q *SelectQuery) From(table Table) *SelectQuery {
 	q.from = table
@@ -281,6 +351,13 @@ func (q *SelectQuery) GroupBy(tableFields ...TableField) *SelectQuery {
 	return q
 }
 
+// Having filters the groups of this query after GroupBy has been applied. The
+// condition may reference aggregate expressions by alias using AliasField.
+func (q *SelectQuery) Having(condition *ConditionExpression) *SelectQuery {
+	q.having.expression = condition
+	return q
+}
+
 // IntoOutfile sets an output file path, causing the query to write results
 // to that file using MySQL's INTO OUTFILE syntax.
 func (q *SelectQuery) IntoOutfile(path string, options *OutfileOptions) *SelectQuery {
@@ -289,7 +366,7 @@ func (q *SelectQuery) IntoOutfile(path string, options *OutfileOptions) *SelectQ
 	return q
 }
 
-func (q *SelectQuery) selectExpressionsSQL() (string, []any) {
+func (q *SelectQuery) selectExpressionsSQL(dialect Dialect) (string, []any, error) {
 	var prefix string
 	if q.distinct {
 		prefix = "SELECT DISTINCT"
@@ -299,16 +376,35 @@ func (q *SelectQuery) selectExpressionsSQL() (string, []any) {
 	expressions := make([]string, len(q.selectExps))
 	values := make([]any, 0, len(q.selectExps))
 	for i, exp := range q.selectExps {
-		selectSQL, selectValues := exp.ParameterizedSQL()
+		selectSQL, selectValues, err := expressionSQL(dialect, exp)
+		if nil != err {
+			return "", nil, err
+		}
 		expressions[i] = selectSQL
 		values = append(values, selectValues...)
 	}
-	return fmt.Sprintf("%s %s", prefix, strings.Join(expressions, ", ")), values
+	return fmt.Sprintf("%s %s", prefix, strings.Join(expressions, ", ")), values, nil
 }
 
 // Validate that this query can be executed.
 func (q *SelectQuery) Validate() bool {
 	q.err = nil
+	missingTables, err := q.validate()
+	if nil != err {
+		q.err = err
+		return false
+	}
+	if len(missingTables) > 0 {
+		q.err = NewMissingTablesError(missingTables)
+		return false
+	}
+	return true
+}
+
+// validate this query and any subqueries it contains returning the tables that
+// are required but are not part of the from or a join so that correlated
+// subqueries can be satisfied by the enclosing query.
+func (q *SelectQuery) validate() ([]string, error) {
 	// gather up all the tables that must be present in the from or in a join
 	tablesRequired := make(map[string]bool)
 	// check the select
@@ -331,45 +427,127 @@ func (q *SelectQuery) Validate() bool {
 			tablesRequired[table] = true
 		}
 	}
+	// and the having
+	for _, table := range q.having.tables() {
+		tablesRequired[table] = true
+	}
 	// check that the from table is set
 	if nil == q.from {
-		q.err = NewValidationFromNotSetError()
-		return false
+		return nil, NewValidationFromNotSetError()
 	}
 	delete(tablesRequired, q.from.GetAlias())
 
 	for _, join := range q.joins {
 		delete(tablesRequired, join.table.GetAlias())
 		if nil != join.err {
-			q.err = join.err
-			return false
+			return nil, join.err
 		}
 	}
 
-	if len(tablesRequired) > 0 {
-		missingTables := make([]string, len(tablesRequired))
-		i := 0
-		for key := range tablesRequired {
-			missingTables[i] = key
-			i++
+	if err := q.with.validate(); nil != err {
+		return nil, err
+	}
+
+	if err := q.validateSubqueries(); nil != err {
+		return nil, err
+	}
+
+	missingTables := make([]string, 0, len(tablesRequired))
+	for key := range tablesRequired {
+		missingTables = append(missingTables, key)
+	}
+	return missingTables, nil
+}
+
+// validateSubqueries contained in this query, derived tables must be valid on
+// their own while other subqueries may reference tables in this query.
+func (q *SelectQuery) validateSubqueries() error {
+	subqueries := []*SelectQuery{}
+	for _, exp := range q.selectExps {
+		if container, ok := exp.(subqueryContainer); ok {
+			subqueries = append(subqueries, container.subqueries()...)
+		}
+	}
+	if nil != q.where.expression {
+		subqueries = append(subqueries, q.where.expression.subqueries()...)
+	}
+	if nil != q.having.expression {
+		subqueries = append(subqueries, q.having.expression.subqueries()...)
+	}
+	tables := []Table{q.from}
+	for _, join := range q.joins {
+		tables = append(tables, join.table)
+		subqueries = append(subqueries, join.condition.subqueries()...)
+	}
+	for _, table := range tables {
+		if derived, ok := table.(derivedSource); ok {
+			if err := derived.validateDerived(); nil != err {
+				return err
+			}
+		}
+	}
+	for _, subquery := range subqueries {
+		if _, err := subquery.validate(); nil != err {
+			return err
 		}
-		q.err = NewMissingTablesError(missingTables)
-		return false
 	}
+	return nil
+}
 
-	return true
+// correlatedTables referenced by this query that must be provided by an
+// enclosing query.
+func (q *SelectQuery) correlatedTables() []string {
+	missingTables, _ := q.validate()
+	return missingTables
 }
 
 // SQL statement corresponding to this query.
 func (q *SelectQuery) SQL(options LimitOffset) (string, []any, error) {
+	return q.SQLFor(q.GetDialect(), options)
+}
+
+// SQLFor the passed dialect, the dialect of this query is not changed so it can
+// be rendered for other dialects.
+func (q *SelectQuery) SQLFor(dialect Dialect, options LimitOffset) (string, []any, error) {
 	if !q.Validate() {
 		return "", []any{}, q.err
 	}
+	dialect = dialectOrDefault(dialect)
+	sql, values, err := q.render(dialect, options)
+	if nil != err {
+		return "", []any{}, err
+	}
+	return Render(dialect, sql), values, q.err
+}
+
+// render this query in the canonical MySQL form for the passed dialect without
+// validating it
+func (q *SelectQuery) render(dialect Dialect, options LimitOffset) (string, []any, error) {
+	lines := []string{}
+	values := []any{}
+
+	// WITH
+	with, withValues, ok, err := q.with.sql(dialect)
+	if nil != err {
+		return "", []any{}, err
+	}
+	if ok {
+		lines = append(lines, with)
+		values = append(values, withValues...)
+	}
+
 	// SELECT
-	selectExpressionsSQL, values := q.selectExpressionsSQL()
-	lines := []string{selectExpressionsSQL}
+	selectExpressionsSQL, selectValues, err := q.selectExpressionsSQL(dialect)
+	if nil != err {
+		return "", []any{}, err
+	}
+	lines = append(lines, selectExpressionsSQL)
+	values = append(values, selectValues...)
 
 	// INTO OUTFILE
+	if (q.outfile != "" || q.outfileOptions != nil) && !dialect.SupportsOutfile() {
+		return "", []any{}, NewUnsupportedError(dialect, "INTO OUTFILE")
+	}
 	if q.outfile != "" {
 		lines = append(lines, fmt.Sprintf("INTO OUTFILE S3 '%s'", strings.ReplaceAll(q.outfile, "'", `\'`)))
 	}
@@ -378,20 +556,29 @@ func (q *SelectQuery) SQL(options LimitOffset) (string, []any, error) {
 	}
 
 	// FROM
-	from := fmt.Sprintf("FROM `%s` AS `%s`",
-		q.from.GetName(),
-		q.from.GetAlias())
-	lines = append(lines, from)
+	fromSQL, fromValues, err := tableSQL(dialect, q.from)
+	if nil != err {
+		return "", []any{}, err
+	}
+	lines = append(lines, "FROM "+fromSQL)
+	values = append(values, fromValues...)
 
 	// JOIN
 	for _, join := range q.joins {
-		joinSQL, joinValues := join.SQL()
+		joinSQL, joinValues, err := join.sql(dialect)
+		if nil != err {
+			return "", []any{}, err
+		}
 		lines = append(lines, joinSQL)
 		values = append(values, joinValues...)
 	}
 
 	// WHERE
-	if where, whereValues, ok := q.where.sql(); ok {
+	where, whereValues, ok, err := q.where.sql(dialect)
+	if nil != err {
+		return "", []any{}, err
+	}
+	if ok {
 		lines = append(lines, "WHERE", where)
 		values = append(values, whereValues...)
 	}
@@ -406,6 +593,16 @@ func (q *SelectQuery) SQL(options LimitOffset) (string, []any, error) {
 		lines = append(lines, groupByStatement)
 	}
 
+	// HAVING
+	having, havingValues, ok, err := q.having.sql(dialect)
+	if nil != err {
+		return "", []any{}, err
+	}
+	if ok {
+		lines = append(lines, "HAVING", having)
+		values = append(values, havingValues...)
+	}
+
 	// ORDER BY
 	if orderby, ok := q.orderBy.sql(); ok {
 		lines = append(lines, orderby)
@@ -413,7 +610,7 @@ func (q *SelectQuery) SQL(options LimitOffset) (string, []any, error) {
 
 	// early exit if the options are nil
 	if options == nil {
-		return strings.Join(lines, q.Seperator), values, q.err
+		return strings.Join(lines, q.Seperator), values, nil
 	}
 	// LIMIT, OFFSET
 	if NoLimit != options.Limit() {
@@ -422,5 +619,5 @@ func (q *SelectQuery) SQL(options LimitOffset) (string, []any, error) {
 	if options.Offset() > 0 {
 		lines = append(lines, fmt.Sprintf("OFFSET %d", options.Offset()))
 	}
-	return strings.Join(lines, q.Seperator), values, q.err
+	return strings.Join(lines, q.Seperator), values, nil
 }
@@ -112,3 +112,28 @@ func Test_Coalesce(t *testing.T) {
 		require.Equal([]any{defaultField}, actualParams)
 	})
 }
+
+func TestSelectFromWhere(t *testing.T) {
+	assert := assert.New(t)
+	query := Select(Person.ID).From(Person).Where(Person.Name.Equal("a"))
+	assert.Equal(query.GetWhere(), query.SelectFrom(Person.Name).GetWhere())
+
+	// the where condition of the copy is independent
+	query.SelectFrom(Person.Name).Where(nil)
+	sql, _, err := query.SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `person`.`id` FROM `person` AS `person` WHERE `person`.`name` = ?", sql)
+	assert.Nil(Select(Person.ID).From(Person).GetWhere())
+}
+
+func TestSelectFromHaving(t *testing.T) {
+	assert := assert.New(t)
+	query := Select(Person.ID).From(Person).GroupBy(Person.ID).Having(Person.Name.Equal("a"))
+
+	// the having condition of the copy is independent
+	query.SelectFrom(Person.Name).Having(nil)
+	sql, _, err := query.SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `person`.`id` FROM `person` AS `person` GROUP BY `person`.`id` "+
+		"HAVING `person`.`name` = ?", sql)
+}
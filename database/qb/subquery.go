@@ -0,0 +1,184 @@
+package qb
+
+import (
+	"fmt"
+)
+
+// subqueryContainer is implemented by select expressions that contain
+// subqueries so that they are validated with the enclosing query
+type subqueryContainer interface {
+	subqueries() []*SelectQuery
+}
+
+// subquerySQL renders the passed query for use inside of another query in the
+// dialect of the enclosing query, which is responsible for validation and
+// rendering the result in its dialect.
+func subquerySQL(dialect Dialect, query *SelectQuery) (string, []any, error) {
+	sql, values, err := query.render(dialect, nil)
+	if nil != err {
+		return "", nil, err
+	}
+	return "(" + sql + ")", values, nil
+}
+
+type existsExpression struct {
+	not   bool
+	query *SelectQuery
+}
+
+func (exp *existsExpression) sql(dialect Dialect) (string, []any, error) {
+	sql, values, err := subquerySQL(dialect, exp.query)
+	if nil != err {
+		return "", nil, err
+	}
+	if exp.not {
+		return "NOT EXISTS " + sql, values, nil
+	}
+	return "EXISTS " + sql, values, nil
+}
+
+// Exists is a condition that is true when the passed query returns at least
+// one row. The query may reference tables from the enclosing query.
+func Exists(query *SelectQuery) *ConditionExpression {
+	return &ConditionExpression{exists: &existsExpression{query: query}}
+}
+
+// NotExists is a condition that is true when the passed query returns no rows.
+// The query may reference tables from the enclosing query.
+func NotExists(query *SelectQuery) *ConditionExpression {
+	return &ConditionExpression{exists: &existsExpression{not: true, query: query}}
+}
+
+type scalar struct {
+	query *SelectQuery
+	alias string
+}
+
+func (s scalar) GetName() string {
+	return s.alias
+}
+
+func (s scalar) GetTables() []string {
+	return s.query.correlatedTables()
+}
+
+func (s scalar) ParameterizedSQL() (string, []any) {
+	sql, values, _ := s.dialectSQL(MySQL)
+	return sql, values
+}
+
+func (s scalar) dialectSQL(dialect Dialect) (string, []any, error) {
+	sql, values, err := subquerySQL(dialect, s.query)
+	if nil != err {
+		return "", nil, err
+	}
+	return fmt.Sprintf("%s AS `%s`", sql, s.alias), values, nil
+}
+
+func (s scalar) subqueries() []*SelectQuery {
+	return []*SelectQuery{s.query}
+}
+
+// Scalar uses the passed query, which must return a single row and column, as
+// a SelectExpression with the passed alias. The query may reference tables
+// from the enclosing query.
+func Scalar(query *SelectQuery, alias string) SelectExpression {
+	return scalar{query: query, alias: alias}
+}
+
+// DerivedTable is a select query that is used as a table in the FROM or a JOIN
+// of another query.
+type DerivedTable struct {
+	query *SelectQuery
+	alias string
+}
+
+// Derived table from the passed query that can be used in From or a Join with
+// the passed alias. Columns on the derived table are referenced using Field.
+func Derived(query *SelectQuery, alias string) *DerivedTable {
+	return &DerivedTable{query: query, alias: alias}
+}
+
+// Field on this derived table with the passed name, this is the name of the
+// select expression in the derived query.
+func (dt *DerivedTable) Field(name string) TableField {
+	return TableField{Name: name, Table: dt.alias}
+}
+
+// GetName of a derived table is its alias
+func (dt *DerivedTable) GetName() string {
+	return dt.alias
+}
+
+// GetAlias of this derived table
+func (dt *DerivedTable) GetAlias() string {
+	return dt.alias
+}
+
+// PrimaryKey of a derived table is the first column
+func (dt *DerivedTable) PrimaryKey() TableField {
+	columns := dt.ReadColumns()
+	if len(columns) == 0 {
+		return dt.AllColumns()
+	}
+	return columns[0]
+}
+
+// AllColumns of this derived table
+func (dt *DerivedTable) AllColumns() TableField {
+	return dt.Field("*")
+}
+
+// ReadColumns of this derived table, one for each select expression in the
+// derived query
+func (dt *DerivedTable) ReadColumns() []TableField {
+	if nil == dt.query {
+		return []TableField{}
+	}
+	columns := make([]TableField, len(dt.query.selectExps))
+	for i, exp := range dt.query.selectExps {
+		columns[i] = dt.Field(exp.GetName())
+	}
+	return columns
+}
+
+// WriteColumns of a derived table, derived tables can not be written to
+func (dt *DerivedTable) WriteColumns() []TableField {
+	return []TableField{}
+}
+
+// SortBy the primary key of this derived table
+func (dt *DerivedTable) SortBy() (TableField, OrderDirection) {
+	return dt.PrimaryKey(), Ascending
+}
+
+// derivedSource is implemented by tables that are built from select queries
+type derivedSource interface {
+	Table
+	derivedSQL(dialect Dialect) (string, []any, error)
+	validateDerived() error
+}
+
+func (dt *DerivedTable) derivedSQL(dialect Dialect) (string, []any, error) {
+	return subquerySQL(dialect, dt.query)
+}
+
+func (dt *DerivedTable) validateDerived() error {
+	if !dt.query.Validate() {
+		return dt.query.err
+	}
+	return nil
+}
+
+// tableSQL renders a table reference for use in a FROM or JOIN in the passed
+// dialect
+func tableSQL(dialect Dialect, table Table) (string, []any, error) {
+	if derived, ok := table.(derivedSource); ok {
+		sql, values, err := derived.derivedSQL(dialect)
+		if nil != err {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s AS `%s`", sql, derived.GetAlias()), values, nil
+	}
+	return fmt.Sprintf("`%s` AS `%s`", table.GetName(), table.GetAlias()), nil, nil
+}
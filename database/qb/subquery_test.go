@@ -0,0 +1,139 @@
+package qb
+
+import (
+	"testing"
+
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+func TestSubquery_In(t *testing.T) {
+	assert := assert1.New(t)
+	subquery := Select(Address.ID).From(Address).Where(Address.Country.Equal("US"))
+	query := Select(Person.ID).From(Person).
+		Where(Person.Age.GreaterThan(18).And(Person.AddressID.In(subquery)))
+	sql, values, err := query.SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `person`.`id` FROM `person` AS `person` WHERE (`person`.`age` > ? AND "+
+		"`person`.`address_id` IN (SELECT `address`.`id` FROM `address` AS `address` "+
+		"WHERE `address`.`country` = ?))", sql)
+	assert.Equal([]any{18, "US"}, values)
+
+	query = Select(Person.ID).From(Person).Where(Person.AddressID.NotIn(subquery))
+	sql, _, err = query.SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `person`.`id` FROM `person` AS `person` WHERE `person`.`address_id` "+
+		"NOT IN (SELECT `address`.`id` FROM `address` AS `address` WHERE `address`.`country` = ?)", sql)
+}
+
+func TestSubquery_Exists(t *testing.T) {
+	assert := assert1.New(t)
+	// correlated subquery referencing the enclosing person table
+	subquery := Select(Address.ID).From(Address).
+		Where(Address.ID.Equal(Person.AddressID).And(Address.Country.Equal("US")))
+	query := Select(Person.ID).From(Person).
+		Where(Exists(subquery).And(Person.Name.NotEqual("bob")))
+	sql, values, err := query.SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `person`.`id` FROM `person` AS `person` WHERE (EXISTS (SELECT `address`.`id` "+
+		"FROM `address` AS `address` WHERE (`address`.`id` = `person`.`address_id` AND "+
+		"`address`.`country` = ?)) AND `person`.`name` != ?)", sql)
+	assert.Equal([]any{"US", "bob"}, values)
+
+	sql, _, err = Select(Person.ID).From(Person).Where(NotExists(subquery)).WithDialect(PostgreSQL).SQL(nil)
+	assert.NoError(err)
+	assert.Equal(`SELECT "person"."id" FROM "person" AS "person" WHERE NOT EXISTS (SELECT "address"."id" `+
+		`FROM "address" AS "address" WHERE ("address"."id" = "person"."address_id" AND `+
+		`"address"."country" = $1))`, sql)
+
+	// correlated table is not part of the enclosing query
+	person2 := Person.Alias("p2")
+	query = Select(Person.ID).From(Person).
+		Where(Exists(Select(Address.ID).From(Address).Where(Address.ID.Equal(person2.AddressID))))
+	_, _, err = query.SQL(nil)
+	assert.EqualError(err, "validation: the following tables are required but were not "+
+		"included in a join or from: [p2]")
+
+	// invalid subquery
+	_, _, err = Select(Person.ID).From(Person).Where(Exists(Select(Address.ID))).SQL(nil)
+	assert.EqualError(err, "validation: from table must be set")
+}
+
+func TestSubquery_Scalar(t *testing.T) {
+	assert := assert1.New(t)
+	person2 := Person.Alias("p2")
+	count := Select(Count(Address.ID, "total")).From(Address).Where(Address.ID.Equal(Person.AddressID))
+	query := Select(Person.ID, Scalar(count, "address_count")).From(Person).
+		Where(Person.Age.GreaterThan(Select(person2.Age).From(person2).Where(person2.ID.Equal(1))))
+	sql, values, err := query.SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `person`.`id`, (SELECT COUNT(`address`.`id`) AS `total` FROM `address` "+
+		"AS `address` WHERE `address`.`id` = `person`.`address_id`) AS `address_count` FROM `person` "+
+		"AS `person` WHERE `person`.`age` > (SELECT `p2`.`age` FROM `person` AS `p2` WHERE `p2`.`id` = ?)", sql)
+	assert.Equal([]any{1}, values)
+}
+
+func TestSubquery_Derived(t *testing.T) {
+	assert := assert1.New(t)
+	adults := Derived(Select(Person.ID, Person.AddressID).From(Person).Where(Person.Age.GreaterThan(18)),
+		"adults")
+	query := Select(adults.Field("id"), Address.Line).From(adults).Where(adults.Field("id").NotEqual(5))
+	query.InnerJoin(Address).On(Address.ID, Equal, adults.Field("address_id"))
+	sql, values, err := query.SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `adults`.`id`, `address`.`line` FROM (SELECT `person`.`id`, "+
+		"`person`.`address_id` FROM `person` AS `person` WHERE `person`.`age` > ?) AS `adults` "+
+		"INNER JOIN `address` AS `address` ON `address`.`id` = `adults`.`address_id` "+
+		"WHERE `adults`.`id` != ?", sql)
+	assert.Equal([]any{18, 5}, values)
+
+	query = Select(Address.Line, adults.Field("id")).From(Address)
+	query.InnerJoin(adults).On(adults.Field("address_id"), Equal, Address.ID)
+	sql, values, err = query.Where(Address.Country.Equal("US")).SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `address`.`line`, `adults`.`id` FROM `address` AS `address` "+
+		"INNER JOIN (SELECT `person`.`id`, `person`.`address_id` FROM `person` AS `person` "+
+		"WHERE `person`.`age` > ?) AS `adults` ON `adults`.`address_id` = `address`.`id` "+
+		"WHERE `address`.`country` = ?", sql)
+	assert.Equal([]any{18, "US"}, values)
+	assert.Equal([]TableField{adults.Field("id"), adults.Field("address_id")}, adults.ReadColumns())
+
+	// derived tables can not be correlated
+	correlated := Derived(Select(Address.ID).From(Address).Where(Address.ID.Equal(Person.AddressID)), "a")
+	query = Select(Person.ID).From(Person)
+	query.InnerJoin(correlated).On(correlated.Field("id"), Equal, Person.AddressID)
+	_, _, err = query.SQL(nil)
+	assert.EqualError(err, "validation: the following tables are required but were not "+
+		"included in a join or from: [person]")
+
+	// missing tables in the enclosing query are still detected
+	_, _, err = Select(adults.Field("id"), Address.Line).From(adults).SQL(nil)
+	assert.EqualError(err, "validation: the following tables are required but were not "+
+		"included in a join or from: [address]")
+}
+
+func TestSubquery_RenderError(t *testing.T) {
+	assert := assert1.New(t)
+	expected := "GROUP_CONCAT DISTINCT with a separator is not supported by the sqlite3 dialect"
+	names := Select(GroupConcatDistinct(Person.Name, ";", "names")).From(Person).WithDialect(SQLite)
+	ids := Select(Person.ID).From(Person).Where(Person.Name.In(names)).WithDialect(SQLite)
+	derived := Derived(names, "n")
+
+	queries := map[string]*SelectQuery{
+		"where":  Select(Address.ID).From(Address).Where(Address.Line.In(names)).WithDialect(SQLite),
+		"exists": Select(Address.ID).From(Address).Where(Exists(names)).WithDialect(SQLite),
+		"nested": Select(Address.ID).From(Address).Where(Address.ID.In(ids)).WithDialect(SQLite),
+		"scalar": Select(Address.ID, Scalar(names, "names")).From(Address).WithDialect(SQLite),
+		"if": Select(If(Address.Line.In(names), Address.ID, Address.Line, "a")).From(Address).
+			WithDialect(SQLite),
+		"derived": Select(derived.Field("names")).From(derived).WithDialect(SQLite),
+	}
+	for name, query := range queries {
+		_, _, err := query.SQL(nil)
+		assert.EqualError(err, expected, name)
+	}
+
+	_, _, err := Delete(Address).Where(Address.Line.In(names)).WithDialect(SQLite).SQL()
+	assert.EqualError(err, expected)
+	_, _, err = Update(Address).Set(Address.Line, "x").Where(Address.Line.In(names)).WithDialect(SQLite).SQL(NoLimit)
+	assert.EqualError(err, expected)
+}
@@ -28,11 +28,13 @@ import (
 // Currently only supports single table, to change this the tableReference
 // would have to be built out more.
 type UpdateQuery struct {
+	with           withClause
 	tableReference Table
-	assignments    []comparisonExpression
+	assignments    []assignmentExpression
 	where          *whereCondition
 	orderBy        *orderBy
 	ignore         bool
+	dialect        Dialect
 	err            error
 }
 
@@ -42,6 +44,13 @@ func (q *UpdateQuery) GetAlias(tableName string) string {
 	return tableName
 }
 
+// With declares the passed common table expressions on this query so that
+// they can be referenced by subqueries in the where clause.
+func (q *UpdateQuery) With(ctes ...*CommonTableExpression) *UpdateQuery {
+	q.with = q.with.add(ctes)
+	return q
+}
+
 // Set adds a assignment to this update query.
 func (q *UpdateQuery) Set(field TableField, value any) *UpdateQuery {
 	if field.Table != q.tableReference.GetName() {
@@ -63,6 +72,16 @@ func (q *UpdateQuery) SetParam(field TableField) *UpdateQuery {
 	return q
 }
 
+// Increment the passed integer field by one in this update query.
+func (q *UpdateQuery) Increment(field TableField) *UpdateQuery {
+	if field.Table != q.tableReference.GetName() {
+		q.err = errors.New("field table does not match table reference on update query")
+	} else {
+		q.assignments = append(q.assignments, incrementExpression{field: field})
+	}
+	return q
+}
+
 // SetIgnore keyword for the update query.
 func (q *UpdateQuery) SetIgnore(ignore bool) {
 	if q == nil {
@@ -71,6 +90,17 @@ func (q *UpdateQuery) SetIgnore(ignore bool) {
 	q.ignore = ignore
 }
 
+// WithDialect sets the dialect this query is rendered in, defaults to MySQL.
+func (q *UpdateQuery) WithDialect(dialect Dialect) *UpdateQuery {
+	q.dialect = dialect
+	return q
+}
+
+// GetDialect this query is rendered in.
+func (q *UpdateQuery) GetDialect() Dialect {
+	return dialectOrDefault(q.dialect)
+}
+
 // Where determines the conditions by which the assignments in this query apply
 func (q *UpdateQuery) Where(condition *ConditionExpression) *UpdateQuery {
 	q.where.expression = condition
@@ -83,74 +113,97 @@ func (q *UpdateQuery) OrderBy(field TableField, direction OrderDirection) *Updat
 	return q
 }
 
-func (q *UpdateQuery) getUpdateStmt() []string {
+func (q *UpdateQuery) getUpdateStmt() ([]string, error) {
 	sql := []string{"UPDATE"}
 	if q.ignore {
-		sql = append(sql, "IGNORE")
+		modifier, ok := q.GetDialect().UpdateIgnore()
+		if !ok {
+			return nil, NewUnsupportedError(q.GetDialect(), "UPDATE IGNORE")
+		}
+		sql = append(sql, modifier)
 	}
 	sql = append(sql, fmt.Sprintf("`%s` SET ", q.tableReference.GetName()))
-	return sql
+	return sql, nil
 }
 
 // SQL representation of this query.
 func (q *UpdateQuery) SQL(limit int) (string, []any, error) {
+	return q.getSQL(limit)
+}
+
+// ParameterizedSQL representation of this query.
+func (q *UpdateQuery) ParameterizedSQL(limit int) (string, error) {
+	sql, _, err := q.getSQL(limit)
+	return sql, err
+}
+
+func (q *UpdateQuery) getSQL(limit int) (string, []any, error) {
 	if nil != q.err {
 		return "", nil, q.err
 	}
 	if len(q.assignments) == 0 {
 		return "", nil, errors.New("no assignments in update query")
 	}
-	sql := q.getUpdateStmt()
-	alines := []string{}
-	values := []any{}
-	for _, assignment := range q.assignments {
-		s, v := assignment.SQL()
-		alines = append(alines, s)
-		values = append(values, v...)
-	}
-	sql = append(sql, strings.Join(alines, ", "))
-	// WHERE
-	if where, whereValues, ok := q.where.sql(); ok {
-		sql = append(sql, "WHERE", where)
-		values = append(values, whereValues...)
-	}
-	// ORDER BY
-	if s, ok := q.orderBy.sql(); ok {
-		sql = append(sql, s)
+	if err := q.with.validate(); nil != err {
+		return "", nil, err
 	}
-	// LIMIT
-	if NoLimit != limit {
-		sql = append(sql, fmt.Sprintf("LIMIT %d", limit))
+	dialect := q.GetDialect()
+	sql := []string{}
+	values := []any{}
+	// WITH
+	with, withValues, ok, err := q.with.sql(dialect)
+	if nil != err {
+		return "", nil, err
 	}
-	return strings.Join(sql, " "), values, q.err
-}
-
-// ParameterizedSQL representation of this query.
-func (q *UpdateQuery) ParameterizedSQL(limit int) (string, error) {
-	if nil != q.err {
-		return "", q.err
+	if ok {
+		sql = append(sql, with)
+		values = append(values, withValues...)
 	}
-	if len(q.assignments) == 0 {
-		return "", errors.New("no assignments in update query")
+	stmt, err := q.getUpdateStmt()
+	if nil != err {
+		return "", nil, err
 	}
-	sql := q.getUpdateStmt()
+	sql = append(sql, stmt...)
 	alines := []string{}
 	for _, assignment := range q.assignments {
-		s, _ := assignment.SQL()
+		var (
+			s string
+			v []any
+		)
+		if dialect.QualifiedAssignments() {
+			s, v, err = assignment.sql(dialect)
+		} else {
+			s, v, err = assignment.unqualifiedSQL(dialect)
+		}
+		if nil != err {
+			return "", nil, err
+		}
 		alines = append(alines, s)
+		values = append(values, v...)
 	}
 	sql = append(sql, strings.Join(alines, ", "))
 	// WHERE
-	if where, _, ok := q.where.sql(); ok {
+	where, whereValues, ok, err := q.where.sql(dialect)
+	if nil != err {
+		return "", nil, err
+	}
+	if ok {
 		sql = append(sql, "WHERE", where)
+		values = append(values, whereValues...)
 	}
 	// ORDER BY
 	if s, ok := q.orderBy.sql(); ok {
+		if !dialect.SupportsUpdateLimit() {
+			return "", nil, NewUnsupportedError(dialect, "UPDATE with ORDER BY")
+		}
 		sql = append(sql, s)
 	}
 	// LIMIT
 	if NoLimit != limit {
+		if !dialect.SupportsUpdateLimit() {
+			return "", nil, NewUnsupportedError(dialect, "UPDATE with LIMIT")
+		}
 		sql = append(sql, fmt.Sprintf("LIMIT %d", limit))
 	}
-	return strings.Join(sql, " "), q.err
+	return Render(dialect, strings.Join(sql, " ")), values, q.err
 }
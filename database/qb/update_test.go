@@ -28,6 +28,24 @@ func TestUpdateQueryParameterized(t *testing.T) {
 	assert.Equal("UPDATE `person` SET  `person`.`name` = :name WHERE `person`.`id` = :id", sql)
 }
 
+func TestUpdateQueryIncrement(t *testing.T) {
+	assert := assert1.New(t)
+	query := Update(Person).SetParam(Person.Name).Increment(Person.AddressID).
+		Where(Person.ID.Equal(":" + Person.ID.GetName()))
+	sql, err := query.ParameterizedSQL(NoLimit)
+	assert.NoError(err)
+	assert.Equal("UPDATE `person` SET  `person`.`name` = :name, "+
+		"`person`.`address_id` = `person`.`address_id` + 1 WHERE `person`.`id` = :id", sql)
+
+	sql, err = query.WithDialect(PostgreSQL).ParameterizedSQL(NoLimit)
+	assert.NoError(err)
+	assert.Equal(`UPDATE "person" SET  "name" = :name, "address_id" = "address_id" + 1 `+
+		`WHERE "person"."id" = :id`, sql)
+
+	_, err = Update(Person).Increment(Address.ID).ParameterizedSQL(NoLimit)
+	assert.EqualError(err, "field table does not match table reference on update query")
+}
+
 func TestUpdateQueryMulitpleFields(t *testing.T) {
 	assert := assert1.New(t)
 	expectedName := generator.String(20)
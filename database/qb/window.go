@@ -0,0 +1,145 @@
+package qb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Window defines the rows a window function is computed over as the
+// OVER (PARTITION BY ... ORDER BY ...) clause.
+type Window struct {
+	partitionBy []TableField
+	orderBy     *orderBy
+}
+
+// Over creates a window over all rows in the result, use PartitionBy and
+// OrderBy to restrict it.
+func Over() *Window {
+	return &Window{partitionBy: []TableField{}, orderBy: &orderBy{}}
+}
+
+// PartitionBy the passed fields so that the function is computed separately
+// for each distinct set of values.
+func (w *Window) PartitionBy(fields ...TableField) *Window {
+	w.partitionBy = append(w.partitionBy, fields...)
+	return w
+}
+
+// OrderBy the passed field and direction within each partition.
+func (w *Window) OrderBy(field TableField, direction OrderDirection) *Window {
+	w.orderBy.addExpression(field, direction)
+	return w
+}
+
+func (w *Window) getTables() []string {
+	tables := []string{}
+	for _, field := range w.partitionBy {
+		tables = append(tables, field.GetTables()...)
+	}
+	return append(tables, w.orderBy.getTables()...)
+}
+
+func (w *Window) sql() string {
+	clauses := []string{}
+	if len(w.partitionBy) > 0 {
+		fields := make([]string, len(w.partitionBy))
+		for i, field := range w.partitionBy {
+			fields[i] = field.SQL()
+		}
+		clauses = append(clauses, "PARTITION BY "+strings.Join(fields, ", "))
+	}
+	if orderBy, ok := w.orderBy.sql(); ok {
+		clauses = append(clauses, orderBy)
+	}
+	return fmt.Sprintf("OVER (%s)", strings.Join(clauses, " "))
+}
+
+type windowFunction struct {
+	function   string
+	expression SelectExpression
+	offset     int
+	window     *Window
+	alias      string
+}
+
+func (wf windowFunction) GetName() string {
+	return wf.alias
+}
+
+func (wf windowFunction) GetTables() []string {
+	tables := wf.window.getTables()
+	if nil != wf.expression {
+		tables = append(tables, wf.expression.GetTables()...)
+	}
+	return tables
+}
+
+func (wf windowFunction) ParameterizedSQL() (string, []any) {
+	sql, values, _ := wf.dialectSQL(MySQL)
+	return sql, values
+}
+
+func (wf windowFunction) dialectSQL(dialect Dialect) (string, []any, error) {
+	var (
+		arguments string
+		values    []any
+		err       error
+	)
+	if nil != wf.expression {
+		arguments, values, err = expressionSQL(dialect, wf.expression)
+		if nil != err {
+			return "", nil, err
+		}
+	}
+	if wf.offset > 0 {
+		arguments += fmt.Sprintf(", %d", wf.offset)
+	}
+	sql := fmt.Sprintf("%s(%s) %s", wf.function, arguments, wf.window.sql())
+	return withAlias(sql, wf.alias), values, nil
+}
+
+func newWindowFunction(function string, expression SelectExpression, offset int, window *Window,
+	alias string) SelectExpression {
+	if nil == window {
+		window = Over()
+	}
+	return windowFunction{function: function, expression: expression, offset: offset, window: window,
+		alias: alias}
+}
+
+// RowNumber of each row within its partition of the window starting at 1
+func RowNumber(window *Window, aliasName string) SelectExpression {
+	return newWindowFunction("ROW_NUMBER", nil, 0, window, aliasName)
+}
+
+// Rank of each row within its partition of the window, rows that sort equally
+// have the same rank and leave a gap in the sequence
+func Rank(window *Window, aliasName string) SelectExpression {
+	return newWindowFunction("RANK", nil, 0, window, aliasName)
+}
+
+// Lag is the value of the passed expression in the row offset rows before the
+// current row in its partition of the window, NULL when there is no such row
+func Lag(expression SelectExpression, offset int, window *Window, aliasName string) SelectExpression {
+	return newWindowFunction("LAG", expression, offset, window, aliasName)
+}
+
+// Lead is the value of the passed expression in the row offset rows after the
+// current row in its partition of the window, NULL when there is no such row
+func Lead(expression SelectExpression, offset int, window *Window, aliasName string) SelectExpression {
+	return newWindowFunction("LEAD", expression, offset, window, aliasName)
+}
+
+// WindowAggregate computes the passed aggregate function over the window
+// instead of a group. When the window is ordered the aggregate includes the
+// rows up to and including the current row.
+func WindowAggregate(function AggregateFunction, expression SelectExpression, window *Window,
+	aliasName string) SelectExpression {
+	return newWindowFunction(string(function), expression, 0, window, aliasName)
+}
+
+// RunningSum of the passed expression over the ordered rows of each partition
+// of the window
+func RunningSum(expression SelectExpression, window *Window, aliasName string) SelectExpression {
+	return WindowAggregate(SumFunction, expression, window, aliasName)
+}
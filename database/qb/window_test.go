@@ -0,0 +1,43 @@
+package qb
+
+import (
+	"testing"
+
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+func TestWindowFunctions(t *testing.T) {
+	assert := assert1.New(t)
+	byAddress := Over().PartitionBy(Person.AddressID).OrderBy(Person.Age, Descending)
+	query := Select(
+		Person.ID,
+		RowNumber(byAddress, "row_number"),
+		Rank(Over().OrderBy(Person.Age, Ascending), "rank"),
+		Lag(Person.Age, 1, byAddress, "previous_age"),
+		Lead(Person.Name, 2, nil, "next_name"),
+		RunningSum(Person.Age, Over().OrderBy(Person.ID, Ascending), "running_age"),
+		WindowAggregate(AvgFunction, Person.Age, Over().PartitionBy(Person.AddressID), "average_age"),
+	).From(Person)
+	sql, values, err := query.SQL(nil)
+	assert.NoError(err)
+	assert.Equal("SELECT `person`.`id`, "+
+		"ROW_NUMBER() OVER (PARTITION BY `person`.`address_id` ORDER BY `person`.`age` DESC) AS `row_number`, "+
+		"RANK() OVER (ORDER BY `person`.`age` ASC) AS `rank`, "+
+		"LAG(`person`.`age`, 1) OVER (PARTITION BY `person`.`address_id` ORDER BY `person`.`age` DESC) "+
+		"AS `previous_age`, "+
+		"LEAD(`person`.`name`, 2) OVER () AS `next_name`, "+
+		"SUM(`person`.`age`) OVER (ORDER BY `person`.`id` ASC) AS `running_age`, "+
+		"AVG(`person`.`age`) OVER (PARTITION BY `person`.`address_id`) AS `average_age` "+
+		"FROM `person` AS `person`", sql)
+	assert.Empty(values)
+
+	sql, _, err = Select(RowNumber(Over().OrderBy(Person.ID, Ascending), "n")).From(Person).
+		WithDialect(PostgreSQL).SQL(nil)
+	assert.NoError(err)
+	assert.Equal(`SELECT ROW_NUMBER() OVER (ORDER BY "person"."id" ASC) AS "n" FROM "person" AS "person"`, sql)
+
+	// tables in the window must be part of the query
+	_, _, err = Select(RowNumber(Over().PartitionBy(Address.Country), "n")).From(Person).SQL(nil)
+	assert.EqualError(err, "validation: the following tables are required but were not "+
+		"included in a join or from: [address]")
+}
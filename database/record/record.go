@@ -18,6 +18,25 @@ type Record interface {
 	Meta() qb.Table
 }
 
+// Versioned is optionally implemented by a Record that uses a version column for
+// optimistic concurrency control. Updates only apply when the version column
+// still holds the value that was read and increment it, otherwise they fail with
+// a StaleRecordError.
+type Versioned interface {
+	// VersionColumn returns the integer column holding the version of the Record
+	VersionColumn() qb.TableField
+}
+
+// VersionColumn returns the version column of the passed Record and true if the
+// Record implements Versioned
+func VersionColumn(obj Record) (qb.TableField, bool) {
+	versioned, ok := obj.(Versioned)
+	if !ok {
+		return qb.TableField{}, false
+	}
+	return versioned.VersionColumn(), true
+}
+
 // DefaultRecord implements the Key() as "id"
 type DefaultRecord struct{}
 
@@ -0,0 +1,87 @@
+package record
+
+import (
+	"context"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+)
+
+// DeletedOnColumn is the conventional name of the soft delete column
+const DeletedOnColumn = "deleted_on"
+
+// SoftDeletable is optionally implemented by the meta of a Record (the qb.Table
+// returned from Meta) whose rows are marked deleted by setting a nullable
+// timestamp column rather than being removed. Deletes set the column and reads,
+// lists and counts exclude rows where it is set unless the context was created
+// with WithDeleted or OnlyDeleted.
+type SoftDeletable interface {
+	// DeletedColumn returns the nullable timestamp column, usually DeletedOnColumn
+	DeletedColumn() qb.TableField
+}
+
+// DeletedScope determines which soft deleted rows are returned by a query
+type DeletedScope int
+
+const (
+	// ExcludeDeleted rows, this is the default
+	ExcludeDeleted DeletedScope = iota
+	// IncludeDeleted rows along with rows that are not deleted
+	IncludeDeleted
+	// OnlyDeleted rows are returned
+	OnlyDeleted
+)
+
+type deletedScopeKey struct{}
+
+// WithDeleted returns a context under which queries include soft deleted rows
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deletedScopeKey{}, IncludeDeleted)
+}
+
+// WithOnlyDeleted returns a context under which queries only return soft
+// deleted rows
+func WithOnlyDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deletedScopeKey{}, OnlyDeleted)
+}
+
+// GetDeletedScope of the passed context
+func GetDeletedScope(ctx context.Context) DeletedScope {
+	scope, _ := ctx.Value(deletedScopeKey{}).(DeletedScope)
+	return scope
+}
+
+// DeletedColumn returns the soft delete column of the passed table and true if
+// the table implements SoftDeletable
+func DeletedColumn(table qb.Table) (qb.TableField, bool) {
+	deletable, ok := table.(SoftDeletable)
+	if !ok {
+		return qb.TableField{}, false
+	}
+	return deletable.DeletedColumn(), true
+}
+
+// ScopeDeleted restricts the passed condition, which may be nil, to the rows of
+// the table in the deleted scope of the context. The passed condition is not
+// modified and is returned as is when the table is not SoftDeletable.
+func ScopeDeleted(ctx context.Context, table qb.Table,
+	condition *qb.ConditionExpression) *qb.ConditionExpression {
+	column, ok := DeletedColumn(table)
+	if !ok {
+		return condition
+	}
+	var scope *qb.ConditionExpression
+	switch GetDeletedScope(ctx) {
+	case IncludeDeleted:
+		return condition
+	case OnlyDeleted:
+		scope = column.IsNotNull()
+	default:
+		scope = column.IsNull()
+	}
+	if nil == condition {
+		return scope
+	}
+	// And modifies the receiver so the passed condition is copied first
+	scoped := *condition
+	return scoped.And(scope)
+}
@@ -0,0 +1,71 @@
+package record
+
+import (
+	"context"
+	"testing"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+type softDeletableMeta struct{}
+
+func (m *softDeletableMeta) GetName() string  { return "soft" }
+func (m *softDeletableMeta) GetAlias() string { return "soft" }
+func (m *softDeletableMeta) PrimaryKey() qb.TableField {
+	return qb.TableField{Name: "id", Table: "soft"}
+}
+func (m *softDeletableMeta) SortBy() (qb.TableField, qb.OrderDirection) {
+	return m.PrimaryKey(), qb.Ascending
+}
+func (m *softDeletableMeta) AllColumns() qb.TableField {
+	return qb.TableField{Name: "*", Table: "soft"}
+}
+func (m *softDeletableMeta) ReadColumns() []qb.TableField  { return []qb.TableField{m.PrimaryKey()} }
+func (m *softDeletableMeta) WriteColumns() []qb.TableField { return m.ReadColumns() }
+func (m *softDeletableMeta) DeletedColumn() qb.TableField {
+	return qb.TableField{Name: DeletedOnColumn, Table: "soft"}
+}
+
+type hardDeletableMeta struct {
+	softDeletableMeta
+}
+
+func (m *hardDeletableMeta) DeletedColumn() {}
+
+func TestScopeDeleted(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		table = &softDeletableMeta{}
+		where = table.PrimaryKey().Equal("a")
+	)
+	var tests = []struct {
+		name      string
+		ctx       context.Context
+		table     qb.Table
+		condition *qb.ConditionExpression
+		expected  string
+	}{
+		{"exclude", ctx, table, where, "(`soft`.`id` = ? AND `soft`.`deleted_on` IS NULL)"},
+		{"exclude without condition", ctx, table, nil, "`soft`.`deleted_on` IS NULL"},
+		{"include", WithDeleted(ctx), table, where, "`soft`.`id` = ?"},
+		{"include without condition", WithDeleted(ctx), table, nil, ""},
+		{"only", WithOnlyDeleted(ctx), table, where, "(`soft`.`id` = ? AND `soft`.`deleted_on` IS NOT NULL)"},
+		{"not soft deletable", ctx, &hardDeletableMeta{}, where, "`soft`.`id` = ?"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert1.New(t)
+			actual := ScopeDeleted(tc.ctx, tc.table, tc.condition)
+			if tc.expected == "" {
+				assert.Nil(actual)
+				return
+			}
+			sql, _ := actual.SQL()
+			assert.Equal(tc.expected, sql)
+		})
+	}
+	// the passed condition is never modified
+	sql, _ := where.SQL()
+	assert1.Equal(t, "`soft`.`id` = ?", sql)
+}
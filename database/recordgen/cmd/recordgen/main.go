@@ -0,0 +1,111 @@
+// Command recordgen generates the qb.Table meta type and record.Record methods
+// for a struct from its db struct tags. It is intended to be run by go generate:
+//
+//	//go:generate go run github.com/beaconsoftwarellc/gadget/v2/database/recordgen/cmd/recordgen -type=Widget -prefix=wdg
+//
+// When a MySQL data source name and schema are passed the record structs are
+// generated as well, one file per table, from the schema in a live database:
+//
+//	recordgen -dsn='user:password@tcp(localhost:3306)/' -schema=legacy -dir=./records
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database"
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/database/recordgen"
+	"github.com/beaconsoftwarellc/gadget/v2/stringutil"
+	"github.com/jmoiron/sqlx"
+)
+
+func main() {
+	var (
+		typeName   = flag.String("type", "", "name of the record struct (required)")
+		table      = flag.String("table", "", "database table name (default: type name in snake case)")
+		primaryKey = flag.String("pk", recordgen.DefaultPrimaryKey, "primary key column")
+		sortBy     = flag.String("sort", "", "default sort column (default: primary key)")
+		descending = flag.Bool("desc", false, "sort descending by the sort column")
+		prefix     = flag.String("prefix", "", "generator.ID prefix used to initialize the primary key")
+		output     = flag.String("output", "", "output file (default: <type>_meta.go)")
+		dir        = flag.String("dir", ".", "directory containing the record struct")
+		dsn        = flag.String("dsn", "", "MySQL data source name to generate records from a live schema")
+		schema     = flag.String("schema", "", "schema to generate records for when -dsn is passed")
+		pkg        = flag.String("package", "", "package of the generated records (default: name of -dir)")
+	)
+	flag.Parse()
+	if *dsn != "" {
+		introspect(*dsn, *schema, *pkg, *dir)
+		return
+	}
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	model, err := recordgen.ParseStruct(*dir, *typeName)
+	if nil != err {
+		fail(err)
+	}
+	if *table != "" {
+		model.Table = *table
+	}
+	model.PrimaryKey = *primaryKey
+	model.SortBy = *primaryKey
+	if *sortBy != "" {
+		model.SortBy = *sortBy
+	}
+	model.Descending = *descending
+	model.IDPrefix = *prefix
+	source, err := recordgen.Generate(model)
+	if nil != err {
+		fail(err)
+	}
+	if *output == "" {
+		*output = stringutil.Underscore(*typeName) + "_meta.go"
+	}
+	if err = os.WriteFile(filepath.Join(*dir, *output), source, 0644); nil != err {
+		fail(err)
+	}
+}
+
+func introspect(dsn, schema, pkg, dir string) {
+	if schema == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if pkg == "" {
+		abs, err := filepath.Abs(dir)
+		if nil != err {
+			fail(err)
+		}
+		pkg = filepath.Base(abs)
+	}
+	db, err := sqlx.Connect(qb.MySQLDriver, dsn)
+	if nil != err {
+		fail(err)
+	}
+	defer db.Close()
+	models, err := database.IntrospectSchema(context.Background(), db, schema, pkg)
+	if nil != err {
+		fail(err)
+	}
+	for _, model := range models {
+		source, err := recordgen.GenerateRecord(model)
+		if nil != err {
+			fail(err)
+		}
+		path := filepath.Join(dir, stringutil.Underscore(model.Table)+"_record.go")
+		if err = os.WriteFile(path, source, 0644); nil != err {
+			fail(err)
+		}
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "recordgen: %s\n", err)
+	os.Exit(1)
+}
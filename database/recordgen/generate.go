@@ -0,0 +1,179 @@
+package recordgen
+
+import (
+	"bytes"
+	"go/format"
+	"strings"
+	"text/template"
+
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/stringutil"
+)
+
+const (
+	qbPackage        = "github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	recordPackage    = "github.com/beaconsoftwarellc/gadget/v2/database/record"
+	generatorPackage = "github.com/beaconsoftwarellc/gadget/v2/generator"
+)
+
+var metaTemplate = template.Must(template.New("meta").Parse(`// Code generated by recordgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .StdImports}}
+	"{{.}}"
+{{- end}}
+{{if .StdImports}}
+{{end}}
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+{{- if .Struct}}
+
+// {{.Type}} is a record in the {{.Table}} table
+type {{.Type}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `db:"{{.Column}}{{if .ReadOnly}},read_only{{end}}"` + "`" + `{{if .References}} // references {{.References}}{{end}}
+{{- end}}
+}
+{{- end}}
+
+type {{.MetaType}} struct {
+	alias string
+{{- range .Fields}}
+	{{.Name}} qb.TableField
+{{- end}}
+
+	allColumns qb.TableField
+}
+
+func (p *{{.MetaType}}) AllColumns() qb.TableField {
+	return p.allColumns
+}
+
+func (p *{{.MetaType}}) GetName() string {
+	return "{{.Table}}"
+}
+
+func (p *{{.MetaType}}) GetAlias() string {
+	return p.alias
+}
+
+func (p *{{.MetaType}}) PrimaryKey() qb.TableField {
+	return p.{{.PrimaryKeyField.Name}}
+}
+
+func (p *{{.MetaType}}) SortBy() (qb.TableField, qb.OrderDirection) {
+	return p.{{.SortByField.Name}}, {{if .Descending}}qb.Descending{{else}}qb.Ascending{{end}}
+}
+
+func (p *{{.MetaType}}) ReadColumns() []qb.TableField {
+	return []qb.TableField{
+{{- range .Fields}}
+		p.{{.Name}},
+{{- end}}
+	}
+}
+
+func (p *{{.MetaType}}) WriteColumns() []qb.TableField {
+	return []qb.TableField{
+{{- range .Fields}}{{if not .ReadOnly}}
+		p.{{.Name}},
+{{- end}}{{end}}
+	}
+}
+
+func (p *{{.MetaType}}) Alias(alias string) *{{.MetaType}} {
+	return &{{.MetaType}}{
+		alias: alias,
+{{- range .Fields}}
+		{{.Name}}: qb.TableField{Name: "{{.Column}}", Table: alias},
+{{- end}}
+
+		allColumns: qb.TableField{Name: "*", Table: alias},
+	}
+}
+
+// {{.Type}}Meta is a meta representation of the {{.Table}} table for building ad hoc queries
+var {{.Type}}Meta = (&{{.MetaType}}{}).Alias("{{.Table}}")
+
+// Initialize the {{.Type}}{{if .IDPrefix}} with an id{{end}}
+func (r *{{.Type}}) Initialize() {
+{{- if .IDPrefix}}
+	r.{{.PrimaryKeyField.Name}} = generator.ID("{{.IDPrefix}}")
+{{- end}}
+}
+
+// PrimaryKey of this record
+func (r *{{.Type}}) PrimaryKey() record.PrimaryKeyValue {
+	return record.NewPrimaryKey(r.{{.PrimaryKeyField.Name}})
+}
+
+// Key field name
+func (r *{{.Type}}) Key() string {
+	return "{{.PrimaryKey}}"
+}
+
+// Meta object for this record
+func (r *{{.Type}}) Meta() qb.Table {
+	return {{.Type}}Meta
+}
+`))
+
+type templateData struct {
+	*Model
+	Struct          bool
+	StdImports      []string
+	Imports         []string
+	MetaType        string
+	PrimaryKeyField Field
+	SortByField     Field
+}
+
+// Generate the formatted Go source for the meta type, the global meta variable
+// and the record.Record methods of the passed model.
+func Generate(model *Model) ([]byte, error) {
+	return generate(model, false)
+}
+
+// GenerateRecord generates the record struct for the passed model along with
+// everything generated by Generate.
+func GenerateRecord(model *Model) ([]byte, error) {
+	return generate(model, true)
+}
+
+func generate(model *Model, withStruct bool) ([]byte, error) {
+	if err := model.Validate(); nil != err {
+		return nil, err
+	}
+	data := templateData{
+		Model:    model,
+		Struct:   withStruct,
+		Imports:  []string{qbPackage, recordPackage},
+		MetaType: stringutil.LowerCamelCase(model.Type) + "Meta",
+	}
+	if model.IDPrefix != "" {
+		data.Imports = append(data.Imports, generatorPackage)
+	}
+	if withStruct {
+		for _, field := range model.Fields {
+			if strings.Contains(field.Type, "time.") {
+				data.StdImports = append(data.StdImports, "time")
+				break
+			}
+		}
+	}
+	data.PrimaryKeyField, _ = model.Field(model.PrimaryKey)
+	data.SortByField, _ = model.Field(model.SortBy)
+	var buffer bytes.Buffer
+	if err := metaTemplate.Execute(&buffer, data); nil != err {
+		return nil, errors.Wrap(err)
+	}
+	source, err := format.Source(buffer.Bytes())
+	if nil != err {
+		return nil, errors.Wrap(err)
+	}
+	return source, nil
+}
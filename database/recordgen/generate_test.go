@@ -0,0 +1,136 @@
+package recordgen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+// fset and sourceImporter are shared by every type check so that imported
+// packages are only checked once
+var (
+	fset           = token.NewFileSet()
+	sourceImporter = importer.ForCompiler(fset, "source", nil)
+)
+
+// typeCheck the Go files in dir along with the passed generated source
+func typeCheck(t *testing.T, dir string, source []byte) error {
+	if err := os.WriteFile(filepath.Join(dir, "widget_meta.go"), source, 0644); nil != err {
+		t.Fatal(err)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	files := make([]*ast.File, len(paths))
+	for i, path := range paths {
+		if files[i], err = parser.ParseFile(fset, path, nil, 0); nil != err {
+			return err
+		}
+	}
+	config := types.Config{Importer: sourceImporter}
+	_, err = config.Check(files[0].Name.Name, fset, files, nil)
+	return err
+}
+
+func TestGenerate(t *testing.T) {
+	assert := assert1.New(t)
+	model, err := ParseStruct(writeWidget(t), "Widget")
+	assert.NoError(err)
+	model.SortBy = "created"
+	model.Descending = true
+	model.IDPrefix = "wdg"
+	source, err := Generate(model)
+	assert.NoError(err)
+	code := string(source)
+	assert.Contains(code, "// Code generated by recordgen. DO NOT EDIT.\n\npackage widgets\n")
+	assert.Contains(code, `"github.com/beaconsoftwarellc/gadget/v2/generator"`)
+	assert.Contains(code, "func (p *widgetMeta) GetName() string {\n\treturn \"widget\"\n}")
+	assert.Contains(code, "func (p *widgetMeta) PrimaryKey() qb.TableField {\n\treturn p.ID\n}")
+	assert.Contains(code, "return p.Created, qb.Descending")
+	assert.Contains(code, "return []qb.TableField{\n\t\tp.ID,\n\t\tp.Name,\n\t\tp.Color,\n\t\tp.Created,\n\t}")
+	assert.Contains(code, "func (p *widgetMeta) WriteColumns() []qb.TableField {\n\treturn []qb.TableField{\n"+
+		"\t\tp.ID,\n\t\tp.Name,\n\t\tp.Color,\n\t}")
+	assert.Contains(code, "\t\tColor:   qb.TableField{Name: \"color\", Table: alias},\n")
+	assert.Contains(code, "var WidgetMeta = (&widgetMeta{}).Alias(\"widget\")")
+	assert.Contains(code, "func (r *Widget) Initialize() {\n\tr.ID = generator.ID(\"wdg\")\n}")
+	assert.Contains(code, "return record.NewPrimaryKey(r.ID)")
+	assert.Contains(code, "func (r *Widget) Key() string {\n\treturn \"id\"\n}")
+	assert.Contains(code, "func (r *Widget) Meta() qb.Table {\n\treturn WidgetMeta\n}")
+
+	// without a prefix initialize does nothing
+	model.IDPrefix = ""
+	model.Table = "widgets"
+	source, err = Generate(model)
+	assert.NoError(err)
+	code = string(source)
+	assert.NotContains(code, "generator")
+	assert.Contains(code, "func (r *Widget) Initialize() {\n}")
+	assert.Contains(code, "var WidgetMeta = (&widgetMeta{}).Alias(\"widgets\")")
+
+	model.PrimaryKey = "missing"
+	_, err = Generate(model)
+	assert.EqualError(err, "Widget has no field for primary key column 'missing'")
+}
+
+func TestGenerateRecord(t *testing.T) {
+	assert := assert1.New(t)
+	model := &Model{
+		Package:    "records",
+		Type:       "Address",
+		Table:      "address",
+		PrimaryKey: "id",
+		SortBy:     "created",
+		Fields: []Field{
+			{Name: "ID", Column: "id", Type: "int"},
+			{Name: "UserID", Column: "user_id", Type: "string", References: "user.id"},
+			{Name: "Created", Column: "created", Type: "time.Time", ReadOnly: true},
+		},
+	}
+	source, err := GenerateRecord(model)
+	assert.NoError(err)
+	code := string(source)
+	assert.Contains(code, "import (\n\t\"time\"\n\n\t\"github.com/beaconsoftwarellc/gadget/v2/database/qb\"\n")
+	assert.Contains(code, "// Address is a record in the address table\ntype Address struct {\n"+
+		"\tID      int       `db:\"id\"`\n"+
+		"\tUserID  string    `db:\"user_id\"` // references user.id\n"+
+		"\tCreated time.Time `db:\"created,read_only\"`\n}")
+	assert.Contains(code, "type addressMeta struct {")
+
+	// the struct is only generated by GenerateRecord
+	source, err = Generate(model)
+	assert.NoError(err)
+	assert.NotContains(string(source), "type Address struct")
+	assert.NotContains(string(source), "\"time\"")
+}
+
+func TestGenerate_Compiles(t *testing.T) {
+	assert := assert1.New(t)
+	dir := writeWidget(t)
+	model, err := ParseStruct(dir, "Widget")
+	assert.NoError(err)
+	model.IDPrefix = "wdg"
+	source, err := Generate(model)
+	assert.NoError(err)
+	assert.NoError(typeCheck(t, dir, source))
+
+	model.Type = "Address"
+	model.Package = "records"
+	source, err = GenerateRecord(model)
+	assert.NoError(err)
+	assert.NoError(typeCheck(t, t.TempDir(), source))
+
+	// fields named after generated methods would not compile
+	for _, name := range []string{"Alias", "Initialize", "Key", "Meta"} {
+		model.Fields[1].Name = name
+		_, err = GenerateRecord(model)
+		assert.Error(err, name)
+	}
+}
@@ -0,0 +1,217 @@
+// Package recordgen generates the qb.Table meta type and record.Record methods
+// for a database record from its struct definition so that the columns used
+// by queries can not drift from the fields on the record.
+package recordgen
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/generator"
+	"github.com/beaconsoftwarellc/gadget/v2/stringutil"
+)
+
+const (
+	// DefaultPrimaryKey column for a record
+	DefaultPrimaryKey = "id"
+	// ReadOnlyOption on a db struct tag excludes the column from WriteColumns
+	ReadOnlyOption = "read_only"
+	dbTag          = "db"
+	skipColumn     = "-"
+)
+
+// reserved names are methods on the generated meta type and can not be used
+// as field names
+var reserved = map[string]bool{
+	"Alias":        true,
+	"AllColumns":   true,
+	"GetName":      true,
+	"GetAlias":     true,
+	"PrimaryKey":   true,
+	"SortBy":       true,
+	"ReadColumns":  true,
+	"WriteColumns": true,
+}
+
+// recordMethods are generated on the record and can not be used as field names
+var recordMethods = map[string]bool{
+	"Initialize": true,
+	"PrimaryKey": true,
+	"Key":        true,
+	"Meta":       true,
+}
+
+// Field on a record that maps to a database column
+type Field struct {
+	// Name of the field on the record struct
+	Name string
+	// Column name in the database table
+	Column string
+	// Type of the field as it appears in the record struct
+	Type string
+	// ReadOnly columns are set by the database and are not written
+	ReadOnly bool
+	// References is the 'table.column' this column is a foreign key to
+	References string
+}
+
+// Model of a record and the table it is stored in
+type Model struct {
+	// Package the generated code belongs to
+	Package string
+	// Type name of the record struct
+	Type string
+	// Table name in the database
+	Table string
+	// Fields on the record in column order
+	Fields []Field
+	// PrimaryKey column of the table
+	PrimaryKey string
+	// SortBy column used as the default sort for the table
+	SortBy string
+	// Descending sort for the SortBy column
+	Descending bool
+	// IDPrefix used to generate the primary key in Initialize, when empty
+	// Initialize does nothing
+	IDPrefix string
+}
+
+// Field with the passed column name and whether it was found
+func (m *Model) Field(column string) (Field, bool) {
+	for _, field := range m.Fields {
+		if field.Column == column {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
+
+// Validate that code can be generated for this model
+func (m *Model) Validate() error {
+	if m.Type == "" || m.Table == "" || m.Package == "" {
+		return errors.New("package, type and table are required")
+	}
+	if len(m.Fields) == 0 {
+		return errors.Newf("%s has no database fields", m.Type)
+	}
+	for _, field := range m.Fields {
+		if reserved[field.Name] {
+			return errors.Newf("%s.%s conflicts with a method on the generated meta type",
+				m.Type, field.Name)
+		}
+		if recordMethods[field.Name] {
+			return errors.Newf("%s.%s conflicts with a method generated on the record",
+				m.Type, field.Name)
+		}
+	}
+	pk, ok := m.Field(m.PrimaryKey)
+	if !ok {
+		return errors.Newf("%s has no field for primary key column '%s'", m.Type, m.PrimaryKey)
+	}
+	if _, ok := m.Field(m.SortBy); !ok {
+		return errors.Newf("%s has no field for sort column '%s'", m.Type, m.SortBy)
+	}
+	if m.IDPrefix != "" {
+		if pk.Type != "string" {
+			return errors.Newf("an ID prefix requires a string primary key but %s.%s is %s",
+				m.Type, pk.Name, pk.Type)
+		}
+		if len(m.IDPrefix) > generator.MaxPrefix {
+			return errors.Newf("ID prefix '%s' is longer than %d characters", m.IDPrefix,
+				generator.MaxPrefix)
+		}
+	}
+	return nil
+}
+
+// ParseStruct finds the struct with the passed type name in the Go files in
+// dir and returns a model of it with the table named after the type, the
+// default primary key and sorted by the primary key. Fields are mapped to
+// columns using the db struct tag, untagged fields use the lower case field
+// name in the same way as sqlx.
+func ParseStruct(dir, typeName string) (*Model, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if nil != err {
+		return nil, errors.Wrap(err)
+	}
+	fset := token.NewFileSet()
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if nil != err {
+			return nil, errors.Wrap(err)
+		}
+		if structType, ok := findStruct(file, typeName); ok {
+			return newModel(file.Name.Name, typeName, structType), nil
+		}
+	}
+	return nil, errors.Newf("struct %s not found in %s", typeName, dir)
+}
+
+func findStruct(file *ast.File, typeName string) (*ast.StructType, bool) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if typeSpec.Name.Name != typeName {
+				continue
+			}
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			return structType, ok
+		}
+	}
+	return nil, false
+}
+
+func newModel(pkg, typeName string, structType *ast.StructType) *Model {
+	model := &Model{
+		Package:    pkg,
+		Type:       typeName,
+		Table:      stringutil.Underscore(typeName),
+		PrimaryKey: DefaultPrimaryKey,
+		SortBy:     DefaultPrimaryKey,
+	}
+	for _, astField := range structType.Fields.List {
+		// embedded structs such as record.DefaultRecord are not columns
+		if len(astField.Names) == 0 {
+			continue
+		}
+		var tag string
+		if nil != astField.Tag {
+			raw, _ := strconv.Unquote(astField.Tag.Value)
+			tag = reflect.StructTag(raw).Get(dbTag)
+		}
+		column, options := stringutil.ParseTag(tag)
+		if column == skipColumn {
+			continue
+		}
+		for _, name := range astField.Names {
+			if !name.IsExported() {
+				continue
+			}
+			field := Field{
+				Name:     name.Name,
+				Column:   column,
+				Type:     types.ExprString(astField.Type),
+				ReadOnly: options.Contains(ReadOnlyOption),
+			}
+			if field.Column == "" {
+				field.Column = strings.ToLower(name.Name)
+			}
+			model.Fields = append(model.Fields, field)
+		}
+	}
+	return model
+}
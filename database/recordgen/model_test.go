@@ -0,0 +1,135 @@
+package recordgen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+const widgetSource = `package widgets
+
+import (
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/record"
+)
+
+type Other struct {
+	Value int ` + "`db:\"value\"`" + `
+}
+
+type Widget struct {
+	record.DefaultRecord
+	ID        string    ` + "`db:\"id\"`" + `
+	Name      string    ` + "`db:\"name\" json:\"name\"`" + `
+	Color     *string
+	internal  int
+	Skipped   string    ` + "`db:\"-\"`" + `
+	Created   time.Time ` + "`db:\"created,read_only\"`" + `
+}
+`
+
+func writeWidget(t *testing.T) string {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "widget.go"), []byte(widgetSource), 0644); nil != err {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestParseStruct(t *testing.T) {
+	assert := assert1.New(t)
+	dir := writeWidget(t)
+	model, err := ParseStruct(dir, "Widget")
+	assert.NoError(err)
+	assert.Equal(&Model{
+		Package:    "widgets",
+		Type:       "Widget",
+		Table:      "widget",
+		PrimaryKey: "id",
+		SortBy:     "id",
+		Fields: []Field{
+			{Name: "ID", Column: "id", Type: "string"},
+			{Name: "Name", Column: "name", Type: "string"},
+			{Name: "Color", Column: "color", Type: "*string"},
+			{Name: "Created", Column: "created", Type: "time.Time", ReadOnly: true},
+		},
+	}, model)
+
+	_, err = ParseStruct(dir, "Missing")
+	assert.EqualError(err, "struct Missing not found in "+dir)
+}
+
+func TestModel_Validate(t *testing.T) {
+	fields := []Field{
+		{Name: "ID", Column: "id", Type: "string"},
+		{Name: "Count", Column: "count", Type: "int"},
+	}
+	var tests = []struct {
+		name     string
+		model    Model
+		expected string
+	}{
+		{
+			name:     "missing type",
+			model:    Model{Package: "p", Table: "t", Fields: fields},
+			expected: "package, type and table are required",
+		},
+		{
+			name:     "no fields",
+			model:    Model{Package: "p", Type: "T", Table: "t"},
+			expected: "T has no database fields",
+		},
+		{
+			name: "reserved field",
+			model: Model{Package: "p", Type: "T", Table: "t", PrimaryKey: "id", SortBy: "id",
+				Fields: []Field{{Name: "Alias", Column: "alias", Type: "string"}}},
+			expected: "T.Alias conflicts with a method on the generated meta type",
+		},
+		{
+			name: "record method field",
+			model: Model{Package: "p", Type: "T", Table: "t", PrimaryKey: "id", SortBy: "id",
+				Fields: []Field{{Name: "ID", Column: "id", Type: "string"}, {Name: "Key", Column: "key",
+					Type: "string"}}},
+			expected: "T.Key conflicts with a method generated on the record",
+		},
+		{
+			name:     "missing primary key",
+			model:    Model{Package: "p", Type: "T", Table: "t", PrimaryKey: "pk", SortBy: "id", Fields: fields},
+			expected: "T has no field for primary key column 'pk'",
+		},
+		{
+			name:     "missing sort",
+			model:    Model{Package: "p", Type: "T", Table: "t", PrimaryKey: "id", SortBy: "x", Fields: fields},
+			expected: "T has no field for sort column 'x'",
+		},
+		{
+			name: "prefix on int key",
+			model: Model{Package: "p", Type: "T", Table: "t", PrimaryKey: "count", SortBy: "id", Fields: fields,
+				IDPrefix: "t"},
+			expected: "an ID prefix requires a string primary key but T.Count is int",
+		},
+		{
+			name: "prefix too long",
+			model: Model{Package: "p", Type: "T", Table: "t", PrimaryKey: "id", SortBy: "id", Fields: fields,
+				IDPrefix: "toolongprefix"},
+			expected: "ID prefix 'toolongprefix' is longer than 8 characters",
+		},
+		{
+			name:  "valid",
+			model: Model{Package: "p", Type: "T", Table: "t", PrimaryKey: "id", SortBy: "count", Fields: fields},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.model.Validate()
+			if tc.expected == "" {
+				assert1.NoError(t, err)
+			} else {
+				assert1.EqualError(t, err, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/utility"
+	"github.com/beaconsoftwarellc/gadget/v2/log"
+	"github.com/jmoiron/sqlx"
+)
+
+// ReplicaSelection determines which healthy replica serves a read
+type ReplicaSelection int
+
+const (
+	// RoundRobin cycles through the healthy replicas
+	RoundRobin ReplicaSelection = iota
+	// LeastLatency chooses the healthy replica with the fastest last ping
+	LeastLatency
+)
+
+type replica struct {
+	client     Client
+	db         *transactable
+	connection string
+	healthy    atomic.Bool
+	latency    atomic.Int64
+}
+
+// replicaSet of read only connections that are pinged periodically, reads are
+// only routed to replicas whose last ping succeeded
+type replicaSet struct {
+	replicas  []*replica
+	selection ReplicaSelection
+	next      atomic.Uint64
+	logger    log.Logger
+	stop      chan struct{}
+	stopped   sync.WaitGroup
+	// committed is when a transaction was last committed on the primary by any
+	// API of the connection, in nanoseconds since the epoch
+	committed atomic.Int64
+}
+
+// commit records that a transaction was committed on the primary
+func (set *replicaSet) commit() {
+	set.committed.Store(time.Now().UnixNano())
+}
+
+// pinned indicates that reads are made on the primary because a transaction
+// was committed on it within window
+func (set *replicaSet) pinned(window time.Duration) bool {
+	return time.Since(time.Unix(0, set.committed.Load())) < window
+}
+
+func newReplicaSet(clients []Client, connections []string, selection ReplicaSelection,
+	logger log.Logger) *replicaSet {
+	set := &replicaSet{selection: selection, logger: logger, stop: make(chan struct{})}
+	for i, client := range clients {
+		set.replicas = append(set.replicas, &replica{
+			client:     client,
+			db:         &transactable{db: client, options: &sql.TxOptions{ReadOnly: true}},
+			connection: utility.ObfuscateConnection(connections[i]),
+		})
+	}
+	return set
+}
+
+// connectReplicas opens a connection to each replica, replicas that can not be
+// reached are marked unhealthy until a ping succeeds
+func connectReplicas(cfg Configuration) (*replicaSet, error) {
+	connections := cfg.ReplicaConnections()
+	if len(connections) == 0 {
+		return nil, nil
+	}
+	clients := make([]Client, len(connections))
+	for i, connection := range connections {
+		conn, err := sqlx.Open(cfg.DatabaseDialect(), connection)
+		if nil != err {
+			for _, client := range clients[:i] {
+				_ = client.Close()
+			}
+			return nil, err
+		}
+		ConfigurePool(conn, cfg)
+		clients[i] = conn
+	}
+	set := newReplicaSet(clients, connections, cfg.ReplicaSelection(), cfg.Logger())
+	set.check(cfg.ReplicaHealthCheckInterval())
+	set.monitor(cfg.ReplicaHealthCheckInterval())
+	return set, nil
+}
+
+// choose a healthy replica, nil when there are none
+func (s *replicaSet) choose() *replica {
+	var healthy []*replica
+	for _, r := range s.replicas {
+		if r.healthy.Load() {
+			healthy = append(healthy, r)
+		}
+	}
+	if len(healthy) == 0 {
+		return nil
+	}
+	if s.selection == LeastLatency {
+		fastest := healthy[0]
+		for _, r := range healthy[1:] {
+			if r.latency.Load() < fastest.latency.Load() {
+				fastest = r
+			}
+		}
+		return fastest
+	}
+	return healthy[(s.next.Add(1)-1)%uint64(len(healthy))]
+}
+
+// fail marks the replica unhealthy until the next successful ping
+func (s *replicaSet) fail(r *replica, err error) {
+	if r.healthy.Swap(false) {
+		s.logger.Warnf("replica %s failed, routing reads elsewhere: %s", r.connection, err)
+	}
+}
+
+// check the health of each replica, a ping that does not complete within
+// timeout fails
+func (s *replicaSet) check(timeout time.Duration) {
+	var wg sync.WaitGroup
+	for _, r := range s.replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			start := time.Now()
+			if err := r.client.PingContext(ctx); nil != err {
+				s.fail(r, err)
+				return
+			}
+			r.latency.Store(int64(time.Since(start)))
+			if !r.healthy.Swap(true) {
+				s.logger.Infof("replica %s is healthy", r.connection)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// monitor the replicas by checking them every interval until closed
+func (s *replicaSet) monitor(interval time.Duration) {
+	s.stopped.Add(1)
+	go func() {
+		defer s.stopped.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.check(interval)
+			}
+		}
+	}()
+}
+
+// Close the replica connections and stop monitoring them
+func (s *replicaSet) Close() error {
+	close(s.stop)
+	s.stopped.Wait()
+	var err error
+	for _, r := range s.replicas {
+		if closeErr := r.client.Close(); nil == err {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/transaction"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/log"
+	assert1 "github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestReplicaSet(t *testing.T, selection ReplicaSelection, count int) (*replicaSet, []*MockClient) {
+	ctrl := gomock.NewController(t)
+	var (
+		clients     []Client
+		mocks       []*MockClient
+		connections []string
+	)
+	for range count {
+		client := NewMockClient(ctrl)
+		clients = append(clients, client)
+		mocks = append(mocks, client)
+		connections = append(connections, "user:password@tcp(replica:3306)/db")
+	}
+	return newReplicaSet(clients, connections, selection, log.Global()), mocks
+}
+
+func Test_replicaSet_choose(t *testing.T) {
+	assert := assert1.New(t)
+	set, _ := newTestReplicaSet(t, RoundRobin, 3)
+	assert.Nil(set.choose())
+
+	for _, r := range set.replicas {
+		r.healthy.Store(true)
+	}
+	set.fail(set.replicas[1], errors.New("connection refused"))
+	assert.Equal(set.replicas[0], set.choose())
+	assert.Equal(set.replicas[2], set.choose())
+	assert.Equal(set.replicas[0], set.choose())
+
+	set.selection = LeastLatency
+	set.replicas[0].latency.Store(int64(time.Second))
+	set.replicas[2].latency.Store(int64(time.Millisecond))
+	assert.Equal(set.replicas[2], set.choose())
+	assert.Equal(set.replicas[2], set.choose())
+}
+
+func Test_replicaSet_check(t *testing.T) {
+	assert := assert1.New(t)
+	set, clients := newTestReplicaSet(t, RoundRobin, 2)
+	clients[0].EXPECT().PingContext(gomock.Any()).Return(nil)
+	clients[1].EXPECT().PingContext(gomock.Any()).Return(errors.New("connection refused"))
+	set.check(time.Second)
+	assert.True(set.replicas[0].healthy.Load())
+	assert.False(set.replicas[1].healthy.Load())
+
+	// failover and recovery
+	clients[0].EXPECT().PingContext(gomock.Any()).Return(errors.New("connection refused"))
+	clients[1].EXPECT().PingContext(gomock.Any()).Return(nil)
+	set.check(time.Second)
+	assert.False(set.replicas[0].healthy.Load())
+	assert.True(set.replicas[1].healthy.Load())
+
+	for _, client := range clients {
+		client.EXPECT().Close().Return(nil)
+	}
+	assert.NoError(set.Close())
+}
+
+func Test_api_runRead(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	primary := NewMockClient(ctrl)
+	set, replicas := newTestReplicaSet(t, RoundRobin, 2)
+	for _, r := range set.replicas {
+		r.healthy.Store(true)
+	}
+	database := &api{
+		db:            &transactable{db: primary},
+		replicas:      set,
+		configuration: &InstanceConfig{Log: log.Global(), ReadYourWrites: time.Minute},
+	}
+	ctx := context.Background()
+	readOnly := &sql.TxOptions{ReadOnly: true}
+	expected := errors.New("connection refused")
+
+	// replicas that fail are marked unhealthy and the read falls back to the
+	// primary
+	gomock.InOrder(
+		replicas[0].EXPECT().BeginTxx(ctx, readOnly).Return(nil, expected),
+		replicas[1].EXPECT().BeginTxx(ctx, readOnly).Return(nil, expected),
+		primary.EXPECT().BeginTxx(ctx, nil).Return(nil, expected),
+	)
+	assert.EqualError(database.Read(&TestRecord{}, (&TestRecord{}).PrimaryKey()), expected.Error())
+	assert.Nil(set.choose())
+	assert.Zero(set.committed.Load())
+
+	// reads are pinned to the primary after a commit
+	for _, r := range set.replicas {
+		r.healthy.Store(true)
+	}
+	set.commit()
+	primary.EXPECT().BeginTxx(ctx, nil).Return(nil, expected)
+	assert.EqualError(database.ListWhere(&TestRecord{}, nil, nil, nil), expected.Error())
+
+	// writes are made on the primary
+	set.committed.Store(0)
+	primary.EXPECT().BeginTxx(ctx, nil).Return(nil, expected)
+	assert.EqualError(database.Create(&TestRecord{}), expected.Error())
+}
+
+func Test_connection_Database_ReadYourWrites(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	primary := NewMockClient(ctrl)
+	set, _ := newTestReplicaSet(t, RoundRobin, 1)
+	set.replicas[0].healthy.Store(true)
+	c := &connection{
+		client:        primary,
+		db:            &transactable{db: primary},
+		replicas:      set,
+		configuration: &InstanceConfig{Log: log.Global(), ReadYourWrites: time.Minute},
+		connected:     true,
+	}
+	ctx := context.Background()
+	expected := errors.New("connection refused")
+
+	// a commit on one API pins the reads of the next to the primary
+	tx := transaction.NewMockTransaction(ctrl)
+	tx.EXPECT().Commit().Return(nil)
+	writer := c.Database().(*api)
+	writer.tx = tx
+	assert.NoError(writer.Commit())
+	primary.EXPECT().BeginTxx(ctx, nil).Return(nil, expected)
+	assert.EqualError(c.Database().Read(&TestRecord{}, (&TestRecord{}).PrimaryKey()), expected.Error())
+}
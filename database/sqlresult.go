@@ -6,9 +6,20 @@ import (
 	"github.com/beaconsoftwarellc/gadget/v2/errors"
 )
 
+// BulkResult is the sql.Result returned by committing a bulk operation, which
+// is aggregated over every chunk sent
+type BulkResult interface {
+	sql.Result
+	// InsertIDs reported by the driver for each chunk in the order they were
+	// sent, see sql.Result.LastInsertId. Chunks for which the driver does not
+	// support LastInsertId are omitted.
+	InsertIDs() []int64
+}
+
 // implements sql.Result
 type result struct {
 	rowsAffected int64
+	insertIDs    []int64
 }
 
 // Consume the passed result and add it to this result
@@ -21,10 +32,30 @@ func (r *result) Consume(sqlResult sql.Result) error {
 	return nil
 }
 
+// ConsumeChunk adds the rows affected by the passed result of a chunk of a bulk
+// operation and records its insert ID when it is supported
+func (r *result) ConsumeChunk(sqlResult sql.Result) error {
+	if err := r.Consume(sqlResult); nil != err {
+		return err
+	}
+	if id, err := sqlResult.LastInsertId(); nil == err {
+		r.insertIDs = append(r.insertIDs, id)
+	}
+	return nil
+}
+
+// LastInsertId of the last chunk that reported one
 func (r *result) LastInsertId() (int64, error) {
-	return 0, errors.New("not supported")
+	if len(r.insertIDs) == 0 {
+		return 0, errors.New("not supported")
+	}
+	return r.insertIDs[len(r.insertIDs)-1], nil
 }
 
 func (r *result) RowsAffected() (int64, error) {
 	return r.rowsAffected, nil
 }
+
+func (r *result) InsertIDs() []int64 {
+	return r.insertIDs
+}
@@ -0,0 +1,155 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/audit"
+	dberrors "github.com/beaconsoftwarellc/gadget/v2/database/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/database/record"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+)
+
+func (tx *transaction) SetAudit(enabled bool) {
+	tx.auditing = enabled
+}
+
+func (tx *transaction) History(obj record.Record) ([]*audit.Entry, errors.TracerError) {
+	return tx.HistoryContext(context.Background(), obj)
+}
+
+func (tx *transaction) HistoryContext(ctx context.Context, obj record.Record) ([]*audit.Entry, errors.TracerError) {
+	var entries []*audit.Entry
+	stmt, values, err := audit.HistoryQuery(obj.Meta(), obj.PrimaryKey()).
+		WithDialect(tx.dialect).
+		SQL(nil)
+	if nil != err {
+		return nil, errors.Wrap(err)
+	}
+	if err = tx.implementation.SelectContext(ctx, &entries, stmt, values...); nil != err {
+		return nil, dberrors.TranslateErrorFor(tx.dialect, err, dberrors.Select, stmt)
+	}
+	return entries, nil
+}
+
+// actor that changes made under the passed context are attributed to
+func (tx *transaction) actor(ctx context.Context) string {
+	if actor, ok := audit.GetActor(ctx); ok {
+		return actor
+	}
+	if nil == tx.log {
+		return ""
+	}
+	return tx.log.GetSessionID()
+}
+
+// writeAudit inserts an entry for the change of a record from before to after,
+// either of which may be nil. Nothing is written when auditing is disabled or no
+// column changed.
+func (tx *transaction) writeAudit(ctx context.Context, operation audit.Operation,
+	before, after record.Record) errors.TracerError {
+	if !tx.auditing {
+		return nil
+	}
+	changed := after
+	if nil == changed {
+		changed = before
+	}
+	// before and after are passed as any so that a nil record is a nil value
+	var beforeValue, afterValue any
+	if nil != before {
+		beforeValue = before
+	}
+	if nil != after {
+		afterValue = after
+	}
+	changes, ok, err := audit.Diff(changed.Meta(), beforeValue, afterValue)
+	if nil != err || !ok {
+		return errors.Wrap(err)
+	}
+	entry := audit.NewEntry(changed.Meta(), changed.PrimaryKey(), operation, tx.actor(ctx), changes)
+	stmt, err := qb.Insert(audit.EntryMeta.WriteColumns()...).WithDialect(tx.dialect).ParameterizedSQL()
+	if nil != err {
+		return errors.Wrap(err)
+	}
+	if _, err = tx.implementation.NamedExecContext(ctx, stmt, entry); nil != err {
+		return dberrors.TranslateErrorFor(tx.dialect, err, dberrors.Insert, stmt)
+	}
+	return nil
+}
+
+// auditWhere calls change, which modifies the rows of the table of obj matching
+// where, and writes an entry for each row that changed when auditing is enabled
+func (tx *transaction) auditWhere(ctx context.Context, obj record.Record, where *qb.ConditionExpression,
+	operation audit.Operation, change func() (int64, errors.TracerError)) (int64, errors.TracerError) {
+	if !tx.auditing {
+		return change()
+	}
+	before, err := tx.snapshot(ctx, obj, where)
+	if nil != err {
+		return 0, err
+	}
+	rowsAffected, err := change()
+	if nil != err || len(before) == 0 {
+		return rowsAffected, err
+	}
+	after := make(map[string]record.Record)
+	if operation != audit.Delete {
+		keys := make([]any, len(before))
+		for i, row := range before {
+			keys[i] = row.PrimaryKey().Value()
+		}
+		rows, err := tx.snapshot(ctx, obj, obj.Meta().PrimaryKey().In(keys...))
+		if nil != err {
+			return 0, err
+		}
+		for _, row := range rows {
+			after[fmt.Sprint(row.PrimaryKey().Value())] = row
+		}
+	}
+	for _, row := range before {
+		if err = tx.writeAudit(ctx, operation, row,
+			after[fmt.Sprint(row.PrimaryKey().Value())]); nil != err {
+			return 0, err
+		}
+	}
+	return rowsAffected, nil
+}
+
+// current state of obj in the database when auditing is enabled, nil if it
+// does not exist
+func (tx *transaction) current(ctx context.Context, obj record.Record) (record.Record, errors.TracerError) {
+	if !tx.auditing {
+		return nil, nil
+	}
+	rows, err := tx.snapshot(ctx, obj, obj.Meta().PrimaryKey().Equal(obj.PrimaryKey().Value()))
+	if nil != err || len(rows) == 0 {
+		return nil, err
+	}
+	return rows[0], nil
+}
+
+// snapshot reads the rows of the table of obj matching where into new records
+// of the same type as obj
+func (tx *transaction) snapshot(ctx context.Context, obj record.Record,
+	where *qb.ConditionExpression) ([]record.Record, errors.TracerError) {
+	stmt, values, err := qb.Select(obj.Meta().AllColumns()).
+		From(obj.Meta()).
+		Where(where).
+		WithDialect(tx.dialect).
+		SQL(nil)
+	if nil != err {
+		return nil, errors.Wrap(err)
+	}
+	target := reflect.New(reflect.SliceOf(reflect.TypeOf(obj)))
+	if err = tx.implementation.SelectContext(ctx, target.Interface(), stmt, values...); nil != err {
+		return nil, dberrors.TranslateErrorFor(tx.dialect, err, dberrors.Select, stmt)
+	}
+	rows := make([]record.Record, target.Elem().Len())
+	for i := range rows {
+		rows[i] = target.Elem().Index(i).Interface().(record.Record)
+	}
+	return rows, nil
+}
@@ -0,0 +1,79 @@
+package transaction
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/audit"
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/database/record"
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+type auditRecord struct {
+	ID   string `db:"id"`
+	Name string `db:"name"`
+}
+
+func (r *auditRecord) Initialize()                        {}
+func (r *auditRecord) PrimaryKey() record.PrimaryKeyValue { return record.NewPrimaryKey(r.ID) }
+func (r *auditRecord) Key() string                        { return "id" }
+func (r *auditRecord) Meta() qb.Table                     { return streamTable }
+
+const (
+	auditInsert = "INSERT INTO `audit_entry` (`audit_entry`.`id`, `audit_entry`.`table_name`, " +
+		"`audit_entry`.`record_id`, `audit_entry`.`operation`, `audit_entry`.`actor`, " +
+		"`audit_entry`.`created`, `audit_entry`.`changes`) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	auditSelect = "SELECT `stream`.* FROM `stream` AS `stream` WHERE `stream`.`id` = ?"
+)
+
+func TestTransaction_Audit(t *testing.T) {
+	assert := assert1.New(t)
+	connector := &streamConnector{values: [][]driver.Value{{"a", "first"}}, affected: 1}
+	tx := newStreamTransaction(t, connector)
+	obj := &auditRecord{ID: "a", Name: "first"}
+	ctx := audit.WithActor(context.Background(), "tester")
+
+	// nothing is written until auditing is enabled
+	assert.NoError(tx.CreateContext(ctx, obj))
+	assert.NotContains(connector.queries, auditInsert)
+
+	tx.SetAudit(true)
+	connector.queries = nil
+	assert.NoError(tx.CreateContext(ctx, obj))
+	assert.Equal(auditInsert, connector.queries[len(connector.queries)-1])
+
+	// the row read back is unchanged so there is nothing to record
+	connector.queries = nil
+	_, err := tx.UpdateWhereContext(ctx, obj, streamTable.ID.Equal("a"),
+		qb.FieldValue{Field: streamTable.Name, Value: "first"})
+	assert.NoError(err)
+	assert.Equal(auditSelect, connector.queries[0])
+	assert.NotContains(connector.queries, auditInsert)
+
+	connector.queries = nil
+	assert.NoError(tx.DeleteContext(ctx, obj))
+	assert.Equal([]string{auditSelect, "DELETE FROM `stream` WHERE `stream`.`id` = ?", auditInsert},
+		connector.queries)
+}
+
+func TestTransaction_History(t *testing.T) {
+	assert := assert1.New(t)
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	connector := &streamConnector{
+		columns: []string{"id", "table_name", "record_id", "operation", "actor", "created", "changes"},
+		values: [][]driver.Value{{"AUD1", "stream", "a", "CREATE", "tester", created,
+			`{"name":{"before":null,"after":"first"}}`}},
+	}
+	tx := newStreamTransaction(t, connector)
+
+	entries, err := tx.History(&auditRecord{ID: "a"})
+	assert.NoError(err)
+	assert.Equal([]*audit.Entry{{ID: "AUD1", TableName: "stream", RecordID: "a", Operation: audit.Create,
+		Actor: "tester", Created: created, Changes: `{"name":{"before":null,"after":"first"}}`}}, entries)
+	assert.Equal([]string{"SELECT `audit_entry`.* FROM `audit_entry` AS `audit_entry` WHERE " +
+		"(`audit_entry`.`table_name` = ? AND `audit_entry`.`record_id` = ?) ORDER BY " +
+		"`audit_entry`.`created` ASC, `audit_entry`.`id` ASC"}, connector.queries)
+}
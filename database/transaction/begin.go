@@ -1,8 +1,28 @@
 package transaction
 
+import "context"
+
 // Begin has methods for starting transactions
 type Begin interface {
 	// Begin transaction on the underlying transactable datastructure and
 	// return it
 	Begin() (Implementation, error)
 }
+
+// BeginContexter is implemented by a Begin that can start transactions which
+// are rolled back if a context is done
+type BeginContexter interface {
+	// BeginContext starts a transaction on the underlying transactable
+	// datastructure that is rolled back if the context is done before it
+	// is committed.
+	BeginContext(ctx context.Context) (Implementation, error)
+}
+
+// beginContext on db when it is a BeginContexter, otherwise the context is
+// ignored and the transaction is started with Begin
+func beginContext(ctx context.Context, db Begin) (Implementation, error) {
+	if contexter, ok := db.(BeginContexter); ok {
+		return contexter.BeginContext(ctx)
+	}
+	return db.Begin()
+}
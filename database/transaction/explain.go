@@ -0,0 +1,232 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/log"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	explainFormat = "EXPLAIN FORMAT=JSON %s"
+	// DefaultExplainTimeout for an EXPLAIN of a slow query
+	DefaultExplainTimeout = 5 * time.Second
+	// maxConcurrentExplains that may run at the same time, slow queries that
+	// arrive while this many are running are logged without a plan
+	maxConcurrentExplains = 2
+)
+
+var (
+	explainable        = regexp.MustCompile(`(?i)^\s*(SELECT|INSERT|UPDATE|DELETE|REPLACE)\b`)
+	stringLiteral      = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'`)
+	numberLiteral      = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	placeholderList    = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	valuesList         = regexp.MustCompile(`(\(\?\))(?:\s*,\s*\(\?\))+`)
+	namedPlaceholder   = regexp.MustCompile(`:\w+`)
+	repeatedWhitespace = regexp.MustCompile(`\s+`)
+)
+
+// ExplainClient executes EXPLAIN statements on a connection other than the one
+// of the transaction being explained, sqlx.DB satisfies this interface
+type ExplainClient interface {
+	QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row
+}
+
+// NormalizeQuery replaces the literals and placeholders in a statement so that
+// executions of the same query with different arguments or numbers of values
+// in a list are the same
+func NormalizeQuery(statement string) string {
+	normalized := stringLiteral.ReplaceAllString(statement, "?")
+	normalized = namedPlaceholder.ReplaceAllString(normalized, "?")
+	normalized = numberLiteral.ReplaceAllString(normalized, "?")
+	normalized = placeholderList.ReplaceAllString(normalized, "(?)")
+	normalized = valuesList.ReplaceAllString(normalized, "$1")
+	return strings.TrimSpace(repeatedWhitespace.ReplaceAllString(normalized, " "))
+}
+
+// Plan is a summary of the problems in the plan of a query
+type Plan struct {
+	// FullScans of tables
+	FullScans []string
+	// Filesort is used to order the results
+	Filesort bool
+	// TemporaryTable is used to execute the query
+	TemporaryTable bool
+	// UnusedIndexes by table that were possible but not used for a full scan
+	UnusedIndexes map[string][]string
+}
+
+// ParsePlan of a MySQL EXPLAIN FORMAT=JSON statement
+func ParsePlan(explained []byte) (*Plan, error) {
+	var root any
+	if err := json.Unmarshal(explained, &root); nil != err {
+		return nil, errors.Wrap(err)
+	}
+	plan := &Plan{UnusedIndexes: make(map[string][]string)}
+	plan.walk(root)
+	return plan, nil
+}
+
+func (p *Plan) walk(node any) {
+	switch value := node.(type) {
+	case []any:
+		for _, child := range value {
+			p.walk(child)
+		}
+	case map[string]any:
+		if filesort, _ := value["using_filesort"].(bool); filesort {
+			p.Filesort = true
+		}
+		if temporary, _ := value["using_temporary_table"].(bool); temporary {
+			p.TemporaryTable = true
+		}
+		if table, ok := value["table_name"].(string); ok {
+			p.table(table, value)
+		}
+		for _, child := range value {
+			p.walk(child)
+		}
+	}
+}
+
+func (p *Plan) table(name string, table map[string]any) {
+	if access, _ := table["access_type"].(string); access != "ALL" {
+		return
+	}
+	p.FullScans = append(p.FullScans, name)
+	if _, ok := table["key"]; ok {
+		return
+	}
+	possible, _ := table["possible_keys"].([]any)
+	for _, key := range possible {
+		p.UnusedIndexes[name] = append(p.UnusedIndexes[name], fmt.Sprint(key))
+	}
+}
+
+// String is a compact summary of the plan
+func (p *Plan) String() string {
+	var problems []string
+	if len(p.FullScans) > 0 {
+		problems = append(problems, "full scan of "+strings.Join(p.FullScans, ", "))
+	}
+	if p.Filesort {
+		problems = append(problems, "filesort")
+	}
+	if p.TemporaryTable {
+		problems = append(problems, "temporary table")
+	}
+	tables := make([]string, 0, len(p.UnusedIndexes))
+	for table := range p.UnusedIndexes {
+		tables = append(tables, table)
+	}
+	slices.Sort(tables)
+	for _, table := range tables {
+		problems = append(problems, fmt.Sprintf("unused indexes on %s (%s)", table,
+			strings.Join(p.UnusedIndexes[table], ", ")))
+	}
+	if len(problems) == 0 {
+		return "no problems found"
+	}
+	return strings.Join(problems, "; ")
+}
+
+// Explainer runs EXPLAIN for slow queries asynchronously, caching the summary
+// of the plan for each normalized query. Only MySQL is supported.
+type Explainer struct {
+	db      ExplainClient
+	log     log.Logger
+	timeout time.Duration
+	running chan struct{}
+	mutex   sync.Mutex
+	plans   map[string]string
+	pending map[string]bool
+}
+
+// NewExplainer that explains queries using db
+func NewExplainer(db ExplainClient, logger log.Logger) *Explainer {
+	return &Explainer{
+		db:      db,
+		log:     logger,
+		timeout: DefaultExplainTimeout,
+		running: make(chan struct{}, maxConcurrentExplains),
+		plans:   make(map[string]string),
+		pending: make(map[string]bool),
+	}
+}
+
+// Explain the query and call fn with the summary of the plan. The summary is
+// empty when the query can not be explained, is already being explained or too
+// many queries are being explained. fn is called synchronously when the plan of
+// the normalized query is cached and from another goroutine otherwise.
+func (e *Explainer) Explain(query Query, fn func(summary string)) {
+	if !explainable.MatchString(query.Statement) {
+		fn("")
+		return
+	}
+	normalized := NormalizeQuery(query.Statement)
+	e.mutex.Lock()
+	if summary, ok := e.plans[normalized]; ok {
+		e.mutex.Unlock()
+		fn(summary)
+		return
+	}
+	if e.pending[normalized] {
+		e.mutex.Unlock()
+		fn("")
+		return
+	}
+	select {
+	case e.running <- struct{}{}:
+	default:
+		e.mutex.Unlock()
+		fn("")
+		return
+	}
+	e.pending[normalized] = true
+	e.mutex.Unlock()
+
+	go func() {
+		defer func() { <-e.running }()
+		summary, err := e.explain(query)
+		e.mutex.Lock()
+		delete(e.pending, normalized)
+		if nil == err {
+			e.plans[normalized] = summary
+		}
+		e.mutex.Unlock()
+		if nil != err {
+			e.log.Warnf("failed to explain slow query: %s", err)
+		}
+		fn(summary)
+	}()
+}
+
+func (e *Explainer) explain(query Query) (string, error) {
+	statement, args := query.Statement, query.Args
+	if query.Named && len(args) == 1 {
+		var err error
+		if statement, args, err = sqlx.Named(statement, args[0]); nil != err {
+			return "", errors.Wrap(err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	var explained string
+	if err := e.db.QueryRowxContext(ctx, fmt.Sprintf(explainFormat, statement),
+		args...).Scan(&explained); nil != err {
+		return "", errors.Wrap(err)
+	}
+	plan, err := ParsePlan([]byte(explained))
+	if nil != err {
+		return "", err
+	}
+	return plan.String(), nil
+}
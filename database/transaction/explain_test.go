@@ -0,0 +1,153 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/log"
+	"github.com/jmoiron/sqlx"
+	assert1 "github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+const testPlan = `{
+  "query_block": {
+    "select_id": 1,
+    "ordering_operation": {
+      "using_filesort": true,
+      "grouping_operation": {
+        "using_temporary_table": true,
+        "nested_loop": [
+          {"table": {"table_name": "a", "access_type": "ALL", "possible_keys": ["a_name", "a_created"]}},
+          {"table": {"table_name": "b", "access_type": "ref", "possible_keys": ["PRIMARY"], "key": "PRIMARY"}},
+          {"table": {"table_name": "c", "access_type": "ALL"}}
+        ]
+      }
+    }
+  }
+}`
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		statement string
+		expected  string
+	}{
+		{
+			statement: "SELECT `a`.* FROM `a` WHERE `a`.`id` = ?",
+			expected:  "SELECT `a`.* FROM `a` WHERE `a`.`id` = ?",
+		},
+		{
+			statement: "SELECT * FROM a\n\tWHERE id IN (?, ?,?) AND name = 'it''s' LIMIT 10",
+			expected:  "SELECT * FROM a WHERE id IN (?) AND name = ? LIMIT ?",
+		},
+		{
+			statement: "INSERT INTO `a` (`id`, `name`) VALUES (:id, :name)",
+			expected:  "INSERT INTO `a` (`id`, `name`) VALUES (?)",
+		},
+		{
+			statement: "INSERT INTO a1 (id) VALUES (?), (?), (?)",
+			expected:  "INSERT INTO a1 (id) VALUES (?)",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.statement, func(t *testing.T) {
+			assert1.Equal(t, tc.expected, NormalizeQuery(tc.statement))
+		})
+	}
+}
+
+func TestParsePlan(t *testing.T) {
+	assert := assert1.New(t)
+	plan, err := ParsePlan([]byte(testPlan))
+	assert.NoError(err)
+	assert.Equal([]string{"a", "c"}, plan.FullScans)
+	assert.True(plan.Filesort)
+	assert.True(plan.TemporaryTable)
+	assert.Equal(map[string][]string{"a": {"a_name", "a_created"}}, plan.UnusedIndexes)
+
+	plan, err = ParsePlan([]byte(`{"query_block": {"table": {"table_name": "a", "access_type": "const"}}}`))
+	assert.NoError(err)
+	assert.Equal("no problems found", plan.String())
+	plan.FullScans = []string{"a"}
+	plan.Filesort = true
+	plan.UnusedIndexes["a"] = []string{"a_name"}
+	assert.Equal("full scan of a; filesort; unused indexes on a (a_name)", plan.String())
+
+	_, err = ParsePlan([]byte("not json"))
+	assert.Error(err)
+}
+
+func newExplainer(t *testing.T, connector *streamConnector) *Explainer {
+	db := sqlx.NewDb(sql.OpenDB(connector), qb.MySQLDriver)
+	t.Cleanup(func() { _ = db.Close() })
+	return NewExplainer(db, log.Global())
+}
+
+func explain(explainer *Explainer, query Query) string {
+	summary := make(chan string, 1)
+	explainer.Explain(query, func(s string) { summary <- s })
+	select {
+	case s := <-summary:
+		return s
+	case <-time.After(5 * time.Second):
+		return "timed out"
+	}
+}
+
+func TestExplainer(t *testing.T) {
+	assert := assert1.New(t)
+	connector := &streamConnector{columns: []string{"EXPLAIN"}, values: [][]driver.Value{{testPlan}}}
+	explainer := newExplainer(t, connector)
+
+	expected := "full scan of a, c; filesort; temporary table; unused indexes on a (a_name, a_created)"
+	actual := explain(explainer, Query{Statement: "SELECT * FROM a WHERE id IN (?, ?)", Args: []any{1, 2}})
+	assert.Equal(expected, actual)
+	assert.Equal([]string{"EXPLAIN FORMAT=JSON SELECT * FROM a WHERE id IN (?, ?)"}, connector.queries)
+
+	// the plan is cached by normalized query
+	assert.Equal(actual, explain(explainer, Query{Statement: "SELECT * FROM a WHERE id IN (?)", Args: []any{1}}))
+	assert.Len(connector.queries, 1)
+
+	// named statements are bound before they are explained
+	explain(explainer, Query{Statement: "UPDATE a SET name = :name", Args: []any{map[string]any{"name": "b"}},
+		Named: true})
+	assert.Equal("EXPLAIN FORMAT=JSON UPDATE a SET name = ?", connector.queries[1])
+
+	// statements that can not be explained are not executed
+	assert.Equal("", explain(explainer, Query{Statement: "SAVEPOINT sp_1"}))
+	assert.Len(connector.queries, 2)
+
+	// failures are not cached
+	connector.values = nil
+	connector.err = errors.New("connection lost")
+	assert.Equal("", explain(explainer, Query{Statement: "DELETE FROM a"}))
+	assert.Equal("", explain(explainer, Query{Statement: "DELETE FROM a"}))
+	assert.Len(connector.queries, 4)
+}
+
+func TestSlowQueryLogger_WithExplainer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	logger := log.NewMockLogger(ctrl)
+	connector := &streamConnector{columns: []string{"EXPLAIN"}, values: [][]driver.Value{{testPlan}}}
+	slow := NewSlowQueryLogger(time.Millisecond, logger, nil).WithExplainer(newExplainer(t, connector))
+
+	logged := make(chan string, 1)
+	logger.EXPECT().Error(gomock.Any()).DoAndReturn(func(err error) error {
+		logged <- err.Error()
+		return nil
+	})
+	slow.After(context.Background(), Query{TransactionID: "TX1", Statement: "SELECT * FROM c"},
+		QueryResult{Duration: time.Second})
+	select {
+	case message := <-logged:
+		assert1.Regexp(t, `^\[TX1\] query execution time: 1s query: SELECT \* FROM c plan: full scan of .+; filesort`,
+			message)
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow query was not logged")
+	}
+}
@@ -2,6 +2,7 @@
 package transaction
 
 import (
+	context "context"
 	sql "database/sql"
 
 	sqlx "github.com/jmoiron/sqlx"
@@ -17,6 +18,9 @@ type Implementation interface {
 	// QueryRowx within a transaction.
 	// Any placeholder parameters are replaced with supplied args.
 	QueryRowx(query string, args ...interface{}) *sqlx.Row
+	// Queryx within a transaction, the returned rows must be closed.
+	// Any placeholder parameters are replaced with supplied args.
+	Queryx(query string, args ...interface{}) (*sqlx.Rows, error)
 	// PrepareNamed returns a sqlx.NamedStatement that can be used
 	// to execute the prepared statement using named parameters
 	PrepareNamed(query string) (*sqlx.NamedStmt, error)
@@ -30,6 +34,28 @@ type Implementation interface {
 	// Exec a query within a transaction.
 	// Any named placeholder parameters are replaced with fields from arg.
 	Exec(query string, args ...any) (sql.Result, error)
+	// NamedExecContext a named query within a transaction.
+	// Any named placeholder parameters are replaced with fields from arg.
+	NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
+	// QueryRowxContext within a transaction.
+	// Any placeholder parameters are replaced with supplied args.
+	QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row
+	// QueryxContext within a transaction, the returned rows must be closed.
+	// Any placeholder parameters are replaced with supplied args.
+	QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error)
+	// PrepareNamedContext returns a sqlx.NamedStatement that can be used
+	// to execute the prepared statement using named parameters
+	PrepareNamedContext(ctx context.Context, query string) (*sqlx.NamedStmt, error)
+	// PreparexContext returns a sqlx.Stmt that can be used to avoid
+	// the overhead of preparing the same statement when executing
+	// many times
+	PreparexContext(ctx context.Context, query string) (*sqlx.Stmt, error)
+	// SelectContext within a transaction.
+	// Any placeholder parameters are replaced with supplied args.
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	// ExecContext a query within a transaction.
+	// Any placeholder parameters are replaced with supplied args.
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 	// Commit this transaction
 	Commit() error
 	// Rollback this transaction
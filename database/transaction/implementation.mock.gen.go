@@ -10,6 +10,7 @@
 package transaction
 
 import (
+	context "context"
 	sql "database/sql"
 	reflect "reflect"
 
@@ -75,6 +76,26 @@ func (mr *MockImplementationMockRecorder) Exec(query any, args ...any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exec", reflect.TypeOf((*MockImplementation)(nil).Exec), varargs...)
 }
 
+// ExecContext mocks base method.
+func (m *MockImplementation) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExecContext", varargs...)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecContext indicates an expected call of ExecContext.
+func (mr *MockImplementationMockRecorder) ExecContext(ctx, query any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecContext", reflect.TypeOf((*MockImplementation)(nil).ExecContext), varargs...)
+}
+
 // NamedExec mocks base method.
 func (m *MockImplementation) NamedExec(query string, arg any) (sql.Result, error) {
 	m.ctrl.T.Helper()
@@ -90,6 +111,21 @@ func (mr *MockImplementationMockRecorder) NamedExec(query, arg any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NamedExec", reflect.TypeOf((*MockImplementation)(nil).NamedExec), query, arg)
 }
 
+// NamedExecContext mocks base method.
+func (m *MockImplementation) NamedExecContext(ctx context.Context, query string, arg any) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NamedExecContext", ctx, query, arg)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NamedExecContext indicates an expected call of NamedExecContext.
+func (mr *MockImplementationMockRecorder) NamedExecContext(ctx, query, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NamedExecContext", reflect.TypeOf((*MockImplementation)(nil).NamedExecContext), ctx, query, arg)
+}
+
 // NamedQuery mocks base method.
 func (m *MockImplementation) NamedQuery(query string, arg any) (*sqlx.Rows, error) {
 	m.ctrl.T.Helper()
@@ -120,6 +156,21 @@ func (mr *MockImplementationMockRecorder) PrepareNamed(query any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrepareNamed", reflect.TypeOf((*MockImplementation)(nil).PrepareNamed), query)
 }
 
+// PrepareNamedContext mocks base method.
+func (m *MockImplementation) PrepareNamedContext(ctx context.Context, query string) (*sqlx.NamedStmt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrepareNamedContext", ctx, query)
+	ret0, _ := ret[0].(*sqlx.NamedStmt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PrepareNamedContext indicates an expected call of PrepareNamedContext.
+func (mr *MockImplementationMockRecorder) PrepareNamedContext(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrepareNamedContext", reflect.TypeOf((*MockImplementation)(nil).PrepareNamedContext), ctx, query)
+}
+
 // Preparex mocks base method.
 func (m *MockImplementation) Preparex(query string) (*sqlx.Stmt, error) {
 	m.ctrl.T.Helper()
@@ -135,6 +186,21 @@ func (mr *MockImplementationMockRecorder) Preparex(query any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preparex", reflect.TypeOf((*MockImplementation)(nil).Preparex), query)
 }
 
+// PreparexContext mocks base method.
+func (m *MockImplementation) PreparexContext(ctx context.Context, query string) (*sqlx.Stmt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreparexContext", ctx, query)
+	ret0, _ := ret[0].(*sqlx.Stmt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreparexContext indicates an expected call of PreparexContext.
+func (mr *MockImplementationMockRecorder) PreparexContext(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreparexContext", reflect.TypeOf((*MockImplementation)(nil).PreparexContext), ctx, query)
+}
+
 // QueryRowx mocks base method.
 func (m *MockImplementation) QueryRowx(query string, args ...any) *sqlx.Row {
 	m.ctrl.T.Helper()
@@ -154,6 +220,65 @@ func (mr *MockImplementationMockRecorder) QueryRowx(query any, args ...any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryRowx", reflect.TypeOf((*MockImplementation)(nil).QueryRowx), varargs...)
 }
 
+// QueryRowxContext mocks base method.
+func (m *MockImplementation) QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "QueryRowxContext", varargs...)
+	ret0, _ := ret[0].(*sqlx.Row)
+	return ret0
+}
+
+// QueryRowxContext indicates an expected call of QueryRowxContext.
+func (mr *MockImplementationMockRecorder) QueryRowxContext(ctx, query any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryRowxContext", reflect.TypeOf((*MockImplementation)(nil).QueryRowxContext), varargs...)
+}
+
+// Queryx mocks base method.
+func (m *MockImplementation) Queryx(query string, args ...any) (*sqlx.Rows, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Queryx", varargs...)
+	ret0, _ := ret[0].(*sqlx.Rows)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Queryx indicates an expected call of Queryx.
+func (mr *MockImplementationMockRecorder) Queryx(query any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Queryx", reflect.TypeOf((*MockImplementation)(nil).Queryx), varargs...)
+}
+
+// QueryxContext mocks base method.
+func (m *MockImplementation) QueryxContext(ctx context.Context, query string, args ...any) (*sqlx.Rows, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "QueryxContext", varargs...)
+	ret0, _ := ret[0].(*sqlx.Rows)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryxContext indicates an expected call of QueryxContext.
+func (mr *MockImplementationMockRecorder) QueryxContext(ctx, query any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryxContext", reflect.TypeOf((*MockImplementation)(nil).QueryxContext), varargs...)
+}
+
 // Rollback mocks base method.
 func (m *MockImplementation) Rollback() error {
 	m.ctrl.T.Helper()
@@ -186,3 +311,22 @@ func (mr *MockImplementationMockRecorder) Select(dest, query any, args ...any) *
 	varargs := append([]any{dest, query}, args...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Select", reflect.TypeOf((*MockImplementation)(nil).Select), varargs...)
 }
+
+// SelectContext mocks base method.
+func (m *MockImplementation) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, dest, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SelectContext", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SelectContext indicates an expected call of SelectContext.
+func (mr *MockImplementationMockRecorder) SelectContext(ctx, dest, query any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, dest, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectContext", reflect.TypeOf((*MockImplementation)(nil).SelectContext), varargs...)
+}
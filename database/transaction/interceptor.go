@@ -0,0 +1,201 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Query executed by a transaction
+type Query struct {
+	// TransactionID of the transaction executing the query
+	TransactionID string
+	// Statement that is executed
+	Statement string
+	// Args passed with the statement
+	Args []any
+	// Named is true when the statement has named parameters, which are read
+	// from the single argument
+	Named bool
+}
+
+// QueryResult of executing a Query
+type QueryResult struct {
+	// Duration of the execution
+	Duration time.Duration
+	// RowsAffected by the statement, -1 when the statement does not report it
+	RowsAffected int64
+	// Err returned by the driver
+	Err error
+}
+
+// QueryInterceptor is called around every query executed by a transaction
+type QueryInterceptor interface {
+	// Before the query is executed, the returned context is passed to the driver
+	// and to After
+	Before(ctx context.Context, query Query) context.Context
+	// After the query is executed
+	After(ctx context.Context, query Query, result QueryResult)
+}
+
+// interceptedTx calls each of the interceptors around each query executed by
+// the implementation, Before is called in order and After in reverse order
+type interceptedTx struct {
+	implementation Implementation
+	id             string
+	interceptors   []QueryInterceptor
+}
+
+// intercept the execution of statement, the returned function must be called
+// with the result once it has executed
+func (tx *interceptedTx) intercept(ctx context.Context, query Query) (context.Context, func(int64, error)) {
+	query.TransactionID = tx.id
+	for _, interceptor := range tx.interceptors {
+		ctx = interceptor.Before(ctx, query)
+	}
+	start := time.Now()
+	return ctx, func(rowsAffected int64, err error) {
+		result := QueryResult{Duration: time.Since(start), RowsAffected: rowsAffected, Err: err}
+		for i := len(tx.interceptors) - 1; i >= 0; i-- {
+			tx.interceptors[i].After(ctx, query, result)
+		}
+	}
+}
+
+// rowsAffected by result or -1 if it is not available
+func rowsAffected(result sql.Result, err error) int64 {
+	if nil != err || nil == result {
+		return -1
+	}
+	affected, err := result.RowsAffected()
+	if nil != err {
+		return -1
+	}
+	return affected
+}
+
+func rowErr(row *sqlx.Row) error {
+	if nil == row {
+		return nil
+	}
+	return row.Err()
+}
+
+func (tx *interceptedTx) NamedQuery(query string, arg interface{}) (*sqlx.Rows, error) {
+	_, done := tx.intercept(context.Background(), Query{Statement: query, Args: []any{arg}, Named: true})
+	rows, err := tx.implementation.NamedQuery(query, arg)
+	done(-1, err)
+	return rows, err
+}
+
+func (tx *interceptedTx) Exec(query string, args ...any) (sql.Result, error) {
+	_, done := tx.intercept(context.Background(), Query{Statement: query, Args: args})
+	result, err := tx.implementation.Exec(query, args...)
+	done(rowsAffected(result, err), err)
+	return result, err
+}
+
+func (tx *interceptedTx) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	_, done := tx.intercept(context.Background(), Query{Statement: query, Args: []any{arg}, Named: true})
+	result, err := tx.implementation.NamedExec(query, arg)
+	done(rowsAffected(result, err), err)
+	return result, err
+}
+
+func (tx *interceptedTx) Preparex(query string) (*sqlx.Stmt, error) {
+	_, done := tx.intercept(context.Background(), Query{Statement: query})
+	stmt, err := tx.implementation.Preparex(query)
+	done(-1, err)
+	return stmt, err
+}
+
+func (tx *interceptedTx) PrepareNamed(query string) (*sqlx.NamedStmt, error) {
+	_, done := tx.intercept(context.Background(), Query{Statement: query})
+	stmt, err := tx.implementation.PrepareNamed(query)
+	done(-1, err)
+	return stmt, err
+}
+
+func (tx *interceptedTx) QueryRowx(query string, args ...interface{}) *sqlx.Row {
+	_, done := tx.intercept(context.Background(), Query{Statement: query, Args: args})
+	row := tx.implementation.QueryRowx(query, args...)
+	done(-1, rowErr(row))
+	return row
+}
+
+func (tx *interceptedTx) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	_, done := tx.intercept(context.Background(), Query{Statement: query, Args: args})
+	rows, err := tx.implementation.Queryx(query, args...)
+	done(-1, err)
+	return rows, err
+}
+
+func (tx *interceptedTx) Select(dest interface{}, query string, args ...interface{}) error {
+	_, done := tx.intercept(context.Background(), Query{Statement: query, Args: args})
+	err := tx.implementation.Select(dest, query, args...)
+	done(-1, err)
+	return err
+}
+
+func (tx *interceptedTx) NamedExecContext(ctx context.Context, query string,
+	arg interface{}) (sql.Result, error) {
+	ctx, done := tx.intercept(ctx, Query{Statement: query, Args: []any{arg}, Named: true})
+	result, err := tx.implementation.NamedExecContext(ctx, query, arg)
+	done(rowsAffected(result, err), err)
+	return result, err
+}
+
+func (tx *interceptedTx) QueryRowxContext(ctx context.Context, query string,
+	args ...interface{}) *sqlx.Row {
+	ctx, done := tx.intercept(ctx, Query{Statement: query, Args: args})
+	row := tx.implementation.QueryRowxContext(ctx, query, args...)
+	done(-1, rowErr(row))
+	return row
+}
+
+func (tx *interceptedTx) QueryxContext(ctx context.Context, query string,
+	args ...interface{}) (*sqlx.Rows, error) {
+	ctx, done := tx.intercept(ctx, Query{Statement: query, Args: args})
+	rows, err := tx.implementation.QueryxContext(ctx, query, args...)
+	done(-1, err)
+	return rows, err
+}
+
+func (tx *interceptedTx) PrepareNamedContext(ctx context.Context, query string) (*sqlx.NamedStmt, error) {
+	ctx, done := tx.intercept(ctx, Query{Statement: query})
+	stmt, err := tx.implementation.PrepareNamedContext(ctx, query)
+	done(-1, err)
+	return stmt, err
+}
+
+func (tx *interceptedTx) PreparexContext(ctx context.Context, query string) (*sqlx.Stmt, error) {
+	ctx, done := tx.intercept(ctx, Query{Statement: query})
+	stmt, err := tx.implementation.PreparexContext(ctx, query)
+	done(-1, err)
+	return stmt, err
+}
+
+func (tx *interceptedTx) SelectContext(ctx context.Context, dest interface{}, query string,
+	args ...interface{}) error {
+	ctx, done := tx.intercept(ctx, Query{Statement: query, Args: args})
+	err := tx.implementation.SelectContext(ctx, dest, query, args...)
+	done(-1, err)
+	return err
+}
+
+func (tx *interceptedTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, done := tx.intercept(ctx, Query{Statement: query, Args: args})
+	result, err := tx.implementation.ExecContext(ctx, query, args...)
+	done(rowsAffected(result, err), err)
+	return result, err
+}
+
+func (tx *interceptedTx) Commit() error {
+	return tx.implementation.Commit()
+}
+
+func (tx *interceptedTx) Rollback() error {
+	return tx.implementation.Rollback()
+}
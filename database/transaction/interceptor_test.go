@@ -0,0 +1,175 @@
+package transaction
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/log"
+	assert1 "github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type contextKey string
+
+// orderInterceptor appends its name to calls before and after each query
+type orderInterceptor struct {
+	name    string
+	calls   *[]string
+	results []QueryResult
+}
+
+func (i *orderInterceptor) Before(ctx context.Context, _ Query) context.Context {
+	*i.calls = append(*i.calls, "before "+i.name)
+	return context.WithValue(ctx, contextKey(i.name), true)
+}
+
+func (i *orderInterceptor) After(_ context.Context, _ Query, result QueryResult) {
+	*i.calls = append(*i.calls, "after "+i.name)
+	i.results = append(i.results, result)
+}
+
+func TestInterceptedTx(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	implementation := NewMockImplementation(ctrl)
+	var calls []string
+	first := &orderInterceptor{name: "first", calls: &calls}
+	second := &orderInterceptor{name: "second", calls: &calls}
+	recorder := &QueryRecorder{}
+	tx := &interceptedTx{implementation: implementation, id: "TX1",
+		interceptors: []QueryInterceptor{first, second, recorder}}
+
+	implementation.EXPECT().ExecContext(gomock.Any(), "UPDATE", "a").DoAndReturn(
+		func(ctx context.Context, _ string, _ ...any) (driver.Result, error) {
+			// the context returned by each Before is passed to the driver
+			assert.Equal(true, ctx.Value(contextKey("first")))
+			assert.Equal(true, ctx.Value(contextKey("second")))
+			return driver.RowsAffected(3), nil
+		})
+	_, err := tx.ExecContext(context.Background(), "UPDATE", "a")
+	assert.NoError(err)
+	assert.Equal([]string{"before first", "before second", "after second", "after first"}, calls)
+	assert.Equal(int64(3), first.results[0].RowsAffected)
+	assert.NoError(first.results[0].Err)
+
+	expected := errors.New("connection lost")
+	implementation.EXPECT().SelectContext(gomock.Any(), nil, "SELECT", "b").Return(expected)
+	assert.Equal(expected, tx.SelectContext(context.Background(), nil, "SELECT", "b"))
+	assert.Equal(int64(-1), second.results[1].RowsAffected)
+	assert.Equal(expected, second.results[1].Err)
+
+	assert.Equal([]Query{{TransactionID: "TX1", Statement: "UPDATE", Args: []any{"a"}},
+		{TransactionID: "TX1", Statement: "SELECT", Args: []any{"b"}}}, recorder.Queries())
+	assert.Equal([]string{"UPDATE", "SELECT"}, recorder.Statements())
+	recorder.Reset()
+	assert.Empty(recorder.Statements())
+}
+
+func TestSlowQueryLogger(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	logger := log.NewMockLogger(ctrl)
+	loggedQueries := make(map[string]time.Duration)
+	slow := NewSlowQueryLogger(time.Second, logger, loggedQueries)
+	ctx := context.Background()
+	query := Query{TransactionID: "TX1", Statement: "SELECT"}
+
+	slow.After(ctx, query, QueryResult{Duration: time.Second})
+	logger.EXPECT().Error(gomock.Any()).Return(nil)
+	slow.After(ctx, query, QueryResult{Duration: 2 * time.Second})
+	// only logged again when slower than the last time
+	slow.After(ctx, query, QueryResult{Duration: 2 * time.Second})
+	assert.Equal(map[string]time.Duration{"SELECT": 2 * time.Second}, loggedQueries)
+}
+
+// begin returns the same implementation for every transaction
+type begin struct {
+	implementation Implementation
+}
+
+func (b begin) Begin() (Implementation, error) {
+	return b.implementation, nil
+}
+
+func (b begin) BeginContext(context.Context) (Implementation, error) {
+	return b.implementation, nil
+}
+
+func TestSlowQueryLogger_Shared(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	logger := log.NewMockLogger(ctrl)
+	logger.EXPECT().Error(gomock.Any()).Return(nil).AnyTimes()
+	implementation := NewMockImplementation(ctrl)
+	implementation.EXPECT().ExecContext(gomock.Any(), gomock.Any()).
+		Return(driver.RowsAffected(1), nil).AnyTimes()
+	loggedQueries := make(map[string]time.Duration)
+
+	// every query is slow so both transactions update the shared logged
+	// queries concurrently, run with -race
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		tx, err := New(begin{implementation: implementation}, logger, 0, loggedQueries)
+		assert.NoError(err)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, _ = tx.Implementation().ExecContext(context.Background(), fmt.Sprintf("UPDATE %d", j%10))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(loggedQueries, 10)
+}
+
+func TestQueryCounter(t *testing.T) {
+	assert := assert1.New(t)
+	ctrl := gomock.NewController(t)
+	logger := log.NewMockLogger(ctrl)
+	counter := NewQueryCounter(2, logger)
+	query := Query{Statement: "SELECT"}
+
+	// queries are not counted without a counting context
+	counter.After(context.Background(), query, QueryResult{})
+	assert.Equal(0, QueryCount(context.Background()))
+
+	ctx := WithQueryCount(context.Background())
+	counter.After(ctx, query, QueryResult{})
+	counter.After(ctx, Query{Statement: "UPDATE"}, QueryResult{})
+	counter.After(ctx, query, QueryResult{})
+	logger.EXPECT().Warnf(gomock.Any(), 2, "SELECT").Return("")
+	counter.After(ctx, query, QueryResult{})
+	counter.After(ctx, query, QueryResult{})
+	assert.Equal(5, QueryCount(ctx))
+}
+
+func TestLatencyHistogram(t *testing.T) {
+	assert := assert1.New(t)
+	histogram := NewLatencyHistogram(time.Millisecond, time.Second)
+	ctx := context.Background()
+	for _, duration := range []time.Duration{time.Microsecond, time.Millisecond, time.Second,
+		time.Minute} {
+		histogram.After(ctx, Query{Statement: "SELECT"}, QueryResult{Duration: duration})
+	}
+	histogram.After(ctx, Query{Statement: "UPDATE"}, QueryResult{Duration: time.Millisecond})
+
+	snapshot := histogram.Snapshot()
+	assert.Equal(Histogram{
+		Buckets: []time.Duration{time.Millisecond, time.Second},
+		Counts:  []uint64{2, 1, 1},
+		Count:   4,
+		Sum:     time.Microsecond + time.Millisecond + time.Second + time.Minute,
+	}, snapshot["SELECT"])
+	assert.Equal([]uint64{1, 0, 0}, snapshot["UPDATE"].Counts)
+
+	// snapshots are not modified by later queries
+	histogram.After(ctx, Query{Statement: "UPDATE"}, QueryResult{Duration: time.Millisecond})
+	assert.Equal([]uint64{1, 0, 0}, snapshot["UPDATE"].Counts)
+	assert.Equal(DefaultLatencyBuckets, NewLatencyHistogram().buckets)
+}
@@ -0,0 +1,194 @@
+package transaction
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/log"
+)
+
+// QueryCounter is a QueryInterceptor that counts the queries executed under a
+// context returned from WithQueryCount, such as the context of a request, and
+// warns when the same statement is executed more than a threshold number of
+// times as that usually indicates an N+1 query
+type QueryCounter struct {
+	threshold int
+	log       log.Logger
+}
+
+// NewQueryCounter that warns on logger when a statement is executed more than
+// threshold times under the same counting context
+func NewQueryCounter(threshold int, logger log.Logger) *QueryCounter {
+	return &QueryCounter{threshold: threshold, log: logger}
+}
+
+type queryCountKey struct{}
+
+type queryCount struct {
+	mutex      sync.Mutex
+	total      int
+	statements map[string]int
+}
+
+// WithQueryCount returns a context under which the queries executed are counted
+// by a QueryCounter
+func WithQueryCount(ctx context.Context) context.Context {
+	return context.WithValue(ctx, queryCountKey{}, &queryCount{statements: make(map[string]int)})
+}
+
+// QueryCount executed under the passed context, 0 if it was not created with
+// WithQueryCount
+func QueryCount(ctx context.Context) int {
+	count, ok := ctx.Value(queryCountKey{}).(*queryCount)
+	if !ok {
+		return 0
+	}
+	count.mutex.Lock()
+	defer count.mutex.Unlock()
+	return count.total
+}
+
+// Before does nothing
+func (c *QueryCounter) Before(ctx context.Context, _ Query) context.Context {
+	return ctx
+}
+
+// After counts the query and warns the first time the statement exceeds the
+// threshold
+func (c *QueryCounter) After(ctx context.Context, query Query, _ QueryResult) {
+	count, ok := ctx.Value(queryCountKey{}).(*queryCount)
+	if !ok {
+		return
+	}
+	count.mutex.Lock()
+	defer count.mutex.Unlock()
+	count.total++
+	count.statements[query.Statement]++
+	if count.statements[query.Statement] == c.threshold+1 {
+		c.log.Warnf("statement executed more than %d times, possible N+1 query: %s",
+			c.threshold, query.Statement)
+	}
+}
+
+// DefaultLatencyBuckets are the upper bounds of the buckets of a LatencyHistogram
+var DefaultLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// Histogram of the execution durations of a statement
+type Histogram struct {
+	// Buckets are the inclusive upper bounds of each count
+	Buckets []time.Duration
+	// Counts of the executions in each bucket, the last count is of executions
+	// slower than every bucket
+	Counts []uint64
+	// Count of executions
+	Count uint64
+	// Sum of the execution durations
+	Sum time.Duration
+}
+
+// LatencyHistogram is a QueryInterceptor that keeps a histogram of execution
+// durations for each statement
+type LatencyHistogram struct {
+	buckets    []time.Duration
+	mutex      sync.Mutex
+	histograms map[string]*Histogram
+}
+
+// NewLatencyHistogram with the passed bucket upper bounds in ascending order,
+// DefaultLatencyBuckets are used if none are passed
+func NewLatencyHistogram(buckets ...time.Duration) *LatencyHistogram {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	return &LatencyHistogram{buckets: buckets, histograms: make(map[string]*Histogram)}
+}
+
+// Before does nothing
+func (h *LatencyHistogram) Before(ctx context.Context, _ Query) context.Context {
+	return ctx
+}
+
+// After records the duration of the query
+func (h *LatencyHistogram) After(_ context.Context, query Query, result QueryResult) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	histogram, ok := h.histograms[query.Statement]
+	if !ok {
+		histogram = &Histogram{Buckets: h.buckets, Counts: make([]uint64, len(h.buckets)+1)}
+		h.histograms[query.Statement] = histogram
+	}
+	bucket, _ := slices.BinarySearch(h.buckets, result.Duration)
+	histogram.Counts[bucket]++
+	histogram.Count++
+	histogram.Sum += result.Duration
+}
+
+// Snapshot of the histogram of each statement
+func (h *LatencyHistogram) Snapshot() map[string]Histogram {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	snapshot := make(map[string]Histogram, len(h.histograms))
+	for statement, histogram := range h.histograms {
+		copied := *histogram
+		copied.Counts = slices.Clone(histogram.Counts)
+		snapshot[statement] = copied
+	}
+	return snapshot
+}
+
+// QueryRecorder is a QueryInterceptor that records the queries executed so
+// that tests can make assertions on them
+type QueryRecorder struct {
+	mutex   sync.Mutex
+	queries []Query
+}
+
+// Before does nothing
+func (r *QueryRecorder) Before(ctx context.Context, _ Query) context.Context {
+	return ctx
+}
+
+// After records the query
+func (r *QueryRecorder) After(_ context.Context, query Query, _ QueryResult) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.queries = append(r.queries, query)
+}
+
+// Queries executed since the recorder was created or Reset
+func (r *QueryRecorder) Queries() []Query {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return slices.Clone(r.queries)
+}
+
+// Statements executed since the recorder was created or Reset
+func (r *QueryRecorder) Statements() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	statements := make([]string, len(r.queries))
+	for i, query := range r.queries {
+		statements[i] = query.Statement
+	}
+	return statements
+}
+
+// Reset the recorded queries
+func (r *QueryRecorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.queries = nil
+}
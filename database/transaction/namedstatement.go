@@ -1,7 +1,10 @@
 //go:generate mockgen -source=$GOFILE -package $GOPACKAGE -destination namedstatement.mock.gen.go
 package transaction
 
-import sql "database/sql"
+import (
+	context "context"
+	sql "database/sql"
+)
 
 // NamedStatement is a prepared statement that executes named queries. Prepare it
 // how you would execute a NamedQuery, but pass in a struct or map when executing.
@@ -15,4 +18,10 @@ type NamedStatement interface {
 	// Get using this NamedStmt
 	// Any named placeholder parameters are replaced with fields from arg.
 	Get(dest interface{}, arg interface{}) error
+	// ExecContext executes a named statement using the struct passed.
+	// Any named placeholder parameters are replaced with fields from arg.
+	ExecContext(ctx context.Context, arg interface{}) (sql.Result, error)
+	// GetContext using this NamedStmt
+	// Any named placeholder parameters are replaced with fields from arg.
+	GetContext(ctx context.Context, dest interface{}, arg interface{}) error
 }
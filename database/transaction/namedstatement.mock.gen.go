@@ -10,6 +10,7 @@
 package transaction
 
 import (
+	context "context"
 	sql "database/sql"
 	reflect "reflect"
 
@@ -69,6 +70,21 @@ func (mr *MockNamedStatementMockRecorder) Exec(arg any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exec", reflect.TypeOf((*MockNamedStatement)(nil).Exec), arg)
 }
 
+// ExecContext mocks base method.
+func (m *MockNamedStatement) ExecContext(ctx context.Context, arg any) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecContext", ctx, arg)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecContext indicates an expected call of ExecContext.
+func (mr *MockNamedStatementMockRecorder) ExecContext(ctx, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecContext", reflect.TypeOf((*MockNamedStatement)(nil).ExecContext), ctx, arg)
+}
+
 // Get mocks base method.
 func (m *MockNamedStatement) Get(dest, arg any) error {
 	m.ctrl.T.Helper()
@@ -82,3 +98,17 @@ func (mr *MockNamedStatementMockRecorder) Get(dest, arg any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockNamedStatement)(nil).Get), dest, arg)
 }
+
+// GetContext mocks base method.
+func (m *MockNamedStatement) GetContext(ctx context.Context, dest, arg any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContext", ctx, dest, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetContext indicates an expected call of GetContext.
+func (mr *MockNamedStatementMockRecorder) GetContext(ctx, dest, arg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContext", reflect.TypeOf((*MockNamedStatement)(nil).GetContext), ctx, dest, arg)
+}
@@ -1,87 +1,84 @@
 package transaction
 
 import (
-	"database/sql"
+	"context"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/beaconsoftwarellc/gadget/v2/errors"
 	"github.com/beaconsoftwarellc/gadget/v2/log"
-
-	"time"
-
-	"github.com/jmoiron/sqlx"
 )
 
-type slowQueryLoggerTx struct {
-	implementation Implementation
-	slow           time.Duration
-	log            log.Logger
-	id             string
-	loggedQueries  map[string]time.Duration
-}
-
-func (tx *slowQueryLoggerTx) NamedQuery(query string, arg interface{}) (*sqlx.Rows, error) {
-	start := time.Now()
-	defer func() { tx.logSlow(query, time.Since(start)) }()
-	return tx.implementation.NamedQuery(query, arg)
-}
-
-func (tx *slowQueryLoggerTx) Exec(query string, args ...any) (sql.Result, error) {
-	start := time.Now()
-	defer func() { tx.logSlow(query, time.Since(start)) }()
-	return tx.implementation.Exec(query, args...)
-}
-
-func (tx *slowQueryLoggerTx) NamedExec(query string, arg interface{}) (sql.Result, error) {
-	start := time.Now()
-	defer func() { tx.logSlow(query, time.Since(start)) }()
-	return tx.implementation.NamedExec(query, arg)
-}
-
-func (tx *slowQueryLoggerTx) Preparex(query string) (*sqlx.Stmt, error) {
-	start := time.Now()
-	defer func() { tx.logSlow(query, time.Since(start)) }()
-	return tx.implementation.Preparex(query)
-}
-
-func (tx *slowQueryLoggerTx) PrepareNamed(query string) (*sqlx.NamedStmt, error) {
-	start := time.Now()
-	defer func() { tx.logSlow(query, time.Since(start)) }()
-	return tx.implementation.PrepareNamed(query)
+// loggedQueriesMutex guards the logged queries of every SlowQueryLogger, the
+// map they are recorded in may be shared between transactions
+var loggedQueriesMutex sync.Mutex
+
+// slower records the passed duration for the statement and returns true when it
+// is slower than the last time the statement was logged
+func slower(loggedQueries map[string]time.Duration, statement string, duration time.Duration) bool {
+	loggedQueriesMutex.Lock()
+	defer loggedQueriesMutex.Unlock()
+	if logged, ok := loggedQueries[statement]; ok && logged >= duration {
+		return false
+	}
+	loggedQueries[statement] = duration
+	return true
 }
 
-func (tx *slowQueryLoggerTx) QueryRowx(query string, args ...interface{}) *sqlx.Row {
-	start := time.Now()
-	defer func() { tx.logSlow(query, time.Since(start)) }()
-	return tx.implementation.QueryRowx(query, args...)
+// SlowQueryLogger is a QueryInterceptor that logs queries that take longer than
+// a threshold to execute
+type SlowQueryLogger struct {
+	slow          time.Duration
+	log           log.Logger
+	loggedQueries map[string]time.Duration
+	explainer     *Explainer
 }
 
-func (tx *slowQueryLoggerTx) Select(dest interface{}, query string, args ...interface{}) error {
-	start := time.Now()
-	defer func() { tx.logSlow(query, time.Since(start)) }()
-	return tx.implementation.Select(dest, query, args...)
+// NewSlowQueryLogger that logs queries slower than the passed duration to
+// logger. A query is only logged again when it is slower than the last time it
+// was logged, loggedQueries holds these times and may be shared between
+// transactions.
+func NewSlowQueryLogger(slow time.Duration, logger log.Logger,
+	loggedQueries map[string]time.Duration) *SlowQueryLogger {
+	if nil == loggedQueries {
+		loggedQueries = make(map[string]time.Duration)
+	}
+	return &SlowQueryLogger{slow: slow, log: logger, loggedQueries: loggedQueries}
 }
 
-func (tx *slowQueryLoggerTx) Commit() error {
-	return tx.implementation.Commit()
+// WithExplainer that explains each slow query, the summary of the plan is
+// logged with the query
+func (l *SlowQueryLogger) WithExplainer(explainer *Explainer) *SlowQueryLogger {
+	l.explainer = explainer
+	return l
 }
 
-func (tx *slowQueryLoggerTx) Rollback() error {
-	return tx.implementation.Rollback()
+// Before does nothing
+func (l *SlowQueryLogger) Before(ctx context.Context, _ Query) context.Context {
+	return ctx
 }
 
-func (tx *slowQueryLoggerTx) logSlow(query string, elapsed time.Duration) {
-	if elapsed <= tx.slow {
+// After logs the query if it was slow
+func (l *SlowQueryLogger) After(_ context.Context, query Query, result QueryResult) {
+	if result.Duration <= l.slow {
 		return
 	}
-
 	// do not log the slow query if it has already been logged with a slower time
-	logged, ok := tx.loggedQueries[query]
-	if ok && logged >= elapsed {
+	if !slower(l.loggedQueries, query.Statement, result.Duration) {
 		return
 	}
 
-	err := errors.Newf(
-		"[%s] query execution time: %s query: %s", tx.id, elapsed, query)
-	_ = tx.log.Error(err)
-	tx.loggedQueries[query] = elapsed
+	message := fmt.Sprintf("[%s] query execution time: %s query: %s", query.TransactionID,
+		result.Duration, query.Statement)
+	if nil == l.explainer {
+		_ = l.log.Error(errors.New(message))
+		return
+	}
+	l.explainer.Explain(query, func(summary string) {
+		if summary != "" {
+			message = fmt.Sprintf("%s plan: %s", message, summary)
+		}
+		_ = l.log.Error(errors.New(message))
+	})
 }
@@ -0,0 +1,40 @@
+package transaction
+
+import (
+	"context"
+	"iter"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/jmoiron/sqlx"
+)
+
+// Stream executes the select query on the transaction and yields each row
+// scanned into a T, which must be a struct with db tags matching the selected
+// columns. Rows are read from the driver as the sequence is consumed rather
+// than being loaded into memory, breaking out of the loop closes them.
+func Stream[T any](tx Transaction, query *qb.SelectQuery, options qb.LimitOffset) iter.Seq2[T, error] {
+	return StreamContext[T](context.Background(), tx, query, options)
+}
+
+// StreamContext is Stream with a context that is passed to the driver
+func StreamContext[T any](ctx context.Context, tx Transaction, query *qb.SelectQuery,
+	options qb.LimitOffset) iter.Seq2[T, error] {
+	return Scan[T](tx.SelectRowsContext(ctx, query, options))
+}
+
+// Scan each of the passed rows into a T using StructScan. Iteration stops after
+// the first error is yielded.
+func Scan[T any](rows iter.Seq2[*sqlx.Rows, error]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for row, err := range rows {
+			var item T
+			if nil == err {
+				err = errors.Wrap(row.StructScan(&item))
+			}
+			if !yield(item, err) || nil != err {
+				return
+			}
+		}
+	}
+}
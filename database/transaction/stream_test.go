@@ -0,0 +1,175 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/log"
+	"github.com/jmoiron/sqlx"
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+// streamConnector is a database/sql driver returning canned rows for any query
+type streamConnector struct {
+	columns  []string
+	values   [][]driver.Value
+	err      error
+	affected int64
+	queries  []string
+	closed   int
+}
+
+func (c *streamConnector) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *streamConnector) Driver() driver.Driver                        { return nil }
+func (c *streamConnector) Prepare(query string) (driver.Stmt, error) {
+	return &streamStmt{connector: c, query: query}, nil
+}
+func (c *streamConnector) Close() error              { return nil }
+func (c *streamConnector) Begin() (driver.Tx, error) { return c, nil }
+func (c *streamConnector) Commit() error             { return nil }
+func (c *streamConnector) Rollback() error           { return nil }
+
+type streamStmt struct {
+	connector *streamConnector
+	query     string
+}
+
+func (s *streamStmt) Close() error  { return nil }
+func (s *streamStmt) NumInput() int { return -1 }
+func (s *streamStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.connector.queries = append(s.connector.queries, s.query)
+	return driver.RowsAffected(s.connector.affected), nil
+}
+func (s *streamStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.connector.queries = append(s.connector.queries, s.query)
+	return &streamRows{connector: s.connector}, nil
+}
+
+type streamRows struct {
+	connector *streamConnector
+	index     int
+}
+
+func (r *streamRows) Columns() []string {
+	if nil == r.connector.columns {
+		return []string{"id", "name"}
+	}
+	return r.connector.columns
+}
+func (r *streamRows) Close() error {
+	r.connector.closed++
+	return nil
+}
+func (r *streamRows) Next(dest []driver.Value) error {
+	if r.index == len(r.connector.values) {
+		if nil != r.connector.err {
+			return r.connector.err
+		}
+		return io.EOF
+	}
+	copy(dest, r.connector.values[r.index])
+	r.index++
+	return nil
+}
+
+type streamRecord struct {
+	ID   string `db:"id"`
+	Name string `db:"name"`
+}
+
+type streamMeta struct {
+	ID   qb.TableField
+	Name qb.TableField
+}
+
+var streamTable = &streamMeta{
+	ID:   qb.TableField{Name: "id", Table: "stream"},
+	Name: qb.TableField{Name: "name", Table: "stream"},
+}
+
+func (m *streamMeta) GetName() string                            { return "stream" }
+func (m *streamMeta) GetAlias() string                           { return "stream" }
+func (m *streamMeta) PrimaryKey() qb.TableField                  { return m.ID }
+func (m *streamMeta) SortBy() (qb.TableField, qb.OrderDirection) { return m.ID, qb.Ascending }
+func (m *streamMeta) AllColumns() qb.TableField                  { return qb.TableField{Name: "*", Table: "stream"} }
+func (m *streamMeta) ReadColumns() []qb.TableField               { return []qb.TableField{m.ID, m.Name} }
+func (m *streamMeta) WriteColumns() []qb.TableField              { return m.ReadColumns() }
+
+func newStreamTransaction(t *testing.T, connector *streamConnector) Transaction {
+	db := sqlx.NewDb(sql.OpenDB(connector), qb.MySQLDriver)
+	t.Cleanup(func() { _ = db.Close() })
+	tx, err := db.Beginx()
+	if nil != err {
+		t.Fatal(err)
+	}
+	return &transaction{
+		implementation: &interceptedTx{implementation: tx,
+			interceptors: []QueryInterceptor{NewSlowQueryLogger(time.Hour, log.Global(), nil)}},
+		dialect: qb.MySQL,
+	}
+}
+
+func TestStream(t *testing.T) {
+	assert := assert1.New(t)
+	connector := &streamConnector{values: [][]driver.Value{{"a", "first"}, {"b", "second"}, {"c", "third"}}}
+	tx := newStreamTransaction(t, connector)
+	query := qb.Select(streamTable.AllColumns()).From(streamTable)
+
+	var actual []streamRecord
+	for record, err := range Stream[streamRecord](tx, query, nil) {
+		assert.NoError(err)
+		actual = append(actual, record)
+	}
+	assert.Equal([]streamRecord{{ID: "a", Name: "first"}, {ID: "b", Name: "second"},
+		{ID: "c", Name: "third"}}, actual)
+	assert.Equal([]string{"SELECT `stream`.* FROM `stream` AS `stream`"}, connector.queries)
+	assert.Equal(1, connector.closed)
+
+	// breaking early closes the rows
+	actual = nil
+	for record, err := range Stream[streamRecord](tx, query, qb.NewLimitOffset[int]().SetLimit(10)) {
+		assert.NoError(err)
+		actual = append(actual, record)
+		break
+	}
+	assert.Equal([]streamRecord{{ID: "a", Name: "first"}}, actual)
+	assert.Equal(2, connector.closed)
+}
+
+func TestStream_Errors(t *testing.T) {
+	assert := assert1.New(t)
+	connector := &streamConnector{values: [][]driver.Value{{"a", "first"}}, err: errors.New("connection lost")}
+	tx := newStreamTransaction(t, connector)
+
+	var count int
+	for _, err := range Stream[streamRecord](tx, qb.Select(streamTable.AllColumns()).From(streamTable), nil) {
+		count++
+		if count == 2 {
+			assert.ErrorContains(err, "connection lost")
+		}
+	}
+	assert.Equal(2, count)
+
+	// the query is validated before it is executed
+	count = 0
+	for _, err := range Stream[streamRecord](tx, qb.Select(streamTable.AllColumns()), nil) {
+		count++
+		assert.Error(err)
+	}
+	assert.Equal(1, count)
+	assert.Len(connector.queries, 1)
+
+	// columns that are not on the struct fail the scan
+	count = 0
+	for _, err := range Stream[struct{ ID string }](tx, qb.Select(streamTable.AllColumns()).From(streamTable), nil) {
+		count++
+		assert.Error(err)
+	}
+	assert.Equal(1, count)
+}
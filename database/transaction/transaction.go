@@ -2,9 +2,13 @@
 package transaction
 
 import (
+	"context"
 	"fmt"
+	"iter"
+	"slices"
 	"time"
 
+	"github.com/beaconsoftwarellc/gadget/v2/database/audit"
 	dberrors "github.com/beaconsoftwarellc/gadget/v2/database/errors"
 	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
 	"github.com/beaconsoftwarellc/gadget/v2/database/record"
@@ -12,6 +16,7 @@ import (
 	"github.com/beaconsoftwarellc/gadget/v2/errors"
 	"github.com/beaconsoftwarellc/gadget/v2/generator"
 	"github.com/beaconsoftwarellc/gadget/v2/log"
+	"github.com/jmoiron/sqlx"
 	"github.com/samber/lo"
 )
 
@@ -19,68 +24,155 @@ import (
 //
 // A transaction must end with a call to Commit or Rollback.
 //
+// Reads, lists and counts exclude the rows of record.SoftDeletable tables that
+// are deleted unless the context passed was created with record.WithDeleted or
+// record.WithOnlyDeleted.
+//
 // After a call to Commit or Rollback, all operations on the
 // transaction fail with ErrTxDone.
+//
+// Each operation has a Context variant that passes the context to the driver
+// so that deadlines and cancellation abort the executing statement.
 type Transaction interface {
 	utility.CommitRollback
 	// Create initializes a Record and inserts it into the Database
 	Create(record.Record) errors.TracerError
+	// CreateContext initializes a Record and inserts it into the Database
+	CreateContext(context.Context, record.Record) errors.TracerError
 	// Upsert a new entry into the database for the Record
 	Upsert(record.Record) errors.TracerError
+	// UpsertContext a new entry into the database for the Record
+	UpsertContext(context.Context, record.Record) errors.TracerError
 	// Read populates a Record from the database
 	Read(record.Record, record.PrimaryKeyValue) errors.TracerError
+	// ReadContext populates a Record from the database
+	ReadContext(context.Context, record.Record, record.PrimaryKeyValue) errors.TracerError
 	// ReadOneWhere populates a Record from a custom where clause
 	ReadOneWhere(record.Record, *qb.ConditionExpression) errors.TracerError
+	// ReadOneWhereContext populates a Record from a custom where clause
+	ReadOneWhereContext(context.Context, record.Record, *qb.ConditionExpression) errors.TracerError
 	// List populates obj with a list of Records from the database
 	// TODO: [COR-586] we can expand the Record interface to return an collection
 	// 		 of its type so we don't have to pass this clumsily
 	List(record.Record, any, qb.LimitOffset) errors.TracerError
+	// ListContext populates obj with a list of Records from the database
+	ListContext(context.Context, record.Record, any, qb.LimitOffset) errors.TracerError
 	// ListWhere populates target with a list of Records from the database
 	// TODO: [COR-586] we can expand the Record interface to return a collection
 	// 		 of its type so we don't have to pass this clumsily
 	ListWhere(record.Record, any, *qb.ConditionExpression, qb.LimitOffset) errors.TracerError
+	// ListWhereContext populates target with a list of Records from the database
+	ListWhereContext(context.Context, record.Record, any, *qb.ConditionExpression,
+		qb.LimitOffset) errors.TracerError
 	// Select executes a given select query and populates the target
 	// TODO: [COR-586] we can expand the Record interface to return a collection
 	// 		 of its type so we don't have to pass this clumsily
 	Select(any, *qb.SelectQuery, qb.LimitOffset) errors.TracerError
-	// Update replaces an entry in the database for the Record
+	// SelectContext executes a given select query and populates the target
+	SelectContext(context.Context, any, *qb.SelectQuery, qb.LimitOffset) errors.TracerError
+	// SelectRows executes a given select query and yields the result set positioned
+	// at each row in turn, the rows are closed when iteration ends. See Stream.
+	SelectRows(*qb.SelectQuery, qb.LimitOffset) iter.Seq2[*sqlx.Rows, error]
+	// SelectRowsContext executes a given select query and yields the result set
+	// positioned at each row in turn, the rows are closed when iteration ends.
+	SelectRowsContext(context.Context, *qb.SelectQuery, qb.LimitOffset) iter.Seq2[*sqlx.Rows, error]
+	// Update replaces an entry in the database for the Record, a
+	// dberrors.StaleRecordError is returned when the Record implements
+	// record.Versioned and its version is not current
 	Update(record.Record) errors.TracerError
+	// UpdateContext replaces an entry in the database for the Record
+	UpdateContext(context.Context, record.Record) errors.TracerError
 	// UpdateWhere updates fields for the Record based on a supplied where clause in a transaction
 	UpdateWhere(record.Record, *qb.ConditionExpression, ...qb.FieldValue) (int64, errors.TracerError)
+	// UpdateWhereContext updates fields for the Record based on a supplied where clause in a transaction
+	UpdateWhereContext(context.Context, record.Record, *qb.ConditionExpression,
+		...qb.FieldValue) (int64, errors.TracerError)
 	// UpdateIgnoreWhere updates fields for the Record based on a supplied where clause in a transaction,
 	// continuing on any ignorable errors.
 	UpdateIgnoreWhere(record.Record, *qb.ConditionExpression, ...qb.FieldValue) (int64, errors.TracerError)
-	// Delete removes a row from the database
+	// UpdateIgnoreWhereContext updates fields for the Record based on a supplied where clause in a
+	// transaction, continuing on any ignorable errors.
+	UpdateIgnoreWhereContext(context.Context, record.Record, *qb.ConditionExpression,
+		...qb.FieldValue) (int64, errors.TracerError)
+	// Delete removes a row from the database or sets the deleted column if the
+	// Record meta is record.SoftDeletable
 	Delete(record.Record) errors.TracerError
+	// DeleteContext removes a row from the database
+	DeleteContext(context.Context, record.Record) errors.TracerError
 	// DeleteWhere removes row(s) from the database based on a supplied where clause
+	// or sets the deleted column if the Record meta is record.SoftDeletable
 	DeleteWhere(record.Record, *qb.ConditionExpression) errors.TracerError
+	// DeleteWhereContext removes row(s) from the database based on a supplied where clause
+	DeleteWhereContext(context.Context, record.Record, *qb.ConditionExpression) errors.TracerError
+	// SetAudit enables or disables writing an audit.Entry for each change made
+	// to a Record by Create, Upsert, Update, Delete and the Where variants. Entries
+	// are written in this transaction and attributed to the actor from
+	// audit.WithActor or the session of the logger.
+	SetAudit(enabled bool)
+	// History of the changes made to a Record, oldest first
+	History(record.Record) ([]*audit.Entry, errors.TracerError)
+	// HistoryContext of the changes made to a Record, oldest first
+	HistoryContext(context.Context, record.Record) ([]*audit.Entry, errors.TracerError)
+	// Restore clears the deleted column of a soft deleted Record and reads it
+	Restore(record.Record) errors.TracerError
+	// RestoreContext clears the deleted column of a soft deleted Record and reads it
+	RestoreContext(context.Context, record.Record) errors.TracerError
 	// PrepareNamed returns a NamedStatement that can be used
 	// to execute the prepared statement using named parameters
 	PrepareNamed(query string) (NamedStatement, errors.TracerError)
+	// PrepareNamedContext returns a NamedStatement that can be used
+	// to execute the prepared statement using named parameters
+	PrepareNamedContext(ctx context.Context, query string) (NamedStatement, errors.TracerError)
 	// Implementation that is backing this transaction
 	Implementation() Implementation
 }
 
-// New transaction that will log query executions that are slower than the passed
-// duration
+// New MySQL transaction that will log query executions that are slower than the
+// passed duration. The passed interceptors are called around each query after a
+// SlowQueryLogger, which is only created when one is not passed.
 func New(db Begin, logger log.Logger, slow time.Duration,
-	loggedQueries map[string]time.Duration) (Transaction, error) {
-	tx, err := db.Begin()
+	loggedQueries map[string]time.Duration, interceptors ...QueryInterceptor) (Transaction, error) {
+	return NewWithDialect(db, qb.MySQL, logger, slow, loggedQueries, interceptors...)
+}
+
+// NewWithDialect is New with queries rendered in the passed dialect
+func NewWithDialect(db Begin, dialect qb.Dialect, logger log.Logger, slow time.Duration,
+	loggedQueries map[string]time.Duration, interceptors ...QueryInterceptor) (Transaction, error) {
+	return NewContext(context.Background(), db, dialect, logger, slow, loggedQueries, interceptors...)
+}
+
+// NewContext transaction rendering queries in the passed dialect that is rolled
+// back by the driver if the passed context is done before the transaction is
+// committed. The context is only used when db is a BeginContexter. See New.
+func NewContext(ctx context.Context, db Begin, dialect qb.Dialect, logger log.Logger,
+	slow time.Duration, loggedQueries map[string]time.Duration,
+	interceptors ...QueryInterceptor) (Transaction, error) {
+	tx, err := beginContext(ctx, db)
 	if nil != err {
 		return nil, err
 	}
-	implementation := &slowQueryLoggerTx{
+	if !slices.ContainsFunc(interceptors, isSlowQueryLogger) {
+		interceptors = append([]QueryInterceptor{NewSlowQueryLogger(slow, logger, loggedQueries)},
+			interceptors...)
+	}
+	implementation := &interceptedTx{
 		implementation: tx,
-		slow:           slow,
-		log:            logger,
 		id:             generator.ID("TX"),
-		loggedQueries:  loggedQueries,
+		interceptors:   interceptors,
 	}
-	return &transaction{implementation: implementation}, nil
+	return &transaction{implementation: implementation, dialect: dialect, log: logger}, nil
+}
+
+func isSlowQueryLogger(interceptor QueryInterceptor) bool {
+	_, ok := interceptor.(*SlowQueryLogger)
+	return ok
 }
 
 type transaction struct {
 	implementation Implementation
+	dialect        qb.Dialect
+	log            log.Logger
+	auditing       bool
 }
 
 func (tx *transaction) Implementation() Implementation {
@@ -88,23 +180,30 @@ func (tx *transaction) Implementation() Implementation {
 }
 
 func (tx *transaction) Create(obj record.Record) errors.TracerError {
+	return tx.CreateContext(context.Background(), obj)
+}
+
+func (tx *transaction) CreateContext(ctx context.Context, obj record.Record) errors.TracerError {
 	var tracerErr errors.TracerError
 	var previousPK record.PrimaryKeyValue
 	obj.Initialize()
 	for i := 0; i < 5; i++ {
 		writeCols := utility.AppendIfMissing(obj.Meta().WriteColumns(),
 			obj.Meta().PrimaryKey())
-		query := qb.Insert(writeCols...)
+		query := qb.Insert(writeCols...).WithDialect(tx.dialect)
 		stmt, err := query.ParameterizedSQL()
 		if nil != err {
 			return errors.Wrap(err)
 		}
 
-		_, err = tx.implementation.NamedExec(stmt, obj)
+		_, err = tx.implementation.NamedExecContext(ctx, stmt, obj)
 		if nil == err {
-			return tx.Read(obj, obj.PrimaryKey())
+			if tracerErr = tx.reread(ctx, obj); nil != tracerErr {
+				return tracerErr
+			}
+			return tx.writeAudit(ctx, audit.Create, nil, obj)
 		}
-		tracerErr = dberrors.TranslateError(err, dberrors.Insert, stmt)
+		tracerErr = dberrors.TranslateErrorFor(tx.dialect, err, dberrors.Insert, stmt)
 		switch tracerErr.(type) {
 		case *dberrors.DuplicateRecordError:
 			previousPK = obj.PrimaryKey()
@@ -122,6 +221,10 @@ func (tx *transaction) Create(obj record.Record) errors.TracerError {
 }
 
 func (tx *transaction) Upsert(obj record.Record) errors.TracerError {
+	return tx.UpsertContext(context.Background(), obj)
+}
+
+func (tx *transaction) UpsertContext(ctx context.Context, obj record.Record) errors.TracerError {
 	insertCols := utility.AppendIfMissing(obj.Meta().ReadColumns(), obj.Meta().PrimaryKey())
 	updateCols := make([]qb.TableField, len(obj.Meta().WriteColumns()))
 	copy(updateCols, obj.Meta().WriteColumns())
@@ -135,59 +238,96 @@ func (tx *transaction) Upsert(obj record.Record) errors.TracerError {
 		updateCols = utility.AppendIfMissing(updateCols, updateOn)
 	}
 
-	query := qb.Insert(insertCols...).OnDuplicate(updateCols)
+	query := qb.Insert(insertCols...).
+		OnDuplicate(updateCols).
+		ConflictOn(obj.Meta().PrimaryKey()).
+		WithDialect(tx.dialect)
 	stmt, err := query.ParameterizedSQL()
 	if nil != err {
 		return errors.Wrap(err)
 	}
+	before, tracerErr := tx.current(ctx, obj)
+	if nil != tracerErr {
+		return tracerErr
+	}
 
-	_, err = tx.implementation.NamedExec(stmt, obj)
+	_, err = tx.implementation.NamedExecContext(ctx, stmt, obj)
 
 	if nil != err {
-		return dberrors.TranslateError(err, dberrors.Insert, stmt)
+		return dberrors.TranslateErrorFor(tx.dialect, err, dberrors.Insert, stmt)
+	}
+	if tracerErr = tx.reread(ctx, obj); nil != tracerErr {
+		return tracerErr
 	}
-	return tx.Read(obj, obj.PrimaryKey())
+	return tx.writeAudit(ctx, audit.Upsert, before, obj)
 }
 
 func (tx *transaction) Read(obj record.Record, pk record.PrimaryKeyValue) errors.TracerError {
-	return tx.ReadOneWhere(obj, obj.Meta().PrimaryKey().Equal(pk.Value()))
+	return tx.ReadContext(context.Background(), obj, pk)
+}
+
+func (tx *transaction) ReadContext(ctx context.Context, obj record.Record,
+	pk record.PrimaryKeyValue) errors.TracerError {
+	return tx.ReadOneWhereContext(ctx, obj, obj.Meta().PrimaryKey().Equal(pk.Value()))
+}
+
+// reread obj by its primary key after writing it, including the row when it is
+// soft deleted so that writes to deleted records do not report it as not found
+func (tx *transaction) reread(ctx context.Context, obj record.Record) errors.TracerError {
+	return tx.ReadContext(record.WithDeleted(ctx), obj, obj.PrimaryKey())
 }
 
 func (tx *transaction) ReadOneWhere(obj record.Record, condition *qb.ConditionExpression) errors.TracerError {
+	return tx.ReadOneWhereContext(context.Background(), obj, condition)
+}
+
+func (tx *transaction) ReadOneWhereContext(ctx context.Context, obj record.Record,
+	condition *qb.ConditionExpression) errors.TracerError {
 	options := qb.NewLimitOffset[int]().SetLimit(1).SetOffset(0)
-	stmt, args, err := qb.Select(obj.Meta().AllColumns()).From(obj.Meta()).Where(condition).SQL(options)
+	stmt, args, err := qb.Select(obj.Meta().AllColumns()).
+		From(obj.Meta()).
+		Where(record.ScopeDeleted(ctx, obj.Meta(), condition)).
+		WithDialect(tx.dialect).
+		SQL(options)
 	if nil != err {
 		return errors.Wrap(err)
 	}
-	if err = tx.implementation.QueryRowx(stmt, args...).StructScan(obj); nil != err {
-		return dberrors.TranslateError(err, dberrors.Select, fmt.Sprintf("%s %% %v", stmt, args))
+	if err = tx.implementation.QueryRowxContext(ctx, stmt, args...).StructScan(obj); nil != err {
+		return dberrors.TranslateErrorFor(tx.dialect, err, dberrors.Select, fmt.Sprintf("%s %% %v", stmt, args))
 	}
 	return nil
 }
 
 func (tx *transaction) List(def record.Record, obj any,
 	options qb.LimitOffset) errors.TracerError {
-	stmt, _, err := qb.Select(def.Meta().AllColumns()).
-		From(def.Meta()).
-		OrderBy(def.Meta().SortBy()).
-		SQL(options)
+	return tx.ListContext(context.Background(), def, obj, options)
+}
+
+func (tx *transaction) ListContext(ctx context.Context, def record.Record, obj any,
+	options qb.LimitOffset) errors.TracerError {
+	stmt, values, err := tx.buildListWhere(def, record.ScopeDeleted(ctx, def.Meta(), nil)).SQL(options)
 	if err != nil {
 		return errors.Wrap(err)
 	}
-	if err = tx.implementation.Select(obj, stmt); nil != err {
-		return dberrors.TranslateError(err, dberrors.Select, stmt)
+	if err = tx.implementation.SelectContext(ctx, obj, stmt, values...); nil != err {
+		return dberrors.TranslateErrorFor(tx.dialect, err, dberrors.Select, stmt)
 	}
 	return nil
 }
 
 func (tx *transaction) ListWhere(meta record.Record, target interface{},
 	condition *qb.ConditionExpression, options qb.LimitOffset) errors.TracerError {
-	stmt, values, err := tx.buildListWhere(meta, condition).SQL(options)
+	return tx.ListWhereContext(context.Background(), meta, target, condition, options)
+}
+
+func (tx *transaction) ListWhereContext(ctx context.Context, meta record.Record, target interface{},
+	condition *qb.ConditionExpression, options qb.LimitOffset) errors.TracerError {
+	stmt, values, err := tx.buildListWhere(meta, record.ScopeDeleted(ctx, meta.Meta(), condition)).SQL(options)
 	if nil != err {
 		return errors.Wrap(err)
 	}
-	if err = tx.implementation.Select(target, stmt, values...); nil != err {
-		return dberrors.TranslateError(err, dberrors.Select, stmt)
+	if err = tx.implementation.SelectContext(ctx, target, stmt, values...); nil != err {
+		return dberrors.TranslateErrorFor(tx.dialect, err, dberrors.Select, stmt)
 	}
 	return nil
 }
@@ -196,75 +336,207 @@ func (tx *transaction) buildListWhere(def record.Record, condition *qb.Condition
 	return qb.Select(def.Meta().AllColumns()).
 		From(def.Meta()).
 		Where(condition).
-		OrderBy(def.Meta().SortBy())
+		OrderBy(def.Meta().SortBy()).
+		WithDialect(tx.dialect)
 }
 
 func (tx *transaction) Select(target interface{}, query *qb.SelectQuery,
 	options qb.LimitOffset) errors.TracerError {
-	stmt, values, err := query.SQL(options)
+	return tx.SelectContext(context.Background(), target, query, options)
+}
+
+func (tx *transaction) SelectContext(ctx context.Context, target interface{}, query *qb.SelectQuery,
+	options qb.LimitOffset) errors.TracerError {
+	stmt, values, err := query.SQLFor(tx.dialect, options)
 	if err != nil {
 		return errors.Wrap(err)
 	}
 
-	if err = tx.implementation.Select(target, stmt, values...); nil != err {
-		return dberrors.TranslateError(err, dberrors.Select, stmt)
+	if err = tx.implementation.SelectContext(ctx, target, stmt, values...); nil != err {
+		return dberrors.TranslateErrorFor(tx.dialect, err, dberrors.Select, stmt)
 	}
 	return nil
 }
 
+func (tx *transaction) SelectRows(query *qb.SelectQuery,
+	options qb.LimitOffset) iter.Seq2[*sqlx.Rows, error] {
+	return tx.SelectRowsContext(context.Background(), query, options)
+}
+
+func (tx *transaction) SelectRowsContext(ctx context.Context, query *qb.SelectQuery,
+	options qb.LimitOffset) iter.Seq2[*sqlx.Rows, error] {
+	return func(yield func(*sqlx.Rows, error) bool) {
+		stmt, values, err := query.SQLFor(tx.dialect, options)
+		if nil != err {
+			yield(nil, errors.Wrap(err))
+			return
+		}
+		rows, err := tx.implementation.QueryxContext(ctx, stmt, values...)
+		if nil != err {
+			yield(nil, dberrors.TranslateErrorFor(tx.dialect, err, dberrors.Select, stmt))
+			return
+		}
+		// closing releases the connection when the caller breaks early
+		defer rows.Close()
+		for rows.Next() {
+			if !yield(rows, nil) {
+				return
+			}
+		}
+		if err = rows.Err(); nil != err {
+			yield(nil, dberrors.TranslateErrorFor(tx.dialect, err, dberrors.Select, stmt))
+		}
+	}
+}
+
 func (tx *transaction) Update(obj record.Record) errors.TracerError {
-	query := qb.Update(obj.Meta())
+	return tx.UpdateContext(context.Background(), obj)
+}
+
+func (tx *transaction) UpdateContext(ctx context.Context, obj record.Record) errors.TracerError {
+	query := qb.Update(obj.Meta()).WithDialect(tx.dialect)
+	version, versioned := record.VersionColumn(obj)
 	for _, col := range obj.Meta().WriteColumns() {
-		query.SetParam(col)
+		if !versioned || col != version {
+			query.SetParam(col)
+		}
+	}
+	where := obj.Meta().PrimaryKey().Equal(":" + obj.Meta().PrimaryKey().GetName())
+	if versioned {
+		query.Increment(version)
+		where.And(version.Equal(":" + version.GetName()))
+	}
+	query.Where(where)
+	// the primary key condition already restricts the update to a single row,
+	// the limit is a safeguard on dialects that support it
+	limit := 1
+	if !query.GetDialect().SupportsUpdateLimit() {
+		limit = qb.NoLimit
 	}
-	query.Where(obj.Meta().PrimaryKey().
-		Equal(":" + obj.Meta().PrimaryKey().GetName()))
-	stmt, err := query.ParameterizedSQL(1)
+	stmt, err := query.ParameterizedSQL(limit)
 	if nil != err {
 		return errors.Wrap(err)
 	}
+	before, tracerErr := tx.current(ctx, obj)
+	if nil != tracerErr {
+		return tracerErr
+	}
 
-	_, err = tx.implementation.NamedExec(stmt, obj)
+	result, err := tx.implementation.NamedExecContext(ctx, stmt, obj)
 	if nil != err {
-		return dberrors.TranslateError(err, dberrors.Update, stmt)
+		return dberrors.TranslateErrorFor(tx.dialect, err, dberrors.Update, stmt)
+	}
+	if versioned {
+		// the version is always incremented so a matching row is always affected
+		rowsAffected, err := result.RowsAffected()
+		if nil != err {
+			return errors.Wrap(err)
+		}
+		if rowsAffected == 0 {
+			return dberrors.NewStaleRecordError(dberrors.Update, stmt)
+		}
 	}
 
-	return tx.Read(obj, obj.PrimaryKey())
+	if tracerErr = tx.reread(ctx, obj); nil != tracerErr {
+		return tracerErr
+	}
+	return tx.writeAudit(ctx, audit.Update, before, obj)
 }
 
 func (tx *transaction) Delete(obj record.Record) errors.TracerError {
+	return tx.DeleteContext(context.Background(), obj)
+}
+
+func (tx *transaction) DeleteContext(ctx context.Context, obj record.Record) errors.TracerError {
 	where := obj.Meta().PrimaryKey().Equal(obj.PrimaryKey().Value())
-	return tx.DeleteWhere(obj, where)
+	return tx.DeleteWhereContext(ctx, obj, where)
 }
 
 func (tx *transaction) DeleteWhere(obj record.Record,
 	condition *qb.ConditionExpression) errors.TracerError {
-	stmt, values, err := qb.Delete(obj.Meta()).Where(condition).SQL()
+	return tx.DeleteWhereContext(context.Background(), obj, condition)
+}
+
+func (tx *transaction) DeleteWhereContext(ctx context.Context, obj record.Record,
+	condition *qb.ConditionExpression) errors.TracerError {
+	if deleted, ok := record.DeletedColumn(obj.Meta()); ok {
+		// rows that are already deleted keep the time they were first deleted
+		condition = record.ScopeDeleted(context.Background(), obj.Meta(), condition)
+		_, err := tx.auditWhere(ctx, obj, condition, audit.Delete, func() (int64, errors.TracerError) {
+			return tx.updateWhere(ctx, obj, condition, false,
+				qb.FieldValue{Field: deleted, Value: time.Now().UTC()})
+		})
+		return err
+	}
+	_, err := tx.auditWhere(ctx, obj, condition, audit.Delete, func() (int64, errors.TracerError) {
+		return 0, tx.deleteWhere(ctx, obj, condition)
+	})
+	return err
+}
+
+func (tx *transaction) deleteWhere(ctx context.Context, obj record.Record,
+	condition *qb.ConditionExpression) errors.TracerError {
+	stmt, values, err := qb.Delete(obj.Meta()).
+		Where(condition).
+		WithDialect(tx.dialect).
+		SQL()
 	if nil != err {
 		return errors.Wrap(err)
 	}
 
-	_, err = tx.implementation.Exec(stmt, values...)
+	_, err = tx.implementation.ExecContext(ctx, stmt, values...)
 
 	if nil != err {
-		return dberrors.TranslateError(err, dberrors.Delete, stmt)
+		return dberrors.TranslateErrorFor(tx.dialect, err, dberrors.Delete, stmt)
 	}
 	return nil
 }
 
+func (tx *transaction) Restore(obj record.Record) errors.TracerError {
+	return tx.RestoreContext(context.Background(), obj)
+}
+
+func (tx *transaction) RestoreContext(ctx context.Context, obj record.Record) errors.TracerError {
+	deleted, ok := record.DeletedColumn(obj.Meta())
+	if !ok {
+		return dberrors.NewValidationError("%s does not support soft delete", obj.Meta().GetName())
+	}
+	where := obj.Meta().PrimaryKey().Equal(obj.PrimaryKey().Value())
+	if _, err := tx.auditWhere(ctx, obj, where, audit.Update, func() (int64, errors.TracerError) {
+		return tx.updateWhere(ctx, obj, where, false, qb.FieldValue{Field: deleted, Value: qb.SQLNull})
+	}); nil != err {
+		return err
+	}
+	return tx.reread(ctx, obj)
+}
+
 func (tx *transaction) UpdateWhere(obj record.Record,
 	where *qb.ConditionExpression, fields ...qb.FieldValue) (int64, errors.TracerError) {
-	return tx.updateWhere(obj, where, false, fields...)
+	return tx.UpdateWhereContext(context.Background(), obj, where, fields...)
+}
+
+func (tx *transaction) UpdateWhereContext(ctx context.Context, obj record.Record,
+	where *qb.ConditionExpression, fields ...qb.FieldValue) (int64, errors.TracerError) {
+	return tx.auditWhere(ctx, obj, where, audit.Update, func() (int64, errors.TracerError) {
+		return tx.updateWhere(ctx, obj, where, false, fields...)
+	})
 }
 
 func (tx *transaction) UpdateIgnoreWhere(obj record.Record,
 	where *qb.ConditionExpression, fields ...qb.FieldValue) (int64, errors.TracerError) {
-	return tx.updateWhere(obj, where, true, fields...)
+	return tx.UpdateIgnoreWhereContext(context.Background(), obj, where, fields...)
 }
 
-func (tx *transaction) updateWhere(obj record.Record,
+func (tx *transaction) UpdateIgnoreWhereContext(ctx context.Context, obj record.Record,
+	where *qb.ConditionExpression, fields ...qb.FieldValue) (int64, errors.TracerError) {
+	return tx.auditWhere(ctx, obj, where, audit.Update, func() (int64, errors.TracerError) {
+		return tx.updateWhere(ctx, obj, where, true, fields...)
+	})
+}
+
+func (tx *transaction) updateWhere(ctx context.Context, obj record.Record,
 	where *qb.ConditionExpression, ignore bool, fields ...qb.FieldValue) (int64, errors.TracerError) {
-	query := qb.Update(obj.Meta())
+	query := qb.Update(obj.Meta()).WithDialect(tx.dialect)
 	query.SetIgnore(ignore)
 
 	for _, f := range fields {
@@ -276,9 +548,9 @@ func (tx *transaction) updateWhere(obj record.Record,
 		return 0, errors.Wrap(err)
 	}
 
-	result, err := tx.implementation.Exec(stmt, values...)
+	result, err := tx.implementation.ExecContext(ctx, stmt, values...)
 	if nil != err {
-		return 0, dberrors.TranslateError(err, dberrors.Update, stmt)
+		return 0, dberrors.TranslateErrorFor(tx.dialect, err, dberrors.Update, stmt)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -301,3 +573,8 @@ func (tx *transaction) PrepareNamed(query string) (NamedStatement, errors.Tracer
 	statement, err := tx.implementation.PrepareNamed(query)
 	return statement, errors.Wrap(err)
 }
+
+func (tx *transaction) PrepareNamedContext(ctx context.Context, query string) (NamedStatement, errors.TracerError) {
+	statement, err := tx.implementation.PrepareNamedContext(ctx, query)
+	return statement, errors.Wrap(err)
+}
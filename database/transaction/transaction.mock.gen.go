@@ -10,11 +10,15 @@
 package transaction
 
 import (
+	context "context"
+	iter "iter"
 	reflect "reflect"
 
+	audit "github.com/beaconsoftwarellc/gadget/v2/database/audit"
 	qb "github.com/beaconsoftwarellc/gadget/v2/database/qb"
 	record "github.com/beaconsoftwarellc/gadget/v2/database/record"
 	errors "github.com/beaconsoftwarellc/gadget/v2/errors"
+	sqlx "github.com/jmoiron/sqlx"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -70,6 +74,20 @@ func (mr *MockTransactionMockRecorder) Create(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTransaction)(nil).Create), arg0)
 }
 
+// CreateContext mocks base method.
+func (m *MockTransaction) CreateContext(arg0 context.Context, arg1 record.Record) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateContext", arg0, arg1)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// CreateContext indicates an expected call of CreateContext.
+func (mr *MockTransactionMockRecorder) CreateContext(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateContext", reflect.TypeOf((*MockTransaction)(nil).CreateContext), arg0, arg1)
+}
+
 // Delete mocks base method.
 func (m *MockTransaction) Delete(arg0 record.Record) errors.TracerError {
 	m.ctrl.T.Helper()
@@ -84,6 +102,20 @@ func (mr *MockTransactionMockRecorder) Delete(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTransaction)(nil).Delete), arg0)
 }
 
+// DeleteContext mocks base method.
+func (m *MockTransaction) DeleteContext(arg0 context.Context, arg1 record.Record) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteContext", arg0, arg1)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// DeleteContext indicates an expected call of DeleteContext.
+func (mr *MockTransactionMockRecorder) DeleteContext(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteContext", reflect.TypeOf((*MockTransaction)(nil).DeleteContext), arg0, arg1)
+}
+
 // DeleteWhere mocks base method.
 func (m *MockTransaction) DeleteWhere(arg0 record.Record, arg1 *qb.ConditionExpression) errors.TracerError {
 	m.ctrl.T.Helper()
@@ -98,6 +130,50 @@ func (mr *MockTransactionMockRecorder) DeleteWhere(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWhere", reflect.TypeOf((*MockTransaction)(nil).DeleteWhere), arg0, arg1)
 }
 
+// DeleteWhereContext mocks base method.
+func (m *MockTransaction) DeleteWhereContext(arg0 context.Context, arg1 record.Record, arg2 *qb.ConditionExpression) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWhereContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// DeleteWhereContext indicates an expected call of DeleteWhereContext.
+func (mr *MockTransactionMockRecorder) DeleteWhereContext(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWhereContext", reflect.TypeOf((*MockTransaction)(nil).DeleteWhereContext), arg0, arg1, arg2)
+}
+
+// History mocks base method.
+func (m *MockTransaction) History(arg0 record.Record) ([]*audit.Entry, errors.TracerError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", arg0)
+	ret0, _ := ret[0].([]*audit.Entry)
+	ret1, _ := ret[1].(errors.TracerError)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockTransactionMockRecorder) History(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockTransaction)(nil).History), arg0)
+}
+
+// HistoryContext mocks base method.
+func (m *MockTransaction) HistoryContext(arg0 context.Context, arg1 record.Record) ([]*audit.Entry, errors.TracerError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HistoryContext", arg0, arg1)
+	ret0, _ := ret[0].([]*audit.Entry)
+	ret1, _ := ret[1].(errors.TracerError)
+	return ret0, ret1
+}
+
+// HistoryContext indicates an expected call of HistoryContext.
+func (mr *MockTransactionMockRecorder) HistoryContext(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HistoryContext", reflect.TypeOf((*MockTransaction)(nil).HistoryContext), arg0, arg1)
+}
+
 // Implementation mocks base method.
 func (m *MockTransaction) Implementation() Implementation {
 	m.ctrl.T.Helper()
@@ -126,6 +202,20 @@ func (mr *MockTransactionMockRecorder) List(arg0, arg1, arg2 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTransaction)(nil).List), arg0, arg1, arg2)
 }
 
+// ListContext mocks base method.
+func (m *MockTransaction) ListContext(arg0 context.Context, arg1 record.Record, arg2 any, arg3 qb.LimitOffset) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListContext", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// ListContext indicates an expected call of ListContext.
+func (mr *MockTransactionMockRecorder) ListContext(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListContext", reflect.TypeOf((*MockTransaction)(nil).ListContext), arg0, arg1, arg2, arg3)
+}
+
 // ListWhere mocks base method.
 func (m *MockTransaction) ListWhere(arg0 record.Record, arg1 any, arg2 *qb.ConditionExpression, arg3 qb.LimitOffset) errors.TracerError {
 	m.ctrl.T.Helper()
@@ -140,6 +230,20 @@ func (mr *MockTransactionMockRecorder) ListWhere(arg0, arg1, arg2, arg3 any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWhere", reflect.TypeOf((*MockTransaction)(nil).ListWhere), arg0, arg1, arg2, arg3)
 }
 
+// ListWhereContext mocks base method.
+func (m *MockTransaction) ListWhereContext(arg0 context.Context, arg1 record.Record, arg2 any, arg3 *qb.ConditionExpression, arg4 qb.LimitOffset) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWhereContext", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// ListWhereContext indicates an expected call of ListWhereContext.
+func (mr *MockTransactionMockRecorder) ListWhereContext(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWhereContext", reflect.TypeOf((*MockTransaction)(nil).ListWhereContext), arg0, arg1, arg2, arg3, arg4)
+}
+
 // PrepareNamed mocks base method.
 func (m *MockTransaction) PrepareNamed(query string) (NamedStatement, errors.TracerError) {
 	m.ctrl.T.Helper()
@@ -155,6 +259,21 @@ func (mr *MockTransactionMockRecorder) PrepareNamed(query any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrepareNamed", reflect.TypeOf((*MockTransaction)(nil).PrepareNamed), query)
 }
 
+// PrepareNamedContext mocks base method.
+func (m *MockTransaction) PrepareNamedContext(ctx context.Context, query string) (NamedStatement, errors.TracerError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrepareNamedContext", ctx, query)
+	ret0, _ := ret[0].(NamedStatement)
+	ret1, _ := ret[1].(errors.TracerError)
+	return ret0, ret1
+}
+
+// PrepareNamedContext indicates an expected call of PrepareNamedContext.
+func (mr *MockTransactionMockRecorder) PrepareNamedContext(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrepareNamedContext", reflect.TypeOf((*MockTransaction)(nil).PrepareNamedContext), ctx, query)
+}
+
 // Read mocks base method.
 func (m *MockTransaction) Read(arg0 record.Record, arg1 record.PrimaryKeyValue) errors.TracerError {
 	m.ctrl.T.Helper()
@@ -169,6 +288,20 @@ func (mr *MockTransactionMockRecorder) Read(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockTransaction)(nil).Read), arg0, arg1)
 }
 
+// ReadContext mocks base method.
+func (m *MockTransaction) ReadContext(arg0 context.Context, arg1 record.Record, arg2 record.PrimaryKeyValue) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// ReadContext indicates an expected call of ReadContext.
+func (mr *MockTransactionMockRecorder) ReadContext(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadContext", reflect.TypeOf((*MockTransaction)(nil).ReadContext), arg0, arg1, arg2)
+}
+
 // ReadOneWhere mocks base method.
 func (m *MockTransaction) ReadOneWhere(arg0 record.Record, arg1 *qb.ConditionExpression) errors.TracerError {
 	m.ctrl.T.Helper()
@@ -183,6 +316,48 @@ func (mr *MockTransactionMockRecorder) ReadOneWhere(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOneWhere", reflect.TypeOf((*MockTransaction)(nil).ReadOneWhere), arg0, arg1)
 }
 
+// ReadOneWhereContext mocks base method.
+func (m *MockTransaction) ReadOneWhereContext(arg0 context.Context, arg1 record.Record, arg2 *qb.ConditionExpression) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadOneWhereContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// ReadOneWhereContext indicates an expected call of ReadOneWhereContext.
+func (mr *MockTransactionMockRecorder) ReadOneWhereContext(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOneWhereContext", reflect.TypeOf((*MockTransaction)(nil).ReadOneWhereContext), arg0, arg1, arg2)
+}
+
+// Restore mocks base method.
+func (m *MockTransaction) Restore(arg0 record.Record) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockTransactionMockRecorder) Restore(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockTransaction)(nil).Restore), arg0)
+}
+
+// RestoreContext mocks base method.
+func (m *MockTransaction) RestoreContext(arg0 context.Context, arg1 record.Record) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreContext", arg0, arg1)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// RestoreContext indicates an expected call of RestoreContext.
+func (mr *MockTransactionMockRecorder) RestoreContext(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreContext", reflect.TypeOf((*MockTransaction)(nil).RestoreContext), arg0, arg1)
+}
+
 // Rollback mocks base method.
 func (m *MockTransaction) Rollback() errors.TracerError {
 	m.ctrl.T.Helper()
@@ -211,6 +386,60 @@ func (mr *MockTransactionMockRecorder) Select(arg0, arg1, arg2 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Select", reflect.TypeOf((*MockTransaction)(nil).Select), arg0, arg1, arg2)
 }
 
+// SelectContext mocks base method.
+func (m *MockTransaction) SelectContext(arg0 context.Context, arg1 any, arg2 *qb.SelectQuery, arg3 qb.LimitOffset) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectContext", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// SelectContext indicates an expected call of SelectContext.
+func (mr *MockTransactionMockRecorder) SelectContext(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectContext", reflect.TypeOf((*MockTransaction)(nil).SelectContext), arg0, arg1, arg2, arg3)
+}
+
+// SelectRows mocks base method.
+func (m *MockTransaction) SelectRows(arg0 *qb.SelectQuery, arg1 qb.LimitOffset) iter.Seq2[*sqlx.Rows, error] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectRows", arg0, arg1)
+	ret0, _ := ret[0].(iter.Seq2[*sqlx.Rows, error])
+	return ret0
+}
+
+// SelectRows indicates an expected call of SelectRows.
+func (mr *MockTransactionMockRecorder) SelectRows(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectRows", reflect.TypeOf((*MockTransaction)(nil).SelectRows), arg0, arg1)
+}
+
+// SelectRowsContext mocks base method.
+func (m *MockTransaction) SelectRowsContext(arg0 context.Context, arg1 *qb.SelectQuery, arg2 qb.LimitOffset) iter.Seq2[*sqlx.Rows, error] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectRowsContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(iter.Seq2[*sqlx.Rows, error])
+	return ret0
+}
+
+// SelectRowsContext indicates an expected call of SelectRowsContext.
+func (mr *MockTransactionMockRecorder) SelectRowsContext(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectRowsContext", reflect.TypeOf((*MockTransaction)(nil).SelectRowsContext), arg0, arg1, arg2)
+}
+
+// SetAudit mocks base method.
+func (m *MockTransaction) SetAudit(enabled bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetAudit", enabled)
+}
+
+// SetAudit indicates an expected call of SetAudit.
+func (mr *MockTransactionMockRecorder) SetAudit(enabled any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAudit", reflect.TypeOf((*MockTransaction)(nil).SetAudit), enabled)
+}
+
 // Update mocks base method.
 func (m *MockTransaction) Update(arg0 record.Record) errors.TracerError {
 	m.ctrl.T.Helper()
@@ -225,6 +454,20 @@ func (mr *MockTransactionMockRecorder) Update(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTransaction)(nil).Update), arg0)
 }
 
+// UpdateContext mocks base method.
+func (m *MockTransaction) UpdateContext(arg0 context.Context, arg1 record.Record) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateContext", arg0, arg1)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// UpdateContext indicates an expected call of UpdateContext.
+func (mr *MockTransactionMockRecorder) UpdateContext(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateContext", reflect.TypeOf((*MockTransaction)(nil).UpdateContext), arg0, arg1)
+}
+
 // UpdateIgnoreWhere mocks base method.
 func (m *MockTransaction) UpdateIgnoreWhere(arg0 record.Record, arg1 *qb.ConditionExpression, arg2 ...qb.FieldValue) (int64, errors.TracerError) {
 	m.ctrl.T.Helper()
@@ -245,6 +488,26 @@ func (mr *MockTransactionMockRecorder) UpdateIgnoreWhere(arg0, arg1 any, arg2 ..
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIgnoreWhere", reflect.TypeOf((*MockTransaction)(nil).UpdateIgnoreWhere), varargs...)
 }
 
+// UpdateIgnoreWhereContext mocks base method.
+func (m *MockTransaction) UpdateIgnoreWhereContext(arg0 context.Context, arg1 record.Record, arg2 *qb.ConditionExpression, arg3 ...qb.FieldValue) (int64, errors.TracerError) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateIgnoreWhereContext", varargs...)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(errors.TracerError)
+	return ret0, ret1
+}
+
+// UpdateIgnoreWhereContext indicates an expected call of UpdateIgnoreWhereContext.
+func (mr *MockTransactionMockRecorder) UpdateIgnoreWhereContext(arg0, arg1, arg2 any, arg3 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIgnoreWhereContext", reflect.TypeOf((*MockTransaction)(nil).UpdateIgnoreWhereContext), varargs...)
+}
+
 // UpdateWhere mocks base method.
 func (m *MockTransaction) UpdateWhere(arg0 record.Record, arg1 *qb.ConditionExpression, arg2 ...qb.FieldValue) (int64, errors.TracerError) {
 	m.ctrl.T.Helper()
@@ -265,6 +528,26 @@ func (mr *MockTransactionMockRecorder) UpdateWhere(arg0, arg1 any, arg2 ...any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWhere", reflect.TypeOf((*MockTransaction)(nil).UpdateWhere), varargs...)
 }
 
+// UpdateWhereContext mocks base method.
+func (m *MockTransaction) UpdateWhereContext(arg0 context.Context, arg1 record.Record, arg2 *qb.ConditionExpression, arg3 ...qb.FieldValue) (int64, errors.TracerError) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateWhereContext", varargs...)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(errors.TracerError)
+	return ret0, ret1
+}
+
+// UpdateWhereContext indicates an expected call of UpdateWhereContext.
+func (mr *MockTransactionMockRecorder) UpdateWhereContext(arg0, arg1, arg2 any, arg3 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWhereContext", reflect.TypeOf((*MockTransaction)(nil).UpdateWhereContext), varargs...)
+}
+
 // Upsert mocks base method.
 func (m *MockTransaction) Upsert(arg0 record.Record) errors.TracerError {
 	m.ctrl.T.Helper()
@@ -278,3 +561,17 @@ func (mr *MockTransactionMockRecorder) Upsert(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockTransaction)(nil).Upsert), arg0)
 }
+
+// UpsertContext mocks base method.
+func (m *MockTransaction) UpsertContext(arg0 context.Context, arg1 record.Record) errors.TracerError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertContext", arg0, arg1)
+	ret0, _ := ret[0].(errors.TracerError)
+	return ret0
+}
+
+// UpsertContext indicates an expected call of UpsertContext.
+func (mr *MockTransactionMockRecorder) UpsertContext(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertContext", reflect.TypeOf((*MockTransaction)(nil).UpsertContext), arg0, arg1)
+}
@@ -0,0 +1,226 @@
+package transaction
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	dberrors "github.com/beaconsoftwarellc/gadget/v2/database/errors"
+	"github.com/beaconsoftwarellc/gadget/v2/database/qb"
+	"github.com/beaconsoftwarellc/gadget/v2/database/record"
+	"github.com/beaconsoftwarellc/gadget/v2/log"
+	assert1 "github.com/stretchr/testify/assert"
+)
+
+type versionedRecord struct {
+	ID      string `db:"id"`
+	Name    string `db:"name"`
+	Version int    `db:"version"`
+}
+
+type versionedMeta struct {
+	ID      qb.TableField
+	Name    qb.TableField
+	Version qb.TableField
+}
+
+var versionedTable = &versionedMeta{
+	ID:      qb.TableField{Name: "id", Table: "versioned"},
+	Name:    qb.TableField{Name: "name", Table: "versioned"},
+	Version: qb.TableField{Name: "version", Table: "versioned"},
+}
+
+func (m *versionedMeta) GetName() string           { return "versioned" }
+func (m *versionedMeta) GetAlias() string          { return "versioned" }
+func (m *versionedMeta) PrimaryKey() qb.TableField { return m.ID }
+func (m *versionedMeta) SortBy() (qb.TableField, qb.OrderDirection) {
+	return m.ID, qb.Ascending
+}
+func (m *versionedMeta) AllColumns() qb.TableField {
+	return qb.TableField{Name: "*", Table: "versioned"}
+}
+func (m *versionedMeta) ReadColumns() []qb.TableField {
+	return []qb.TableField{m.ID, m.Name, m.Version}
+}
+func (m *versionedMeta) WriteColumns() []qb.TableField { return m.ReadColumns() }
+
+func (r *versionedRecord) Initialize()                        {}
+func (r *versionedRecord) PrimaryKey() record.PrimaryKeyValue { return record.NewPrimaryKey(r.ID) }
+func (r *versionedRecord) Key() string                        { return "id" }
+func (r *versionedRecord) Meta() qb.Table                     { return versionedTable }
+func (r *versionedRecord) VersionColumn() qb.TableField       { return versionedTable.Version }
+
+func TestTransaction_Update_Versioned(t *testing.T) {
+	assert := assert1.New(t)
+	connector := &streamConnector{
+		columns:  []string{"id", "name", "version"},
+		values:   [][]driver.Value{{"a", "renamed", int64(4)}},
+		affected: 1,
+	}
+	tx := newStreamTransaction(t, connector)
+	obj := &versionedRecord{ID: "a", Name: "renamed", Version: 3}
+
+	assert.NoError(tx.Update(obj))
+	assert.Equal(4, obj.Version)
+	if assert.Len(connector.queries, 2) {
+		assert.Equal("UPDATE `versioned` SET  `versioned`.`id` = ?, `versioned`.`name` = ?, "+
+			"`versioned`.`version` = `versioned`.`version` + 1 "+
+			"WHERE (`versioned`.`id` = ? AND `versioned`.`version` = ?) LIMIT 1", connector.queries[0])
+	}
+
+	// no rows are affected when the version has changed
+	connector.affected = 0
+	err := tx.Update(obj)
+	assert.IsType(&dberrors.StaleRecordError{}, err)
+	assert.Len(connector.queries, 3)
+}
+
+type softRecord struct {
+	ID        string     `db:"id"`
+	Name      string     `db:"name"`
+	DeletedOn *time.Time `db:"deleted_on"`
+}
+
+type softMeta struct {
+	ID        qb.TableField
+	Name      qb.TableField
+	DeletedOn qb.TableField
+}
+
+var softTable = &softMeta{
+	ID:        qb.TableField{Name: "id", Table: "soft"},
+	Name:      qb.TableField{Name: "name", Table: "soft"},
+	DeletedOn: qb.TableField{Name: record.DeletedOnColumn, Table: "soft"},
+}
+
+func (m *softMeta) GetName() string           { return "soft" }
+func (m *softMeta) GetAlias() string          { return "soft" }
+func (m *softMeta) PrimaryKey() qb.TableField { return m.ID }
+func (m *softMeta) SortBy() (qb.TableField, qb.OrderDirection) {
+	return m.ID, qb.Ascending
+}
+func (m *softMeta) AllColumns() qb.TableField {
+	return qb.TableField{Name: "*", Table: "soft"}
+}
+func (m *softMeta) ReadColumns() []qb.TableField  { return []qb.TableField{m.ID, m.Name, m.DeletedOn} }
+func (m *softMeta) WriteColumns() []qb.TableField { return []qb.TableField{m.ID, m.Name} }
+func (m *softMeta) DeletedColumn() qb.TableField  { return m.DeletedOn }
+
+func (r *softRecord) Initialize()                        {}
+func (r *softRecord) PrimaryKey() record.PrimaryKeyValue { return record.NewPrimaryKey(r.ID) }
+func (r *softRecord) Key() string                        { return "id" }
+func (r *softRecord) Meta() qb.Table                     { return softTable }
+
+func TestTransaction_SoftDelete(t *testing.T) {
+	assert := assert1.New(t)
+	connector := &streamConnector{
+		columns:  []string{"id", "name", "deleted_on"},
+		values:   [][]driver.Value{{"a", "first", nil}},
+		affected: 1,
+	}
+	tx := newStreamTransaction(t, connector)
+	obj := &softRecord{ID: "a"}
+	ctx := context.Background()
+
+	assert.NoError(tx.Delete(obj))
+	assert.NoError(tx.Read(obj, obj.PrimaryKey()))
+	assert.NoError(tx.ReadContext(record.WithDeleted(ctx), obj, obj.PrimaryKey()))
+	var records []*softRecord
+	assert.NoError(tx.ListContext(record.WithOnlyDeleted(ctx), obj, &records, nil))
+	assert.NoError(tx.ListWhere(obj, &records, softTable.Name.Equal("first"), nil))
+	assert.NoError(tx.Restore(obj))
+	assert.Equal([]string{
+		"UPDATE `soft` SET  `soft`.`deleted_on` = ? WHERE (`soft`.`id` = ? AND `soft`.`deleted_on` IS NULL)",
+		"SELECT `soft`.* FROM `soft` AS `soft` WHERE (`soft`.`id` = ? AND `soft`.`deleted_on` IS NULL) LIMIT 1",
+		"SELECT `soft`.* FROM `soft` AS `soft` WHERE `soft`.`id` = ? LIMIT 1",
+		"SELECT `soft`.* FROM `soft` AS `soft` WHERE `soft`.`deleted_on` IS NOT NULL ORDER BY `soft`.`id` ASC",
+		"SELECT `soft`.* FROM `soft` AS `soft` WHERE (`soft`.`name` = ? AND `soft`.`deleted_on` IS NULL) " +
+			"ORDER BY `soft`.`id` ASC",
+		"UPDATE `soft` SET  `soft`.`deleted_on` = NULL WHERE `soft`.`id` = ?",
+		"SELECT `soft`.* FROM `soft` AS `soft` WHERE `soft`.`id` = ? LIMIT 1",
+	}, connector.queries)
+
+	// deleted records are read back after they are written
+	deleted := time.Now()
+	connector.values = [][]driver.Value{{"a", "renamed", deleted}}
+	connector.queries = nil
+	obj = &softRecord{ID: "a", Name: "renamed"}
+	assert.NoError(tx.Update(obj))
+	assert.NotNil(obj.DeletedOn)
+	assert.NoError(tx.Upsert(obj))
+	assert.NotNil(obj.DeletedOn)
+	if assert.Len(connector.queries, 4) {
+		assert.Equal("SELECT `soft`.* FROM `soft` AS `soft` WHERE `soft`.`id` = ? LIMIT 1",
+			connector.queries[1])
+		assert.Equal(connector.queries[1], connector.queries[3])
+	}
+
+	// records that are not soft deletable can not be restored
+	assert.IsType(&dberrors.ValidationError{}, tx.Restore(&versionedRecord{ID: "a"}))
+}
+
+func TestNew_Dialect(t *testing.T) {
+	assert := assert1.New(t)
+	tx, err := New(begin{}, log.Global(), time.Second, nil)
+	assert.NoError(err)
+	assert.Equal(qb.MySQL, tx.(*transaction).dialect)
+
+	tx, err = NewWithDialect(begin{}, qb.PostgreSQL, log.Global(), time.Second, nil)
+	assert.NoError(err)
+	assert.Equal(qb.PostgreSQL, tx.(*transaction).dialect)
+}
+
+// beginOnly does not implement BeginContexter
+type beginOnly struct {
+	implementation Implementation
+}
+
+func (b beginOnly) Begin() (Implementation, error) {
+	return b.implementation, nil
+}
+
+// beginContexter records the context transactions were started with
+type beginContexter struct {
+	beginOnly
+	ctx context.Context
+}
+
+func (b *beginContexter) BeginContext(ctx context.Context) (Implementation, error) {
+	b.ctx = ctx
+	return b.implementation, nil
+}
+
+func TestNewContext_Begin(t *testing.T) {
+	assert := assert1.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// the context is ignored when it cannot be used to begin the transaction
+	tx, err := NewContext(ctx, beginOnly{}, qb.MySQL, log.Global(), time.Second, nil)
+	assert.NoError(err)
+	assert.NotNil(tx)
+
+	contexter := &beginContexter{}
+	_, err = NewContext(ctx, contexter, qb.MySQL, log.Global(), time.Second, nil)
+	assert.NoError(err)
+	assert.Equal(ctx, contexter.ctx)
+}
+
+func TestTransaction_Select_Dialect(t *testing.T) {
+	assert := assert1.New(t)
+	connector := &streamConnector{values: [][]driver.Value{{"a", "first"}}}
+	tx := newStreamTransaction(t, connector)
+	tx.(*transaction).dialect = qb.PostgreSQL
+	query := qb.Select(streamTable.AllColumns()).From(streamTable).Where(streamTable.ID.Equal("a"))
+
+	var actual []streamRecord
+	assert.NoError(tx.SelectContext(context.Background(), &actual, query, nil))
+	for _, err := range tx.SelectRowsContext(context.Background(), query, nil) {
+		assert.NoError(err)
+	}
+	expected := `SELECT "stream".* FROM "stream" AS "stream" WHERE "stream"."id" = $1`
+	assert.Equal([]string{expected, expected}, connector.queries)
+	// the query is rendered for the transaction without changing its dialect
+	assert.Equal(qb.MySQL, query.GetDialect())
+}